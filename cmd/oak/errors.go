@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Exit codes distinguishing the stage of a run that failed
+const (
+	// exitFailure is returned for errors of no other class, such as failed
+	// generation, strict checks, and interrupted runs
+	exitFailure = 1
+
+	// exitConfig is returned for invalid arguments and configuration
+	exitConfig = 2
+
+	// exitParse is returned when source files cannot be read or parsed
+	exitParse = 3
+
+	// exitWrite is returned when generated files, reports, or the cache
+	// cannot be written
+	exitWrite = 4
+)
+
+// classifiedError attributes an error to the stage of the run that failed
+type classifiedError struct {
+	kind string
+	code int
+	err  error
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+
+func (e *classifiedError) Unwrap() error { return e.err }
+
+// configError classifies err as an argument or configuration error
+func configError(err error) error {
+	return &classifiedError{kind: "config", code: exitConfig, err: err}
+}
+
+// parseError classifies err as a failure to read or parse sources
+func parseError(err error) error {
+	return &classifiedError{kind: "parse", code: exitParse, err: err}
+}
+
+// writeError classifies err as a failure to write output
+func writeError(err error) error {
+	return &classifiedError{kind: "write", code: exitWrite, err: err}
+}
+
+// jsonErrorsError marks an error to be reported as a JSON object, as
+// requested with --json-errors
+type jsonErrorsError struct {
+	err error
+}
+
+func (e *jsonErrorsError) Error() string { return e.err.Error() }
+
+func (e *jsonErrorsError) Unwrap() error { return e.err }
+
+// errorKind returns the class of err and its exit code; errors of no class
+// are general failures
+func errorKind(err error) (string, int) {
+	var classified *classifiedError
+	if errors.As(err, &classified) {
+		return classified.kind, classified.code
+	}
+	return "error", exitFailure
+}
+
+// exitCode returns the process exit code for an error returned by run
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	_, code := errorKind(err)
+	return code
+}
+
+// printError reports an error returned by run, as a JSON object with its
+// message, kind, and exit code when --json-errors is set
+func printError(w io.Writer, err error) {
+	var jsonErr *jsonErrorsError
+	if !errors.As(err, &jsonErr) {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+
+	kind, code := errorKind(err)
+	data, marshalErr := json.Marshal(struct {
+		Error string `json:"error"`
+		Kind  string `json:"kind"`
+		Code  int    `json:"code"`
+	}{err.Error(), kind, code})
+	if marshalErr != nil {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "%s\n", data)
+}
@@ -1,31 +1,67 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"go/token"
+	"io"
 	"os"
-	"runtime/debug"
-
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/stuckinforloop/oak/internal/cache"
 	"github.com/stuckinforloop/oak/internal/cli"
 	"github.com/stuckinforloop/oak/internal/config"
 	"github.com/stuckinforloop/oak/internal/generator"
 	"github.com/stuckinforloop/oak/internal/parser"
+	"github.com/stuckinforloop/oak/internal/report"
+	"github.com/stuckinforloop/oak/internal/types"
+	"github.com/stuckinforloop/oak/internal/version"
 	"github.com/stuckinforloop/oak/internal/writer"
 )
 
-var version string
+// maxWorkers bounds the number of packages parsed and generated concurrently
+var maxWorkers = runtime.GOMAXPROCS(0)
+
+// cachePath returns the location of the parse cache
+var cachePath = cache.DefaultPath
 
 func main() {
-	if err := run(os.Args[1:]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// Interrupting the run cancels it between packages
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, os.Args[1:])
+	stop()
+	if err != nil {
+		printError(os.Stderr, err)
+		os.Exit(exitCode(err))
 	}
 }
 
-func run(args []string) error {
+// run runs oak with the given arguments. Its errors are classified by the
+// stage that failed, which determines the exit code.
+func run(ctx context.Context, args []string) (err error) {
 	// Parse command-line arguments
 	opts, err := cli.ParseArgs(args)
 	if err != nil {
-		return fmt.Errorf("failed to parse arguments: %w", err)
+		return configError(fmt.Errorf("failed to parse arguments: %w", err))
+	}
+
+	// Errors are reported as JSON objects when requested
+	if opts.JSONErrors {
+		defer func() {
+			if err != nil {
+				err = &jsonErrorsError{err: err}
+			}
+		}()
 	}
 
 	// Handle help and version flags
@@ -35,19 +71,35 @@ func run(args []string) error {
 	}
 
 	if opts.Version {
-		fmt.Printf("oak %s\n", getBuildVersion())
+		fmt.Printf("oak %s\n", version.Get())
 		return nil
 	}
 
 	// Validate options
 	if err := opts.Validate(); err != nil {
-		return fmt.Errorf("invalid arguments: %w", err)
+		return configError(fmt.Errorf("invalid arguments: %w", err))
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// Scaffold a configuration instead of generating
+	if opts.Init {
+		configPath, err := config.WriteExample(".")
+		if err != nil {
+			return writeError(err)
+		}
+		fmt.Printf("Created %s\n", configPath)
+		return nil
 	}
 
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return configError(fmt.Errorf("failed to load configuration: %w", err))
 	}
 
 	// Determine what to process
@@ -56,77 +108,592 @@ func run(args []string) error {
 	// Get the paths to process
 	paths, err := getProcessingPaths(target, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to determine processing paths: %w", err)
+		return configError(fmt.Errorf("failed to determine processing paths: %w", err))
 	}
 
 	if len(paths) == 0 {
-		return fmt.Errorf("no paths to process")
+		return configError(fmt.Errorf("no paths to process"))
 	}
 
-	// Process each path
-	var allStructs []parser.StructInfo
+	// Paths whose sources, config and options are unchanged since the last
+	// run are skipped
+	buildCache, err := loadCache(cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	// Parse each path in parallel; parsing packages is independent. The
+	// go/packages loader instead loads all changed paths together below.
+	usePackages := cfg.Loader == config.LoaderPackages
+	analyzeAll := opts.Report != "" || opts.StrictRedact || opts.StrictFields || opts.StrictTypes || opts.Diff || opts.List
 	oakParser := parser.New()
+	oakParser.IncludeTests = cfg.IncludeTests
+	parseResults := make([]*parser.ParseResult, len(paths))
+	snapshots := make([]map[string]cache.FileState, len(paths))
+	unchanged := make([]bool, len(paths))
+
+	parsed, err := runParallel(ctx, len(paths), maxWorkers, func(i int) error {
+		snapshot, err := cache.Snapshot(paths[i])
+		if err != nil {
+			return parseError(fmt.Errorf("failed to read %s: %w", paths[i], err))
+		}
+		snapshots[i] = snapshot
+
+		// Reports, redact key, field count, and type checks cover every struct, so
+		// nothing is skipped when they are requested, nor when forced. --type
+		// must find its struct, so it always parses too.
+		if !analyzeAll && !opts.Force && opts.TypeName == "" && buildCache.Unchanged(paths[i], snapshot) {
+			parseResults[i] = &parser.ParseResult{}
+			unchanged[i] = true
+			return nil
+		}
+
+		if usePackages {
+			return nil
+		}
 
-	for _, path := range paths {
-		var result *parser.ParseResult
 		var parseErr error
 
 		if target.Mode == cli.ModeSourceFile {
-			result, parseErr = oakParser.ParseFile(path)
+			parseResults[i], parseErr = oakParser.ParseFile(paths[i])
 		} else {
-			result, parseErr = oakParser.ParsePackage(path)
+			parseResults[i], parseErr = oakParser.ParsePackage(paths[i])
 		}
 
 		if parseErr != nil {
-			return fmt.Errorf("failed to parse %s: %w", path, parseErr)
+			return parseError(fmt.Errorf("failed to parse %s: %w", paths[i], parseErr))
+		}
+		if len(parseResults[i].Errors) > 0 && !opts.KeepGoing {
+			return parseError(fmt.Errorf("failed to parse %s: %w", paths[i], parseResults[i].Errors[0]))
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return interrupted(ctx, "parsing", parsed, len(paths), "path(s)")
+	}
 
-		allStructs = append(allStructs, result.Structs...)
+	if usePackages {
+		var changed []string
+		for i, path := range paths {
+			if !unchanged[i] {
+				changed = append(changed, path)
+			}
+		}
+
+		if len(changed) > 0 {
+			loaded, err := oakParser.LoadPackages(changed...)
+			if err != nil {
+				return parseError(err)
+			}
+			for _, loadErr := range loaded.Errors {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", loadErr)
+			}
+			parseResults = append(parseResults, loaded)
+		}
+	}
+
+	// With --keep-going, paths with a file that failed to parse generate
+	// nothing, so their generated files keep the structs of the broken files
+	var parseErrs []error
+	failed := make([]bool, len(paths))
+	for i, result := range parseResults[:len(paths)] {
+		if result != nil && len(result.Errors) > 0 {
+			parseErrs = append(parseErrs, result.Errors...)
+			result.Structs = nil
+			failed[i] = true
+		}
+	}
+
+	var allStructs []parser.StructInfo
+	for _, result := range parseResults {
+		if result != nil {
+			allStructs = append(allStructs, result.Structs...)
+		}
+	}
+
+	var skipped int
+	for _, isUnchanged := range unchanged {
+		if isUnchanged {
+			skipped++
+		}
+	}
+
+	// Keep only structs matching the configured include patterns
+	allStructs = filterStructs(allStructs, cfg)
+	if cfg.ExportedOnly {
+		allStructs = exportedStructs(allStructs, opts.Verbose)
+	}
+
+	// Restrict generation to the packages holding a single struct when
+	// requested. Their other structs are generated too, since they share the
+	// generated file, which would otherwise lose their methods.
+	if opts.TypeName != "" {
+		named, err := parser.FilterByName(allStructs, opts.TypeName)
+		if err != nil {
+			return configError(err)
+		}
+		if opts.List {
+			allStructs = named
+		} else {
+			allStructs = samePackage(allStructs, named)
+		}
+	}
+
+	// Listing reports the structs found without generating or caching
+	if opts.List {
+		return listStructs(os.Stdout, cfg, allStructs)
 	}
 
 	if len(allStructs) == 0 {
-		fmt.Println("No structs found with //go:generate oak directive")
-		return nil
+		if skipped > 0 {
+			fmt.Printf("Skipped %d unchanged path(s)\n", skipped)
+		} else {
+			fmt.Println("No structs found with //go:generate oak or //oak:generate directive")
+		}
+		if err := writeReport(opts, nil); err != nil {
+			return writeError(err)
+		}
+		if err := checkRedactKeys(cfg, opts, nil); err != nil {
+			return err
+		}
+		if opts.Fix {
+			printRewritten(nil)
+		}
+		if opts.Diff {
+			return nil
+		}
+		if err := recordPaths(buildCache, paths, snapshots, unchanged, failed, nil); err != nil {
+			return writeError(err)
+		}
+		return reportParseErrors(parseErrs)
+	}
+
+	warnEmbeddedInterfaces(cfg, opts, allStructs)
+	if err := checkUnknownTypes(cfg, opts, allStructs); err != nil {
+		return err
 	}
 
-	// Group structs by package
+	// Group structs by package, visiting packages in a stable order
 	packageStructs := groupStructsByPackage(allStructs)
+	packageDirs := make([]string, 0, len(packageStructs))
+	for dir := range packageStructs {
+		packageDirs = append(packageDirs, dir)
+	}
+	sort.Strings(packageDirs)
 
-	// Generate code for each package
+	// Generate code for each package in parallel
 	gen := generator.New(cfg)
-	fileWriter := writer.New()
+	packageResults := make([][]*generator.GenerationResult, len(packageDirs))
 
-	var generatedFiles []string
+	generatedCount, err := runParallel(ctx, len(packageDirs), maxWorkers, func(i int) error {
+		structs := packageStructs[packageDirs[i]]
+		packageName := structs[0].PackageName
 
-	for packageName, structs := range packageStructs {
-		result, err := gen.GenerateForStructs(structs)
+		// Packages without loggable fields get no generated file
+		var results []*generator.GenerationResult
+		var err error
+		if cfg.InlineOutput {
+			results, err = gen.GenerateInline(structs)
+		} else {
+			var result *generator.GenerationResult
+			result, err = gen.GenerateForStructs(structs)
+			results = []*generator.GenerationResult{result}
+		}
+		if errors.Is(err, generator.ErrNoLoggableStructs) {
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("failed to generate code for package %s: %w", packageName, err)
 		}
+		packageResults[i] = append(packageResults[i], results...)
+
+		// Benchmarks are not generated for structs declared in test files
+		if opts.EmitBenchmarks && !parser.IsTestFile(structs[0].FilePath) {
+			benchResult, err := gen.GenerateBenchmarks(structs)
+			if err != nil {
+				return fmt.Errorf("failed to generate benchmarks for package %s: %w", packageName, err)
+			}
+			packageResults[i] = append(packageResults[i], benchResult)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		return interrupted(ctx, "generating", generatedCount, len(packageDirs), "package(s)")
+	}
+
+	// Oversized structs fail the run before anything is written in strict mode
+	if err := checkMaxFields(cfg, opts, packageResults); err != nil {
+		return err
+	}
+
+	// Write results in package order so output is deterministic
+	fileWriter := writer.New()
+	fileWriter.OutputDir = cfg.OutputDir
+	fileWriter.Root = cfg.Dir
+	fileWriter.Force = opts.Force
+
+	generatedFiles := make(map[string][]string)
+	var generated []*generator.GenerationResult
+	var structCount, packageCount, staleCount int
+
+	for i, results := range packageResults {
+		// Files already written are complete, but the run is not recorded
+		// in the cache
+		if ctx.Err() != nil {
+			return interrupted(ctx, "writing", i, len(packageResults), "package(s)")
+		}
+
+		generated = append(generated, results...)
+		if len(results) > 0 {
+			packageCount++
+		}
+		for _, result := range results {
+			structCount += len(result.Structs)
+
+			// Diffs are informational; nothing is written
+			if opts.Diff {
+				patch, err := fileWriter.DiffResult(result)
+				if err != nil {
+					return fmt.Errorf("failed to diff generated file: %w", err)
+				}
+				fmt.Print(patch)
+				if patch != "" {
+					staleCount++
+				}
+				continue
+			}
+
+			if err := fileWriter.WriteResult(result); err != nil {
+				return writeError(fmt.Errorf("failed to write generated file: %w", err))
+			}
+
+			// Source files written inline stay tracked as sources
+			if result.Inline {
+				continue
+			}
+
+			// Outputs are recorded under the source directory, wherever the
+			// file was written
+			outputPath, err := fileWriter.OutputPath(result.FilePath)
+			if err != nil {
+				return writeError(err)
+			}
+			dir := absPath(filepath.Dir(result.FilePath))
+			generatedFiles[dir] = append(generatedFiles[dir], outputPath)
+		}
+	}
+
+	if !opts.Diff {
+		fmt.Printf("Successfully processed %d struct(s) in %d package(s)\n",
+			structCount, packageCount)
+		if skipped > 0 {
+			fmt.Printf("Skipped %d unchanged path(s)\n", skipped)
+		}
+	}
 
-		if err := fileWriter.WriteResult(result); err != nil {
-			return fmt.Errorf("failed to write generated file: %w", err)
+	if cfg.PostHook != "" {
+		for _, file := range fileWriter.Rewritten() {
+			if err := runPostHook(cfg.PostHook, file); err != nil {
+				return err
+			}
 		}
+	}
+
+	if opts.Fix {
+		printRewritten(fileWriter.Rewritten())
+	}
+
+	if err := writeReport(opts, generated); err != nil {
+		return writeError(err)
+	}
+
+	// Files left unwritten must not be recorded as up to date
+	if !opts.Diff {
+		if err := recordPaths(buildCache, paths, snapshots, unchanged, failed, generatedFiles); err != nil {
+			return writeError(err)
+		}
+	}
+
+	if err := reportParseErrors(parseErrs); err != nil {
+		return err
+	}
 
-		generatedFiles = append(generatedFiles, result.FilePath)
+	// Checks fail once every diff has been printed
+	if opts.Check && staleCount > 0 {
+		return fmt.Errorf("%d generated file(s) are out of date; run oak generate", staleCount)
 	}
 
-	fmt.Printf("Successfully processed %d struct(s) in %d package(s)\n",
-		len(allStructs), len(packageStructs))
+	return checkRedactKeys(cfg, opts, generated)
+}
+
+// warnEmbeddedInterfaces reports, in verbose mode, the embedded interface
+// fields skipped because logEmbeddedInterfaces is not set
+func warnEmbeddedInterfaces(cfg *config.Config, opts *cli.Options, structs []parser.StructInfo) {
+	if !opts.Verbose {
+		return
+	}
 
+	analyzer := types.NewTypeAnalyzer(cfg)
+	for _, s := range structs {
+		for _, field := range s.Fields {
+			if analyzer.IsSkippedEmbeddedInterface(field) && !field.LogOptions.Skip {
+				fmt.Fprintf(os.Stderr, "Warning: skipping embedded interface %s in %s; set logEmbeddedInterfaces to log it\n", field.Type, s.Name)
+			}
+		}
+	}
+}
+
+// checkUnknownTypes reports, in verbose or strict mode, the logged fields
+// whose type the parser could not resolve, which are logged by reflection,
+// and fails the run if there are any when --strict-types is set
+func checkUnknownTypes(cfg *config.Config, opts *cli.Options, structs []parser.StructInfo) error {
+	if !opts.Verbose && !opts.StrictTypes {
+		return nil
+	}
+
+	packageStructs := groupStructsByPackage(structs)
+	packageDirs := make([]string, 0, len(packageStructs))
+	for dir := range packageStructs {
+		packageDirs = append(packageDirs, dir)
+	}
+	sort.Strings(packageDirs)
+
+	var count int
+	for _, dir := range packageDirs {
+		analyzer := types.NewTypeAnalyzer(cfg.ForPackage(dir)).WithKnownStructs(packageStructs[dir])
+		for _, s := range packageStructs[dir] {
+			for _, name := range types.UnknownTypeFields(analyzer.AnalyzeStruct(s)) {
+				fmt.Fprintf(os.Stderr, "Warning: cannot resolve the type of field %s in %s (%s); it is logged by reflection\n",
+					name, s.Name, relativePath(s.FilePath))
+				count++
+			}
+		}
+	}
+
+	if opts.StrictTypes && count > 0 {
+		return fmt.Errorf("%d field(s) have types oak cannot resolve", count)
+	}
+	return nil
+}
+
+// runPostHook runs the post-generation hook command through sh for a written
+// file. Its output is passed through, while its standard error is captured and
+// reported in the returned error when the command fails.
+func runPostHook(hook, file string) error {
+	quoted := "'" + strings.ReplaceAll(file, "'", `'\''`) + "'"
+	command := strings.ReplaceAll(hook, "{file}", quoted)
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("post hook failed for %s: %w: %s", file, err, message)
+		}
+		return fmt.Errorf("post hook failed for %s: %w", file, err)
+	}
+	return nil
+}
+
+// listStructs prints a table of the structs carrying the directive, by
+// package, file, and name, with how many of their fields would be logged,
+// redacted (including masked and hashed fields), and skipped
+func listStructs(w io.Writer, cfg *config.Config, structs []parser.StructInfo) error {
+	if len(structs) == 0 {
+		fmt.Fprintln(w, "No structs found with //go:generate oak or //oak:generate directive")
+		return nil
+	}
+
+	packageStructs := groupStructsByPackage(structs)
+	packageDirs := make([]string, 0, len(packageStructs))
+	for dir := range packageStructs {
+		packageDirs = append(packageDirs, dir)
+	}
+	sort.Strings(packageDirs)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tSTRUCT\tFILE\tFIELDS\tLOGGED\tREDACTED\tSKIPPED")
+	for _, dir := range packageDirs {
+		structs := packageStructs[dir]
+		sort.SliceStable(structs, func(i, j int) bool {
+			if structs[i].FilePath != structs[j].FilePath {
+				return structs[i].FilePath < structs[j].FilePath
+			}
+			return structs[i].Name < structs[j].Name
+		})
+
+		// Fields are analyzed as they would be generated for the package
+		analyzer := types.NewTypeAnalyzer(cfg.ForPackage(dir)).WithKnownStructs(structs)
+		for _, s := range structs {
+			var logged, redacted, skipped int
+			analyses := analyzer.AnalyzeStruct(s)
+			for _, analysis := range analyses {
+				switch analysis.Action {
+				case types.ActionLog:
+					logged++
+				case types.ActionSkip:
+					skipped++
+				default:
+					redacted++
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n", s.PackageName, s.Name,
+				relativePath(s.FilePath), len(analyses), logged, redacted, skipped)
+		}
+	}
+	return tw.Flush()
+}
+
+// relativePath returns path relative to the working directory when possible
+func relativePath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, absPath(path)); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// printRewritten reports the generated files whose content changed
+func printRewritten(files []string) {
+	if len(files) == 0 {
+		fmt.Println("All generated files are up to date")
+		return
+	}
+
+	fmt.Printf("Rewrote %d file(s):\n", len(files))
+	for _, file := range files {
+		fmt.Printf("  %s\n", file)
+	}
+}
+
+// checkRedactKeys warns about configured redact keys that matched no field
+// and fails the run if any did, when --strict-redact is set
+func checkRedactKeys(cfg *config.Config, opts *cli.Options, results []*generator.GenerationResult) error {
+	if !opts.StrictRedact {
+		return nil
+	}
+
+	unmatched := unmatchedRedactKeys(cfg, results)
+	for _, key := range unmatched {
+		fmt.Fprintf(os.Stderr, "Warning: redact key %q matched no fields\n", key)
+	}
+	if len(unmatched) > 0 {
+		return fmt.Errorf("%d redact key(s) matched no fields: %s", len(unmatched), strings.Join(unmatched, ", "))
+	}
+
+	return nil
+}
+
+// unmatchedRedactKeys returns the configured redact keys, including those of
+// overrides, that matched no field of the generated structs
+func unmatchedRedactKeys(cfg *config.Config, results []*generator.GenerationResult) []string {
+	matched := make(map[string]bool)
+	for _, result := range results {
+		for _, s := range result.Structs {
+			for _, field := range s.Fields {
+				if field.RedactKey != "" {
+					matched[field.RedactKey] = true
+				}
+			}
+		}
+	}
+
+	var unmatched []string
+	for _, key := range cfg.AllRedactKeys() {
+		if !matched[key] {
+			unmatched = append(unmatched, key)
+		}
+	}
+	return unmatched
+}
+
+// checkMaxFields warns about structs logging more fields than maxFields, and
+// fails the run if any do, when --strict-fields is set
+func checkMaxFields(cfg *config.Config, opts *cli.Options, packageResults [][]*generator.GenerationResult) error {
+	var count int
+	for _, results := range packageResults {
+		for _, result := range results {
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+				count++
+			}
+		}
+	}
+
+	if opts.StrictFields && count > 0 {
+		return fmt.Errorf("%d struct(s) log more fields than maxFields (%d)", count, cfg.MaxFields)
+	}
 	return nil
 }
 
-func getBuildVersion() string {
-	if version != "" {
-		return version
+// writeReport writes the JSON summary of the generated structs if requested
+func writeReport(opts *cli.Options, results []*generator.GenerationResult) error {
+	if opts.Report == "" {
+		return nil
+	}
+	return report.Build(results).Write(opts.Report)
+}
+
+// loadCache loads the parse cache, invalidated whenever the configuration or
+// the options affecting generated output change
+func loadCache(cfg *config.Config, opts *cli.Options) (*cache.Cache, error) {
+	path, err := cachePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache: %w", err)
 	}
 
-	if buildInfo, exists := debug.ReadBuildInfo(); exists {
-		return buildInfo.Main.Version
+	fingerprint, err := cache.Fingerprint(version.Get(), cfg, opts.TypeName, opts.EmitBenchmarks)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.Load(path, fingerprint), nil
+}
+
+// reportParseErrors fails a --keep-going run with the errors of the files
+// that failed to parse, once everything else has been generated
+func reportParseErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return parseError(fmt.Errorf("%d file(s) failed to parse:\n%w", len(errs), errors.Join(errs...)))
+}
+
+// recordPaths stores the processed paths in the cache along with the files
+// generated for their package directories. Unchanged paths keep their entry,
+// and paths that failed to parse get none, so they are parsed again.
+func recordPaths(c *cache.Cache, paths []string, snapshots []map[string]cache.FileState, unchanged, failed []bool, generatedFiles map[string][]string) error {
+	for i, path := range paths {
+		if unchanged[i] || failed[i] {
+			continue
+		}
+
+		dir := path
+		if filepath.Ext(path) == ".go" {
+			dir = filepath.Dir(path)
+		}
+		c.Record(path, snapshots[i], generatedFiles[absPath(dir)])
 	}
 
-	return "unknown"
+	return c.Save()
+}
+
+// absPath returns the absolute form of path, or the cleaned path if it cannot
+// be made absolute, so paths from different loaders compare equal
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
 }
 
 func getProcessingPaths(target *cli.ProcessingTarget, cfg *config.Config) ([]string, error) {
@@ -148,29 +715,154 @@ func getProcessingPaths(target *cli.ProcessingTarget, cfg *config.Config) ([]str
 	}
 }
 
+func filterStructs(structs []parser.StructInfo, cfg *config.Config) []parser.StructInfo {
+	var filtered []parser.StructInfo
+
+	for _, s := range structs {
+		if cfg.ShouldIncludeStruct(s.Name) {
+			filtered = append(filtered, s)
+		}
+	}
+
+	return filtered
+}
+
+// exportedStructs keeps only exported structs, noting in verbose mode each
+// unexported struct skipped
+func exportedStructs(structs []parser.StructInfo, verbose bool) []parser.StructInfo {
+	var exported []parser.StructInfo
+
+	for _, s := range structs {
+		if token.IsExported(s.Name) {
+			exported = append(exported, s)
+		} else if verbose {
+			fmt.Fprintf(os.Stderr, "Note: skipping unexported struct %s in %s; exportedOnly is set\n", s.Name, relativePath(s.FilePath))
+		}
+	}
+
+	return exported
+}
+
+// groupStructsByPackage groups structs by the directory of their source file,
+// since each package directory receives its own generated file. Structs
+// declared in test files are grouped by package apart from the others, as
+// they are generated into test files.
 func groupStructsByPackage(structs []parser.StructInfo) map[string][]parser.StructInfo {
 	groups := make(map[string][]parser.StructInfo)
 
 	for _, s := range structs {
-		groups[s.PackageName] = append(groups[s.PackageName], s)
+		key := packageKey(s)
+		groups[key] = append(groups[key], s)
 	}
 
 	return groups
 }
 
+// packageKey returns the key grouping a struct with the others sharing its
+// generated file: its directory, with test packages kept apart
+func packageKey(s parser.StructInfo) string {
+	key := filepath.Dir(s.FilePath)
+	if parser.IsTestFile(s.FilePath) {
+		key = filepath.Join(key, s.PackageName+" (test)")
+	}
+	return key
+}
+
+// samePackage returns the structs grouped into the same package as any of
+// named, in their original order
+func samePackage(structs, named []parser.StructInfo) []parser.StructInfo {
+	keys := make(map[string]bool)
+	for _, s := range named {
+		keys[packageKey(s)] = true
+	}
+
+	var result []parser.StructInfo
+	for _, s := range structs {
+		if keys[packageKey(s)] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// interrupted returns the error ending a run cancelled or timed out during a
+// stage, summarizing how much of it was done
+func interrupted(ctx context.Context, stage string, done, total int, unit string) error {
+	return fmt.Errorf("interrupted while %s after %d of %d %s: %w", stage, done, total, unit, ctx.Err())
+}
+
+// runParallel calls fn for each index in [0, n) using at most workers
+// goroutines, making no further calls once ctx is done. It returns the number
+// of calls made, which all completed, and their errors joined in index order.
+func runParallel(ctx context.Context, n, workers int, fn func(i int) error) (int, error) {
+	errs := make([]error, n)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+
+	started := 0
+send:
+	for ; started < n && ctx.Err() == nil; started++ {
+		select {
+		case jobs <- started:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return started, errors.Join(errs...)
+}
+
 func printHelp() {
 	fmt.Printf(`oak %s - Go structured logging code generator
 
 USAGE:
-    oak [OPTIONS] [PATH]
+    oak [COMMAND] [OPTIONS] [PATH]
 
 DESCRIPTION:
     Oak generates LogValue() methods for Go structs to integrate with log/slog.
     It automatically handles type-specific logging, field redaction, and exclusion.
 
+COMMANDS:
+    generate            Generate code (the default when no command is given)
+    check               Print a diff of the changes and fail if any generated
+                        file is out of date, without writing files
+    init                Write an example oak.yaml into the current directory
+    list                List the structs that would be generated, without generating
+
 OPTIONS:
     --source <FILE>     Process a specific Go source file
     --package <DIR>     Process a specific package directory
+    --type <NAME>       Only generate for the struct with this name
+    --emit-benchmarks   Also generate LogValue benchmarks (oak_log_bench_test.go)
+    --report <FILE>     Write a JSON summary of the generated structs and fields
+    --diff              Print a diff of the changes instead of writing files
+    --strict-redact     Fail when a configured redact key matches no field
+    --strict-fields     Fail when a struct logs more fields than maxFields
+    --strict-types      Fail when a logged field's type cannot be resolved
+    --keep-going        Generate for the files that parse, reporting the files
+                        that fail to parse at the end
+    --fix               Report which generated files were rewritten
+    --force             Regenerate and write every file, ignoring the cache
+                        (e.g. after upgrading oak or Go)
+    --verbose           Report fields skipped by default, such as embedded interfaces
+    --list              List the structs that would be generated, without generating
+    --init              Write an example oak.yaml into the current directory
+    --json-errors       Print errors to stderr as JSON objects
+    --timeout <DURATION>
+                        Stop the run after this long (e.g. 30s); Ctrl-C also
+                        stops it between packages
     --help, -h          Show this help message
     --version, -v       Show version information
 
@@ -184,11 +876,22 @@ EXAMPLES:
     oak ./internal/booking        Process specific package
     oak --package ./internal/booking
     oak --source ./booking.go     Process specific file
+    oak --package ./booking --type Reservation
+                                  Process a single struct
+    oak check ./...               Fail if generated files are out of date (e.g. in CI)
+    oak init                      Write an example oak.yaml
 
 CONFIGURATION:
     Oak uses an oak.yaml file in the project root for configuration.
-    See the example oak.yaml file for available options.
+    Run oak init to write a commented example oak.yaml.
+
+EXIT CODES:
+    0    Success
+    1    Other failures, such as failed strict checks or interrupted runs
+    2    Invalid arguments or configuration
+    3    Source files could not be read or parsed
+    4    Generated files, reports, or the cache could not be written
 
 For more information, visit: https://github.com/stuckinforloop/oak
-`, version)
+`, version.Get())
 }
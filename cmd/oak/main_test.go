@@ -0,0 +1,1153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stuckinforloop/oak/internal/config"
+	"github.com/stuckinforloop/oak/internal/report"
+)
+
+// useTempCache points the parse cache at a file in a temporary directory
+func useTempCache(t testing.TB) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	previous := cachePath
+	cachePath = func() (string, error) { return path, nil }
+	t.Cleanup(func() { cachePath = previous })
+
+	return path
+}
+
+// writeFixturePackages creates count packages under dir, each holding a struct
+// with the oak directive, along with an empty oak.yaml
+func writeFixturePackages(t testing.TB, dir string, count int) []string {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("redactKeys:\n  - password\n"), 0644); err != nil {
+		t.Fatalf("Failed to create oak.yaml: %v", err)
+	}
+
+	var packageDirs []string
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("pkg%02d", i)
+		packageDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(packageDir, 0755); err != nil {
+			t.Fatalf("Failed to create package directory: %v", err)
+		}
+
+		content := fmt.Sprintf(`package %s
+
+//go:generate oak
+type User struct {
+	ID       int
+	Name     string
+	Password string
+}
+`, name)
+		if err := os.WriteFile(filepath.Join(packageDir, "user.go"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create source file: %v", err)
+		}
+		packageDirs = append(packageDirs, packageDir)
+	}
+
+	return packageDirs
+}
+
+func TestRunGeneratesAllPackages(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 20)
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	for _, packageDir := range packageDirs {
+		content, err := os.ReadFile(filepath.Join(packageDir, "oak_gen.go"))
+		if err != nil {
+			t.Errorf("Expected generated file in %s: %v", packageDir, err)
+			continue
+		}
+
+		expected := "package " + filepath.Base(packageDir)
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Generated file in %s missing %q", packageDir, expected)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "oak_gen.go")); err == nil {
+		t.Errorf("Generated file should not be written to the working directory")
+	}
+}
+
+func TestRunSkipsUnchangedPackages(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// Replace the generated files so regeneration is observable
+	const marker = "// stale"
+	markStale := func(packageDir string) {
+		content := "package " + filepath.Base(packageDir) + "\n\n" + marker + "\n"
+		if err := os.WriteFile(filepath.Join(packageDir, "oak_gen.go"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to overwrite generated file: %v", err)
+		}
+	}
+	for _, packageDir := range packageDirs {
+		markStale(packageDir)
+	}
+
+	regenerated := func() []bool {
+		if err := run(t.Context(), []string{"./..."}); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+
+		var result []bool
+		for _, packageDir := range packageDirs {
+			content, err := os.ReadFile(filepath.Join(packageDir, "oak_gen.go"))
+			if err != nil {
+				t.Fatalf("Failed to read generated file: %v", err)
+			}
+			result = append(result, !strings.Contains(string(content), marker))
+		}
+		return result
+	}
+
+	if got := regenerated(); got[0] || got[1] {
+		t.Errorf("Unchanged packages should be skipped, regenerated: %v", got)
+	}
+
+	// Touching a source file reprocesses only its package
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(packageDirs[0], "user.go"), future, future); err != nil {
+		t.Fatalf("Failed to touch source file: %v", err)
+	}
+	if got := regenerated(); !got[0] || got[1] {
+		t.Errorf("Only the touched package should be regenerated, regenerated: %v", got)
+	}
+
+	// Changing oak.yaml invalidates every package
+	markStale(packageDirs[0])
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("redactKeys:\n  - name\n"), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	if got := regenerated(); !got[0] || !got[1] {
+		t.Errorf("All packages should be regenerated after a config change, regenerated: %v", got)
+	}
+}
+
+func TestRunIsIdempotent(t *testing.T) {
+	cacheFile := useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// Structs spread over several files are parsed in no particular order
+	for _, name := range []string{"account", "order", "invoice"} {
+		typeName := strings.ToUpper(name[:1]) + name[1:]
+		content := "package pkg00\n\n//go:generate oak\ntype " + typeName + " struct {\n\tID int\n}\n"
+		if err := os.WriteFile(filepath.Join(packageDirs[0], name+".go"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create source file: %v", err)
+		}
+	}
+
+	var outputs []string
+	for i := 0; i < 5; i++ {
+		// Regenerate from scratch rather than hitting the cache
+		os.Remove(cacheFile)
+		if err := run(t.Context(), []string{"./..."}); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(packageDirs[0], "oak_gen.go"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		outputs = append(outputs, string(content))
+	}
+
+	for i, output := range outputs[1:] {
+		if output != outputs[0] {
+			t.Errorf("Run %d produced different output:\n%s\nexpected:\n%s", i+2, output, outputs[0])
+		}
+	}
+}
+
+func TestRunSkipsPackagesWithoutLoggableFields(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	quietDir := filepath.Join(dir, "quiet")
+	if err := os.MkdirAll(quietDir, 0755); err != nil {
+		t.Fatalf("Failed to create package directory: %v", err)
+	}
+	content := `package quiet
+
+//go:generate oak
+type Secret struct {
+	Key   string ` + "`log:\"-\"`" + `
+	Value string ` + "`log:\"-\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(quietDir, "secret.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	if err := run(t.Context(), []string{"--emit-benchmarks", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	for _, name := range []string{"oak_gen.go", "oak_log_bench_test.go"} {
+		if _, err := os.Stat(filepath.Join(quietDir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected no %s for a package without loggable fields, got %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(packageDirs[0], name)); err != nil {
+			t.Errorf("Expected %s in %s: %v", name, packageDirs[0], err)
+		}
+	}
+}
+
+func TestRunWritesReportForCachedPackages(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// Unchanged packages are still reported
+	if err := run(t.Context(), []string{"--report", "report.json", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var summary report.Report
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+
+	if summary.Totals.Structs != 2 || summary.Totals.Redact != 2 {
+		t.Errorf("Expected 2 structs with 2 redacted fields, got %+v", summary.Totals)
+	}
+	if len(summary.Structs) > 0 && summary.Structs[0].Output != "pkg00/oak_gen.go" {
+		t.Errorf("Expected output pkg00/oak_gen.go, got %s", summary.Structs[0].Output)
+	}
+}
+
+func TestRunStrictRedact(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// Every configured key matches a field
+	if err := run(t.Context(), []string{"--strict-redact", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// Stale keys, including those of overrides, are reported even when the
+	// packages are unchanged
+	config := "redactKeys:\n  - password\n  - ssn\noverrides:\n  ./pkg00:\n    redactKeys:\n      - apiKey\n"
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run without --strict-redact failed: %v", err)
+	}
+
+	err := run(t.Context(), []string{"--strict-redact", "./..."})
+	if err == nil {
+		t.Fatalf("Expected error for unmatched redact keys")
+	}
+	expected := "2 redact key(s) matched no fields: apikey, ssn"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestRunStrictFields(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// The fixture struct logs three fields; exceeding the limit only warns
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("maxFields: 2\n"), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run without --strict-fields failed: %v", err)
+	}
+
+	err := run(t.Context(), []string{"--strict-fields", "./..."})
+	if err == nil {
+		t.Fatalf("Expected error for struct exceeding maxFields")
+	}
+	expected := "1 struct(s) log more fields than maxFields (2)"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("maxFields: 3\n"), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	if err := run(t.Context(), []string{"--strict-fields", "./..."}); err != nil {
+		t.Errorf("run within maxFields failed: %v", err)
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		setup    func(t *testing.T, dir string)
+		kind     string
+		expected int
+	}{
+		{
+			name:     "success",
+			args:     []string{"./..."},
+			expected: 0,
+		},
+		{
+			name:     "invalid arguments",
+			args:     []string{"--source", "missing.go"},
+			kind:     "config",
+			expected: exitConfig,
+		},
+		{
+			name: "invalid configuration",
+			args: []string{"./..."},
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("maxFields: -1\n"), 0644); err != nil {
+					t.Fatalf("Failed to update oak.yaml: %v", err)
+				}
+			},
+			kind:     "config",
+			expected: exitConfig,
+		},
+		{
+			name: "unparsable source",
+			args: []string{"./..."},
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "pkg00", "broken.go"), []byte("package pkg00\n\nfunc {\n"), 0644); err != nil {
+					t.Fatalf("Failed to create source file: %v", err)
+				}
+			},
+			kind:     "parse",
+			expected: exitParse,
+		},
+		{
+			name: "unwritable output",
+			args: []string{"./..."},
+			setup: func(t *testing.T, dir string) {
+				// A directory in place of the generated file cannot be written
+				if err := os.Mkdir(filepath.Join(dir, "pkg00", "oak_gen.go"), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+			},
+			kind:     "write",
+			expected: exitWrite,
+		},
+		{
+			name: "failed strict check",
+			args: []string{"--strict-fields", "./..."},
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("maxFields: 2\n"), 0644); err != nil {
+					t.Fatalf("Failed to update oak.yaml: %v", err)
+				}
+			},
+			kind:     "error",
+			expected: exitFailure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			useTempCache(t)
+			dir := t.TempDir()
+			writeFixturePackages(t, dir, 1)
+			t.Chdir(dir)
+			if tc.setup != nil {
+				tc.setup(t, dir)
+			}
+
+			err := run(t.Context(), tc.args)
+			if code := exitCode(err); code != tc.expected {
+				t.Fatalf("Exit code: expected %d, got %d (%v)", tc.expected, code, err)
+			}
+			if err == nil {
+				return
+			}
+
+			// The same error is reported as a JSON object on request
+			err = run(t.Context(), append([]string{"--json-errors"}, tc.args...))
+			var buf strings.Builder
+			printError(&buf, err)
+
+			var reported struct {
+				Error string `json:"error"`
+				Kind  string `json:"kind"`
+				Code  int    `json:"code"`
+			}
+			if jsonErr := json.Unmarshal([]byte(buf.String()), &reported); jsonErr != nil {
+				t.Fatalf("Failed to decode reported error %q: %v", buf.String(), jsonErr)
+			}
+			if reported.Error != err.Error() {
+				t.Errorf("Error: expected %q, got %q", err.Error(), reported.Error)
+			}
+			if reported.Kind != tc.kind {
+				t.Errorf("Kind: expected %s, got %s", tc.kind, reported.Kind)
+			}
+			if reported.Code != tc.expected {
+				t.Errorf("Code: expected %d, got %d", tc.expected, reported.Code)
+			}
+		})
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	var buf strings.Builder
+	printError(&buf, parseError(errors.New("failed to parse user.go")))
+	if buf.String() != "Error: failed to parse user.go\n" {
+		t.Errorf("Expected plain error, got %q", buf.String())
+	}
+}
+
+func TestRunStrictTypes(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// Parenthesized types are not rendered by the parser
+	content := `package pkg00
+
+//go:generate oak
+type Limits struct {
+	Max    (int)
+	Config struct {
+		Burst (int)
+	}
+	Skipped (int) ` + "`log:\"-\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "limits.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	// Unresolved types are only reported in verbose or strict mode
+	var runErr error
+	output := captureStderr(t, func() { runErr = run(t.Context(), []string{"./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+	if strings.Contains(output, "cannot resolve") {
+		t.Errorf("Expected no warnings without --verbose, got:\n%s", output)
+	}
+
+	output = captureStderr(t, func() { runErr = run(t.Context(), []string{"--strict-types", "./..."}) })
+	if runErr == nil {
+		t.Fatalf("Expected error for unresolved types")
+	}
+	expected := "2 field(s) have types oak cannot resolve"
+	if runErr.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, runErr.Error())
+	}
+	for _, warning := range []string{
+		"Warning: cannot resolve the type of field Max in Limits (pkg00/limits.go); it is logged by reflection\n",
+		"Warning: cannot resolve the type of field Config.Burst in Limits (pkg00/limits.go)",
+	} {
+		if !strings.Contains(output, warning) {
+			t.Errorf("Expected warning %q, got:\n%s", warning, output)
+		}
+	}
+	if strings.Contains(output, "Skipped") {
+		t.Errorf("Skipped fields should not be reported, got:\n%s", output)
+	}
+}
+
+func TestRunPostHook(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	// The hook runs once for each written file
+	config := "postHook: echo hooked >> {file}.log\n"
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	for _, pkg := range []string{"pkg00", "pkg01"} {
+		content, err := os.ReadFile(filepath.Join(dir, pkg, "oak_gen.go.log"))
+		if err != nil {
+			t.Fatalf("Expected the hook to run for %s: %v", pkg, err)
+		}
+		if string(content) != "hooked\n" {
+			t.Errorf("%s: expected hook output %q, got %q", pkg, "hooked\n", content)
+		}
+	}
+
+	// Failing hooks report their standard error
+	if err := os.Remove(filepath.Join(dir, "pkg00", "oak_gen.go")); err != nil {
+		t.Fatalf("Failed to remove generated file: %v", err)
+	}
+	config = "postHook: echo formatter crashed >&2; exit 3\n"
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to update oak.yaml: %v", err)
+	}
+	err := run(t.Context(), []string{"./..."})
+	if err == nil {
+		t.Fatalf("Expected error for failing post hook")
+	}
+	expected := "post hook failed for pkg00/oak_gen.go: exit status 3: formatter crashed"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestRunExportedOnly(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	session := `package pkg00
+
+//go:generate oak
+type session struct {
+	Token string
+}
+`
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "session.go"), []byte(session), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	testCases := []struct {
+		config   string
+		expected bool
+	}{
+		{"", true},
+		{"exportedOnly: true\n", false},
+	}
+
+	for _, tc := range testCases {
+		if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte(tc.config), 0644); err != nil {
+			t.Fatalf("Failed to update oak.yaml: %v", err)
+		}
+		if err := run(t.Context(), []string{"./..."}); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(packageDirs[0], "oak_gen.go"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		if !strings.Contains(string(content), "func (u User) LogValue() slog.Value") {
+			t.Errorf("config %q: expected LogValue for User, got:\n%s", tc.config, content)
+		}
+		if result := strings.Contains(string(content), "func (s session) LogValue() slog.Value"); result != tc.expected {
+			t.Errorf("config %q: expected LogValue for session %v, got %v", tc.config, tc.expected, result)
+		}
+	}
+}
+
+func TestRunInit(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"--init"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, err := config.LoadConfigFromPath(filepath.Join(dir, "oak.yaml")); err != nil {
+		t.Errorf("Expected a valid oak.yaml: %v", err)
+	}
+
+	// A second run refuses to overwrite it, also with the init command
+	err := run(t.Context(), []string{"init"})
+	if err == nil {
+		t.Fatalf("Expected error for existing oak.yaml")
+	}
+	if err.Error() != "oak.yaml already exists" {
+		t.Errorf("Expected error %q, got %q", "oak.yaml already exists", err.Error())
+	}
+}
+
+func TestRunList(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	order := `package pkg01
+
+//go:generate oak
+type Order struct {
+	ID    int
+	Token string ` + "`log:\"mask\"`" + `
+	Notes string ` + "`log:\"-\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(packageDirs[1], "order.go"), []byte(order), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"--list", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+
+	expected := `PACKAGE  STRUCT  FILE            FIELDS  LOGGED  REDACTED  SKIPPED
+pkg00    User    pkg00/user.go   3       2       1         0
+pkg01    Order   pkg01/order.go  3       1       1         1
+pkg01    User    pkg01/user.go   3       2       1         0
+`
+	if output != expected {
+		t.Errorf("Expected listing:\n%s\ngot:\n%s", expected, output)
+	}
+
+	// Nothing is generated
+	for _, packageDir := range packageDirs {
+		if _, err := os.Stat(filepath.Join(packageDir, "oak_gen.go")); !os.IsNotExist(err) {
+			t.Errorf("Expected no generated file in %s", packageDir)
+		}
+	}
+}
+
+func TestRunTypeKeepsPackageStructs(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	address := `package pkg00
+
+//go:generate oak
+type Address struct {
+	City string
+}
+
+//go:generate oak
+type Order struct {
+	ID int
+}
+`
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "address.go"), []byte(address), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	if err := run(t.Context(), []string{"--type", "Address", "./pkg00"}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// The other structs sharing the generated file keep their methods
+	content, err := os.ReadFile(filepath.Join(packageDirs[0], "oak_gen.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	for _, name := range []string{"Address", "Order", "User"} {
+		method := fmt.Sprintf("func (%s %s) LogValue() slog.Value", strings.ToLower(name[:1]), name)
+		if !strings.Contains(string(content), method) {
+			t.Errorf("Expected LogValue method for %s, got:\n%s", name, content)
+		}
+	}
+
+	// Other packages are not generated
+	if _, err := os.Stat(filepath.Join(packageDirs[1], "oak_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no generated file in %s", packageDirs[1])
+	}
+}
+
+func TestRunTypeRepeated(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// Repeating a run on unchanged sources still finds the struct
+	for i := 0; i < 2; i++ {
+		if err := run(t.Context(), []string{"--type", "User", "./pkg00"}); err != nil {
+			t.Fatalf("run %d failed: %v", i+1, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); err != nil {
+		t.Errorf("Expected generated file: %v", err)
+	}
+
+	if err := run(t.Context(), []string{"--type", "Missing", "./pkg00"}); err == nil {
+		t.Errorf("Expected an error for a missing type")
+	}
+}
+
+func TestRunReportsDuplicateStructs(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// A second declaration of User, as during a partial edit
+	content := "package pkg00\n\n//go:generate oak\ntype User struct {\n\tEmail string\n}\n"
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "account.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	err := run(t.Context(), []string{"./..."})
+	if err == nil {
+		t.Fatalf("Expected error for duplicate struct")
+	}
+	for _, expected := range []string{"struct User is declared in both", "account.go", "user.go"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got %q", expected, err.Error())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no generated file, got %v", err)
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureOutput(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn prints to standard error
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureOutput(t, &os.Stderr, fn)
+}
+
+// captureOutput returns what fn writes to the file *f, which is replaced by a
+// pipe while fn runs
+func captureOutput(t *testing.T, f **os.File, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	original := *f
+	*f = w
+	defer func() { *f = original }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-output
+}
+
+func TestRunCheck(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	// Files that have never been generated are out of date
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"check", "./..."}) })
+	if runErr == nil || runErr.Error() != "1 generated file(s) are out of date; run oak generate" {
+		t.Fatalf("Expected out of date error, got %v", runErr)
+	}
+	if exitCode(runErr) != exitFailure {
+		t.Errorf("Expected exit code %d, got %d", exitFailure, exitCode(runErr))
+	}
+	if !strings.HasPrefix(output, "--- /dev/null\n+++ pkg00/oak_gen.go\n") {
+		t.Errorf("Expected diff of the missing file, got:\n%s", output)
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected check not to write files, got %v", err)
+	}
+
+	// Once generated, the check passes and prints nothing
+	if err := run(t.Context(), []string{"generate", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	output = captureStdout(t, func() { runErr = run(t.Context(), []string{"check", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("Expected check to pass, got %v", runErr)
+	}
+	if output != "" {
+		t.Errorf("Expected no output, got:\n%s", output)
+	}
+}
+
+func TestRunKeepGoing(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 2)
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "broken.go"), []byte("package pkg00\n\nfunc {\n"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	t.Chdir(dir)
+
+	// The good package is generated, and the broken one fails the run at the end
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"--keep-going", "./..."}) })
+	if runErr == nil || !strings.Contains(runErr.Error(), "1 file(s) failed to parse") || !strings.Contains(runErr.Error(), "broken.go") {
+		t.Fatalf("Expected parse failure for broken.go, got %v", runErr)
+	}
+	if exitCode(runErr) != exitParse {
+		t.Errorf("Expected exit code %d, got %d", exitParse, exitCode(runErr))
+	}
+	if !strings.Contains(output, "Successfully processed 1 struct(s) in 1 package(s)") {
+		t.Errorf("Expected the good package to be processed, got:\n%s", output)
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[1], "oak_gen.go")); err != nil {
+		t.Errorf("Expected generated file for the good package: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no generated file for the broken package, got %v", err)
+	}
+
+	// Without the flag the broken file aborts the run
+	if err := run(t.Context(), []string{"./..."}); exitCode(err) != exitParse {
+		t.Errorf("Expected exit code %d, got %v", exitParse, err)
+	}
+
+	// Once fixed, the package that failed is generated despite the cache
+	if err := os.Remove(filepath.Join(packageDirs[0], "broken.go")); err != nil {
+		t.Fatalf("Failed to remove source file: %v", err)
+	}
+	if err := run(t.Context(), []string{"--keep-going", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); err != nil {
+		t.Errorf("Expected generated file once fixed: %v", err)
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	generatedPath := filepath.Join(packageDirs[0], "oak_gen.go")
+	before, err := os.ReadFile(generatedPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	// Change the struct and diff without writing
+	content := "package pkg00\n\n//go:generate oak\ntype User struct {\n\tID       int\n\tEmail    string\n\tPassword string\n}\n"
+	if err := os.WriteFile(filepath.Join(packageDirs[0], "user.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to update source file: %v", err)
+	}
+
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"--diff", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+
+	expected := "--- pkg00/oak_gen.go\n+++ pkg00/oak_gen.go\n" +
+		"@@ -10,7 +10,7 @@\n" +
+		" func (u User) LogValue() slog.Value {\n" +
+		" \tattrs := make([]slog.Attr, 0, 3)\n" +
+		" \tattrs = append(attrs, slog.Int64(\"ID\", int64(u.ID)))\n" +
+		"-\tattrs = append(attrs, slog.String(\"Name\", u.Name))\n" +
+		"+\tattrs = append(attrs, slog.String(\"Email\", u.Email))\n" +
+		" \tattrs = append(attrs, slog.String(\"Password\", \"[REDACTED]\"))\n" +
+		" \treturn slog.GroupValue(attrs...)\n" +
+		" }\n"
+	if output != expected {
+		t.Errorf("Expected diff:\n%s\ngot:\n%s", expected, output)
+	}
+
+	after, err := os.ReadFile(generatedPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("--diff should not write the generated file")
+	}
+
+	// The unwritten change is still generated by the next run
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	after, err = os.ReadFile(generatedPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	if !strings.Contains(string(after), `slog.String("Email", u.Email)`) {
+		t.Errorf("Expected regeneration after --diff, got:\n%s", after)
+	}
+}
+
+func TestRunParallelJoinsErrorsInOrder(t *testing.T) {
+	calls, err := runParallel(t.Context(), 10, 4, func(i int) error {
+		if i%3 == 0 {
+			return fmt.Errorf("job %d failed", i)
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("Expected joined error")
+	}
+	if calls != 10 {
+		t.Errorf("Expected 10 calls, got %d", calls)
+	}
+
+	expected := "job 0 failed\njob 3 failed\njob 6 failed\njob 9 failed"
+	if err.Error() != expected {
+		t.Errorf("Expected errors in index order %q, got %q", expected, err.Error())
+	}
+}
+
+func TestRunParallelStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var ran []int
+	calls, err := runParallel(ctx, 10, 1, func(i int) error {
+		ran = append(ran, i)
+		if i == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runParallel failed: %v", err)
+	}
+	if calls != 3 || !reflect.DeepEqual(ran, []int{0, 1, 2}) {
+		t.Errorf("Expected 3 calls for jobs [0 1 2], got %d for %v", calls, ran)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 3)
+	t.Chdir(dir)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	err := run(ctx, []string{"./..."})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation error, got %v", err)
+	}
+	expected := "interrupted while parsing after 0 of 3 path(s): context canceled"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+
+	// Nothing is generated or cached, so the next run processes everything
+	for _, packageDir := range packageDirs {
+		if _, err := os.Stat(filepath.Join(packageDir, "oak_gen.go")); !os.IsNotExist(err) {
+			t.Errorf("Expected no generated file in %s", packageDir)
+		}
+	}
+	output := captureStdout(t, func() { err = run(t.Context(), []string{"./..."}) })
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(output, "Successfully processed 3 struct(s) in 3 package(s)") {
+		t.Errorf("Expected all packages to be processed, got:\n%s", output)
+	}
+
+	// An elapsed timeout stops the run the same way
+	err = run(t.Context(), []string{"--timeout", "1ns", "./..."})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	dir := b.TempDir()
+	writeFixturePackages(b, dir, 100)
+	b.Chdir(dir)
+	cacheFile := useTempCache(b)
+
+	stdout := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		b.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer devNull.Close()
+
+	workerCounts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		workerCounts = append(workerCounts, n)
+	}
+
+	for _, workers := range workerCounts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			defer func(previous int) { maxWorkers = previous }(maxWorkers)
+			maxWorkers = workers
+
+			os.Stdout = devNull
+			defer func() { os.Stdout = stdout }()
+
+			for i := 0; i < b.N; i++ {
+				// Measure full runs rather than cache hits
+				os.Remove(cacheFile)
+
+				if err := run(b.Context(), []string{"./..."}); err != nil {
+					b.Fatalf("run failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunForce(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	generatedFile := filepath.Join(packageDirs[0], "oak_gen.go")
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(generatedFile, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	// Without --force the cached package is skipped
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"--fix", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+	if !strings.Contains(output, "Skipped 1 unchanged path(s)\n") {
+		t.Errorf("Expected the package to be skipped, got:\n%s", output)
+	}
+
+	// With it the package is regenerated and its identical file rewritten
+	output = captureStdout(t, func() { runErr = run(t.Context(), []string{"--force", "--fix", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+	if !strings.Contains(output, "Rewrote 1 file(s):\n  pkg00/oak_gen.go\n") {
+		t.Errorf("Expected the file to be rewritten, got:\n%s", output)
+	}
+	info, err := os.Stat(generatedFile)
+	if err != nil {
+		t.Fatalf("Failed to stat generated file: %v", err)
+	}
+	if info.ModTime().Equal(past) {
+		t.Errorf("Expected modification time to change from %v", past)
+	}
+}
+
+func TestRunInlineOutput(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 1)
+	if err := os.WriteFile(filepath.Join(dir, "oak.yaml"), []byte("inlineOutput: true\nredactKeys:\n  - password\n"), 0644); err != nil {
+		t.Fatalf("Failed to create oak.yaml: %v", err)
+	}
+	t.Chdir(dir)
+
+	if err := run(t.Context(), []string{"./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// The methods are spliced into the source file; no file is generated
+	if _, err := os.Stat(filepath.Join(packageDirs[0], "oak_gen.go")); !os.IsNotExist(err) {
+		t.Errorf("Expected no generated file, got %v", err)
+	}
+	sourceFile := filepath.Join(packageDirs[0], "user.go")
+	content, err := os.ReadFile(sourceFile)
+	if err != nil {
+		t.Fatalf("Failed to read source file: %v", err)
+	}
+	for _, expected := range []string{
+		"import \"log/slog\"\n",
+		"type User struct {",
+		"// oak:begin\n",
+		`attrs = append(attrs, slog.String("Password", "[REDACTED]"))`,
+		"// oak:end\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Source file missing %q, got:\n%s", expected, content)
+		}
+	}
+
+	// Regenerating replaces the region rather than appending another
+	if err := run(t.Context(), []string{"--force", "./..."}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	rewritten, err := os.ReadFile(sourceFile)
+	if err != nil {
+		t.Fatalf("Failed to read source file: %v", err)
+	}
+	if string(rewritten) != string(content) {
+		t.Errorf("Expected source file to be unchanged, got:\n%s", rewritten)
+	}
+}
+
+func TestRunFix(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	packageDirs := writeFixturePackages(t, dir, 2)
+	t.Chdir(dir)
+
+	var runErr error
+	output := captureStdout(t, func() { runErr = run(t.Context(), []string{"--fix", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+	if !strings.Contains(output, "Rewrote 2 file(s):\n  pkg00/oak_gen.go\n  pkg01/oak_gen.go\n") {
+		t.Errorf("Expected both files to be reported, got:\n%s", output)
+	}
+
+	// Touching a source regenerates its package, but the identical output is
+	// not rewritten
+	now := time.Now().Add(time.Second)
+	if err := os.Chtimes(filepath.Join(packageDirs[0], "user.go"), now, now); err != nil {
+		t.Fatalf("Failed to touch source file: %v", err)
+	}
+	output = captureStdout(t, func() { runErr = run(t.Context(), []string{"--fix", "./..."}) })
+	if runErr != nil {
+		t.Fatalf("run failed: %v", runErr)
+	}
+	if !strings.Contains(output, "Unchanged: pkg00/oak_gen.go\n") || !strings.Contains(output, "All generated files are up to date\n") {
+		t.Errorf("Expected no files to be rewritten, got:\n%s", output)
+	}
+}
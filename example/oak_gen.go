@@ -1,28 +1,32 @@
-// Code generated by oak. DO NOT EDIT.
+// Code generated by oak (devel); DO NOT EDIT.
+
 package main
 
 import "log/slog"
 
+var _ slog.LogValuer = Example{}
+
 // LogValue implements slog.LogValuer for Example
 func (e Example) LogValue() slog.Value {
-	return slog.GroupValue(
-		slog.Int64("IntValue", int64(e.IntValue)),
-		slog.String("StringValue", "[REDACTED]"),
-		slog.Float64("FloatValue", e.FloatValue),
-		slog.Any("NestedValue", e.NestedValue),
-	)
+	attrs := make([]slog.Attr, 0, 4)
+	attrs = append(attrs, slog.Int64("IntValue", int64(e.IntValue)))
+	attrs = append(attrs, slog.String("StringValue", "[REDACTED]"))
+	attrs = append(attrs, slog.Float64("FloatValue", e.FloatValue))
+	attrs = append(attrs, slog.Attr{Key: "NestedValue", Value: e.NestedValue.LogValue()})
+	return slog.GroupValue(attrs...)
 }
 
+var _ slog.LogValuer = NestedExample{}
+
 // LogValue implements slog.LogValuer for NestedExample
 func (n NestedExample) LogValue() slog.Value {
-	return slog.GroupValue(
-		slog.String("BoolValue", "[REDACTED]"),
-		slog.Any("SliceValue", n.SliceValue),
-		func() slog.Attr {
-			if n.PointerValue == nil {
-				return slog.String("PointerValue", "null")
-			}
-			return slog.String("PointerValue", *n.PointerValue)
-		}(),
-	)
+	attrs := make([]slog.Attr, 0, 3)
+	attrs = append(attrs, slog.String("BoolValue", "[REDACTED]"))
+	attrs = append(attrs, slog.Any("SliceValue", n.SliceValue))
+	if n.PointerValue == nil {
+		attrs = append(attrs, slog.String("PointerValue", "null"))
+	} else {
+		attrs = append(attrs, slog.String("PointerValue", *n.PointerValue))
+	}
+	return slog.GroupValue(attrs...)
 }
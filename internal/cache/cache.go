@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileState records the modification time and size of a source file
+type FileState struct {
+	ModTime int64 `json:"modTime"` // Modification time in nanoseconds
+	Size    int64 `json:"size"`    // File size in bytes
+}
+
+// Entry records the state of a processed path at the time of generation
+type Entry struct {
+	Files   map[string]FileState `json:"files"`   // Source files and their state
+	Outputs []string             `json:"outputs"` // Files generated from the path
+}
+
+// Cache tracks processed paths so unchanged ones can be skipped on later runs.
+// It is safe for concurrent use.
+type Cache struct {
+	path string
+	mu   sync.Mutex
+
+	Fingerprint string           `json:"fingerprint"` // Hash of config and options
+	Entries     map[string]Entry `json:"entries"`     // Entries keyed by absolute path
+}
+
+// DefaultPath returns the cache file location for the current working
+// directory, under the user's cache directory
+func DefaultPath() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	sum := sha256.Sum256([]byte(cwd))
+	return filepath.Join(dir, "oak", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Fingerprint returns a stable hash of the given values, used to invalidate
+// the cache when configuration or options change
+func Fingerprint(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache fingerprint: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Load reads the cache file at path. A missing or unreadable cache, or one
+// recorded with a different fingerprint, yields an empty cache.
+func Load(path, fingerprint string) *Cache {
+	c := &Cache{
+		path:        path,
+		Fingerprint: fingerprint,
+		Entries:     make(map[string]Entry),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+
+	var stored Cache
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Fingerprint != fingerprint {
+		return c
+	}
+	if stored.Entries != nil {
+		c.Entries = stored.Entries
+	}
+
+	return c
+}
+
+// Save writes the cache to disk
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache file %s: %w", c.path, err)
+	}
+
+	return nil
+}
+
+// Snapshot returns the state of the Go source files for a path, which is
+// either a single file or a package directory
+func Snapshot(path string) (map[string]FileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]FileState)
+
+	if !info.IsDir() {
+		files[filepath.Base(path)] = FileState{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+		return files, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		fileInfo, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = FileState{ModTime: fileInfo.ModTime().UnixNano(), Size: fileInfo.Size()}
+	}
+
+	return files, nil
+}
+
+// Unchanged reports whether path was recorded with the same source file
+// state and all of its generated outputs still exist
+func (c *Cache) Unchanged(path string, files map[string]FileState) bool {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	c.mu.Lock()
+	entry, ok := c.Entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	for _, output := range entry.Outputs {
+		if _, err := os.Stat(output); err != nil {
+			return false
+		}
+	}
+
+	sources := withoutOutputs(files, entry.Outputs)
+	if len(sources) != len(entry.Files) {
+		return false
+	}
+	for name, state := range sources {
+		if recorded, ok := entry.Files[name]; !ok || recorded != state {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Record stores the source file state and generated outputs for path
+func (c *Cache) Record(path string, files map[string]FileState, outputs []string) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	var absOutputs []string
+	for _, output := range outputs {
+		if abs, err := filepath.Abs(output); err == nil {
+			absOutputs = append(absOutputs, abs)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Entries[key] = Entry{
+		Files:   withoutOutputs(files, absOutputs),
+		Outputs: absOutputs,
+	}
+}
+
+// withoutOutputs returns the file states excluding generated output files,
+// whose state changes every time they are written
+func withoutOutputs(files map[string]FileState, outputs []string) map[string]FileState {
+	generated := make(map[string]bool)
+	for _, output := range outputs {
+		generated[filepath.Base(output)] = true
+	}
+
+	sources := make(map[string]FileState)
+	for name, state := range files {
+		if !generated[name] {
+			sources[name] = state
+		}
+	}
+
+	return sources
+}
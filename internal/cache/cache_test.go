@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSource creates a Go source file in dir and returns its path
+func writeSource(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create %s: %v", name, err)
+	}
+	return path
+}
+
+func snapshot(t *testing.T, path string) map[string]FileState {
+	t.Helper()
+
+	files, err := Snapshot(path)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	return files
+}
+
+func TestUnchangedFileIsSkipped(t *testing.T) {
+	dir := t.TempDir()
+	source := writeSource(t, dir, "user.go", "package user\n")
+	output := writeSource(t, dir, "oak_gen.go", "package user\n")
+
+	c := Load(filepath.Join(t.TempDir(), "cache.json"), "v1")
+	if c.Unchanged(dir, snapshot(t, dir)) {
+		t.Errorf("Path should not be unchanged before it is recorded")
+	}
+
+	c.Record(dir, snapshot(t, dir), []string{output})
+
+	if !c.Unchanged(dir, snapshot(t, dir)) {
+		t.Errorf("Unchanged package should be skipped")
+	}
+	if c.Unchanged(source, snapshot(t, source)) {
+		t.Errorf("Unrecorded file should not be skipped")
+	}
+
+	// Rewriting the generated output does not invalidate the entry
+	writeSource(t, dir, "oak_gen.go", "package user\n\n// regenerated\n")
+	if !c.Unchanged(dir, snapshot(t, dir)) {
+		t.Errorf("Changes to generated output should be ignored")
+	}
+}
+
+func TestTouchedFileIsReprocessed(t *testing.T) {
+	dir := t.TempDir()
+	source := writeSource(t, dir, "user.go", "package user\n")
+
+	c := Load(filepath.Join(t.TempDir(), "cache.json"), "v1")
+	c.Record(source, snapshot(t, source), nil)
+
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(source, future, future); err != nil {
+		t.Fatalf("Failed to touch source file: %v", err)
+	}
+
+	if c.Unchanged(source, snapshot(t, source)) {
+		t.Errorf("Touched file should be reprocessed")
+	}
+}
+
+func TestPackageChangesAreReprocessed(t *testing.T) {
+	testCases := []struct {
+		name   string
+		change func(t *testing.T, dir string)
+	}{
+		{
+			name: "file added",
+			change: func(t *testing.T, dir string) {
+				writeSource(t, dir, "account.go", "package user\n")
+			},
+		},
+		{
+			name: "file removed",
+			change: func(t *testing.T, dir string) {
+				os.Remove(filepath.Join(dir, "user.go"))
+			},
+		},
+		{
+			name: "file resized",
+			change: func(t *testing.T, dir string) {
+				writeSource(t, dir, "user.go", "package user\n\ntype User struct{}\n")
+			},
+		},
+		{
+			name: "output deleted",
+			change: func(t *testing.T, dir string) {
+				os.Remove(filepath.Join(dir, "oak_gen.go"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSource(t, dir, "user.go", "package user\n")
+			output := writeSource(t, dir, "oak_gen.go", "package user\n")
+
+			c := Load(filepath.Join(t.TempDir(), "cache.json"), "v1")
+			c.Record(dir, snapshot(t, dir), []string{output})
+
+			tc.change(t, dir)
+
+			if c.Unchanged(dir, snapshot(t, dir)) {
+				t.Errorf("Changed package should be reprocessed")
+			}
+		})
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	source := writeSource(t, dir, "user.go", "package user\n")
+	cachePath := filepath.Join(t.TempDir(), "nested", "cache.json")
+
+	c := Load(cachePath, "v1")
+	c.Record(source, snapshot(t, source), nil)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if !Load(cachePath, "v1").Unchanged(source, snapshot(t, source)) {
+		t.Errorf("Entry should survive a save and load")
+	}
+
+	// A different fingerprint, such as a changed oak.yaml, discards all entries
+	if Load(cachePath, "v2").Unchanged(source, snapshot(t, source)) {
+		t.Errorf("Entries should be invalidated when the fingerprint changes")
+	}
+}
+
+func TestLoadCorruptCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(cachePath, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("Failed to create cache file: %v", err)
+	}
+
+	c := Load(cachePath, "v1")
+	if len(c.Entries) != 0 {
+		t.Errorf("Corrupt cache should load empty, got %d entries", len(c.Entries))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, err := Fingerprint("v1", map[string]string{"redactMessage": "[REDACTED]"})
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	b, _ := Fingerprint("v1", map[string]string{"redactMessage": "[REDACTED]"})
+	c, _ := Fingerprint("v1", map[string]string{"redactMessage": "***"})
+
+	if a != b {
+		t.Errorf("Fingerprint should be stable, got %s and %s", a, b)
+	}
+	if a == c {
+		t.Errorf("Fingerprint should change with its inputs")
+	}
+}
@@ -3,19 +3,93 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// Commands selected by the leading verb of the arguments
+const (
+	CommandGenerate = "generate" // Generate code; the default without a verb
+	CommandCheck    = "check"    // Print a diff, failing if generated files are out of date
+	CommandInit     = "init"     // Write an example oak.yaml
+	CommandList     = "list"     // List the structs that would be generated
+)
+
+// commands are the verbs accepted as the first argument
+var commands = map[string]bool{
+	CommandGenerate: true,
+	CommandCheck:    true,
+	CommandInit:     true,
+	CommandList:     true,
+}
+
 // Options represents the parsed command-line options
 type Options struct {
+	// Command is the verb the arguments started with, or generate without one
+	Command string
+	
 	// SourceFile is the path to a specific Go source file to process
 	SourceFile string
 	
 	// PackagePath is the path to a package directory to process
 	PackagePath string
 	
+	// TypeName restricts generation to the struct with this exact name
+	TypeName string
+	
+	// EmitBenchmarks additionally generates a benchmark file for LogValue methods
+	EmitBenchmarks bool
+	
+	// Report is the path of a JSON summary of the generated structs to write
+	Report string
+	
+	// Diff prints a diff of each generated file instead of writing it
+	Diff bool
+	
+	// Check prints a diff like Diff, and fails the run when any generated
+	// file is out of date
+	Check bool
+	
+	// StrictRedact fails the run when a configured redact key matches no field
+	StrictRedact bool
+	
+	// StrictFields fails the run when a struct logs more fields than maxFields
+	StrictFields bool
+	
+	// StrictTypes fails the run when a logged field's type cannot be resolved
+	StrictTypes bool
+	
+	// KeepGoing generates the files that parse when others fail to, and
+	// fails the run once the parse errors have been reported
+	KeepGoing bool
+	
+	// Fix reports which generated files were rewritten; files whose content
+	// is unchanged are never written
+	Fix bool
+	
+	// Force regenerates every package and writes every generated file,
+	// ignoring the cache and files whose content is unchanged
+	Force bool
+	
+	// Verbose reports details such as the fields skipped by default
+	Verbose bool
+	
+	// List prints the structs carrying the directive and how many of their
+	// fields would be redacted or skipped, without generating anything
+	List bool
+	
+	// Init writes an example oak.yaml into the current directory
+	Init bool
+	
+	// Timeout bounds the total runtime; zero means no limit
+	Timeout time.Duration
+	
+	// JSONErrors prints errors to stderr as JSON objects
+	JSONErrors bool
+	
 	// PositionalArgs are the non-flag arguments (e.g., "./..." or "./pkg")
 	PositionalArgs []string
 	
@@ -50,15 +124,26 @@ type ProcessingTarget struct {
 	UseFlags bool // true if flags were used, false if positional args
 }
 
-// ParseArgs parses command-line arguments and returns Options
+// ParseArgs parses command-line arguments and returns Options. A leading
+// verb (generate, check, init, or list) selects the command; without one,
+// oak generates. The init and list commands are equivalent to the --init and
+// --list flags, and check to --diff failing on any difference.
 func ParseArgs(args []string) (*Options, error) {
-	opts := &Options{}
+	opts := &Options{Command: CommandGenerate}
+	if len(args) > 0 && commands[args[0]] {
+		opts.Command, args = args[0], args[1:]
+	}
 	
 	// Create a custom flag set to avoid conflicts with testing
 	fs := flag.NewFlagSet("oak", flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Fprintf(fs.Output(), "Usage: oak [options] [path]\n\n")
+		fmt.Fprintf(fs.Output(), "Usage: oak [command] [options] [path]\n\n")
 		fmt.Fprintf(fs.Output(), "Oak generates LogValue methods for Go structs to integrate with log/slog.\n\n")
+		fmt.Fprintf(fs.Output(), "Commands:\n")
+		fmt.Fprintf(fs.Output(), "  generate    Generate code (default)\n")
+		fmt.Fprintf(fs.Output(), "  check       Print a diff and fail if generated files are out of date\n")
+		fmt.Fprintf(fs.Output(), "  init        Write an example oak.yaml into the current directory\n")
+		fmt.Fprintf(fs.Output(), "  list        List the structs that would be generated\n\n")
 		fmt.Fprintf(fs.Output(), "Options:\n")
 		fs.PrintDefaults()
 		fmt.Fprintf(fs.Output(), "\nExamples:\n")
@@ -67,10 +152,29 @@ func ParseArgs(args []string) (*Options, error) {
 		fmt.Fprintf(fs.Output(), "  oak ./internal/booking        # Process specific package\n")
 		fmt.Fprintf(fs.Output(), "  oak --package ./internal/booking\n")
 		fmt.Fprintf(fs.Output(), "  oak --source ./booking.go     # Process specific file\n")
+		fmt.Fprintf(fs.Output(), "  oak --package ./booking --type Reservation\n")
+		fmt.Fprintf(fs.Output(), "  oak --report report.json ./...\n")
+		fmt.Fprintf(fs.Output(), "  oak check ./...               # Fail if generated files are out of date\n")
+		fmt.Fprintf(fs.Output(), "  oak init                      # Write an example oak.yaml\n")
 	}
 	
 	fs.StringVar(&opts.SourceFile, "source", "", "Path to a specific Go source file to process")
 	fs.StringVar(&opts.PackagePath, "package", "", "Path to a package directory to process")
+	fs.StringVar(&opts.TypeName, "type", "", "Name of a single struct to generate for")
+	fs.BoolVar(&opts.EmitBenchmarks, "emit-benchmarks", false, "Also generate benchmarks for the LogValue methods")
+	fs.StringVar(&opts.Report, "report", "", "Write a JSON summary of the generated structs to this file")
+	fs.BoolVar(&opts.Diff, "diff", false, "Print a diff of the changes instead of writing files")
+	fs.BoolVar(&opts.StrictRedact, "strict-redact", false, "Fail when a configured redact key matches no field")
+	fs.BoolVar(&opts.StrictFields, "strict-fields", false, "Fail when a struct logs more fields than maxFields")
+	fs.BoolVar(&opts.StrictTypes, "strict-types", false, "Fail when a logged field's type cannot be resolved")
+	fs.BoolVar(&opts.KeepGoing, "keep-going", false, "Generate for the files that parse, reporting the others at the end")
+	fs.BoolVar(&opts.Fix, "fix", false, "Report which generated files were rewritten")
+	fs.BoolVar(&opts.Force, "force", false, "Regenerate and write every file, ignoring the cache")
+	fs.BoolVar(&opts.Verbose, "verbose", false, "Report fields skipped by default, such as embedded interfaces")
+	fs.BoolVar(&opts.List, "list", false, "List the structs that would be generated, without generating")
+	fs.BoolVar(&opts.Init, "init", false, "Write an example oak.yaml into the current directory")
+	fs.BoolVar(&opts.JSONErrors, "json-errors", false, "Print errors to stderr as JSON objects")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "Stop the run after this long (e.g. 30s); no limit by default")
 	fs.BoolVar(&opts.Help, "help", false, "Show help message")
 	fs.BoolVar(&opts.Help, "h", false, "Show help message (shorthand)")
 	fs.BoolVar(&opts.Version, "version", false, "Show version information")
@@ -84,6 +188,16 @@ func ParseArgs(args []string) (*Options, error) {
 	// Get remaining positional arguments
 	opts.PositionalArgs = fs.Args()
 	
+	switch opts.Command {
+	case CommandCheck:
+		opts.Check = true
+		opts.Diff = true
+	case CommandInit:
+		opts.Init = true
+	case CommandList:
+		opts.List = true
+	}
+	
 	return opts, nil
 }
 
@@ -93,12 +207,36 @@ func (opts *Options) Validate() error {
 	if opts.SourceFile != "" && opts.PackagePath != "" {
 		return fmt.Errorf("--source and --package flags cannot be used together")
 	}
+	if opts.Check && (opts.Fix || opts.List) {
+		return fmt.Errorf("check cannot be used with --fix or --list")
+	}
+	if opts.Fix && opts.Diff {
+		return fmt.Errorf("--fix and --diff flags cannot be used together")
+	}
+	if opts.List && (opts.Fix || opts.Diff) {
+		return fmt.Errorf("--list cannot be used with --fix or --diff")
+	}
+	if opts.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must be positive", opts.Timeout)
+	}
 	
 	// If flags are used, positional arguments should be ignored
 	if (opts.SourceFile != "" || opts.PackagePath != "") && len(opts.PositionalArgs) > 0 {
 		fmt.Fprintf(os.Stderr, "Warning: Positional arguments ignored when using flags\n")
 	}
 	
+	// A type name must identify a single struct within a single target
+	if opts.TypeName != "" {
+		if !token.IsIdentifier(opts.TypeName) {
+			return fmt.Errorf("invalid type name: %s", opts.TypeName)
+		}
+		if opts.SourceFile == "" && opts.PackagePath == "" {
+			if len(opts.PositionalArgs) != 1 || strings.HasSuffix(opts.PositionalArgs[0], "/...") || IsGlobPattern(opts.PositionalArgs[0]) {
+				return fmt.Errorf("--type requires a single --source, --package, or package path")
+			}
+		}
+	}
+	
 	// Validate source file exists if specified
 	if opts.SourceFile != "" {
 		if !strings.HasSuffix(opts.SourceFile, ".go") {
@@ -118,7 +256,7 @@ func (opts *Options) Validate() error {
 	
 	// Validate positional arguments
 	for _, arg := range opts.PositionalArgs {
-		if arg != "./..." && arg != "." {
+		if !strings.HasSuffix(arg, "/...") && arg != "." && !IsGlobPattern(arg) {
 			// Check if it's a valid path
 			if _, err := os.Stat(arg); os.IsNotExist(err) {
 				return fmt.Errorf("path does not exist: %s", arg)
@@ -163,27 +301,75 @@ func (opts *Options) GetProcessingTarget() *ProcessingTarget {
 	return target
 }
 
-// ExpandPaths expands path patterns like "./..." into actual package paths
+// ExpandPaths expands path patterns into actual package paths. A trailing
+// "/..." (as in "./...") matches all packages below a directory, and glob
+// patterns such as "./internal/*/handlers" match directories containing Go
+// files. Patterns matching nothing expand to no paths.
 func ExpandPaths(paths []string) ([]string, error) {
 	var expanded []string
+	seen := make(map[string]bool)
+	
+	add := func(paths ...string) {
+		for _, path := range paths {
+			if !seen[path] {
+				seen[path] = true
+				expanded = append(expanded, path)
+			}
+		}
+	}
 	
 	for _, path := range paths {
-		if path == "./..." {
-			// Find all Go packages recursively
-			packages, err := findGoPackages(".")
+		base, recursive := strings.CutSuffix(path, "/...")
+		if !recursive && !IsGlobPattern(path) {
+			add(path)
+			continue
+		}
+		
+		dirs := []string{base}
+		if IsGlobPattern(base) {
+			matches, err := filepath.Glob(base)
 			if err != nil {
 				return nil, fmt.Errorf("failed to expand %s: %w", path, err)
 			}
-			expanded = append(expanded, packages...)
-		} else {
-			expanded = append(expanded, path)
+			dirs = matches
+		}
+		
+		for _, dir := range dirs {
+			if recursive {
+				// Find all Go packages recursively
+				packages, err := findGoPackages(dir)
+				if err != nil {
+					return nil, fmt.Errorf("failed to expand %s: %w", path, err)
+				}
+				add(packages...)
+				continue
+			}
+			
+			// Glob matches survive only if they are packages
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				continue
+			}
+			hasGoFiles, err := hasGoFilesInDir(dir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to expand %s: %w", path, err)
+			}
+			if hasGoFiles {
+				add(dir)
+			}
 		}
 	}
 	
 	return expanded, nil
 }
 
-// findGoPackages recursively finds all directories containing Go files
+// IsGlobPattern reports whether a path contains glob metacharacters
+func IsGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// findGoPackages recursively finds all directories containing Go files,
+// without descending into nested modules (directories other than root with
+// their own go.mod), which the go command treats the same way
 func findGoPackages(root string) ([]string, error) {
 	var packages []string
 	
@@ -192,12 +378,16 @@ func findGoPackages(root string) ([]string, error) {
 			return err
 		}
 		
-		// Skip hidden directories and vendor
-		if info.IsDir() {
+		// Skip hidden directories and vendor (but never the root itself,
+		// which is "." for ./...)
+		if info.IsDir() && path != root {
 			name := info.Name()
 			if strings.HasPrefix(name, ".") || name == "vendor" {
 				return filepath.SkipDir
 			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
 		}
 		
 		// Check if this directory contains Go files
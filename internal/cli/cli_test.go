@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"cmp"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseArgs(t *testing.T) {
@@ -66,6 +69,183 @@ func TestParseArgs(t *testing.T) {
 				PositionalArgs: []string{},
 			},
 		},
+		{
+			name: "type flag",
+			args: []string{"--package", "./booking", "--type", "Reservation"},
+			expected: &Options{
+				PackagePath:    "./booking",
+				TypeName:       "Reservation",
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "emit benchmarks flag",
+			args: []string{"--emit-benchmarks", "./..."},
+			expected: &Options{
+				EmitBenchmarks: true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "report flag",
+			args: []string{"--report", "report.json", "./..."},
+			expected: &Options{
+				Report:         "report.json",
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "diff flag",
+			args: []string{"--diff", "./..."},
+			expected: &Options{
+				Diff:           true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "strict redact flag",
+			args: []string{"--strict-redact"},
+			expected: &Options{
+				StrictRedact:   true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "strict fields flag",
+			args: []string{"--strict-fields"},
+			expected: &Options{
+				StrictFields:   true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "strict types flag",
+			args: []string{"--strict-types"},
+			expected: &Options{
+				StrictTypes:    true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "keep going flag",
+			args: []string{"--keep-going", "./..."},
+			expected: &Options{
+				KeepGoing:      true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "fix flag",
+			args: []string{"--fix", "./..."},
+			expected: &Options{
+				Fix:            true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "force flag",
+			args: []string{"--force", "./..."},
+			expected: &Options{
+				Force:          true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "verbose flag",
+			args: []string{"--verbose"},
+			expected: &Options{
+				Verbose:        true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "list flag",
+			args: []string{"--list", "./..."},
+			expected: &Options{
+				List:           true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "init flag",
+			args: []string{"--init"},
+			expected: &Options{
+				Init:           true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "timeout flag",
+			args: []string{"--timeout", "30s", "./..."},
+			expected: &Options{
+				Timeout:        30 * time.Second,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "json errors flag",
+			args: []string{"--json-errors", "./..."},
+			expected: &Options{
+				JSONErrors:     true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "generate command",
+			args: []string{"generate", "--verbose", "./..."},
+			expected: &Options{
+				Command:        CommandGenerate,
+				Verbose:        true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "check command",
+			args: []string{"check", "./..."},
+			expected: &Options{
+				Command:        CommandCheck,
+				Check:          true,
+				Diff:           true,
+				PositionalArgs: []string{"./..."},
+			},
+		},
+		{
+			name: "init command",
+			args: []string{"init"},
+			expected: &Options{
+				Command:        CommandInit,
+				Init:           true,
+				PositionalArgs: []string{},
+			},
+		},
+		{
+			name: "list command",
+			args: []string{"list", "--type", "User", "./booking"},
+			expected: &Options{
+				Command:        CommandList,
+				List:           true,
+				TypeName:       "User",
+				PositionalArgs: []string{"./booking"},
+			},
+		},
+		{
+			name: "command after a flag is a path",
+			args: []string{"--verbose", "list"},
+			expected: &Options{
+				Verbose:        true,
+				PositionalArgs: []string{"list"},
+			},
+		},
+		{
+			name:     "timeout flag with invalid duration",
+			args:     []string{"--timeout", "soon"},
+			hasError: true,
+		},
+		{
+			name:     "type flag without value",
+			args:     []string{"--type"},
+			hasError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -84,6 +264,11 @@ func TestParseArgs(t *testing.T) {
 				return
 			}
 			
+			// Arguments without a verb generate
+			if expected := cmp.Or(tc.expected.Command, CommandGenerate); opts.Command != expected {
+				t.Errorf("Command: expected %s, got %s", expected, opts.Command)
+			}
+			
 			if opts.SourceFile != tc.expected.SourceFile {
 				t.Errorf("SourceFile: expected %s, got %s", tc.expected.SourceFile, opts.SourceFile)
 			}
@@ -92,6 +277,70 @@ func TestParseArgs(t *testing.T) {
 				t.Errorf("PackagePath: expected %s, got %s", tc.expected.PackagePath, opts.PackagePath)
 			}
 			
+			if opts.TypeName != tc.expected.TypeName {
+				t.Errorf("TypeName: expected %s, got %s", tc.expected.TypeName, opts.TypeName)
+			}
+			
+			if opts.EmitBenchmarks != tc.expected.EmitBenchmarks {
+				t.Errorf("EmitBenchmarks: expected %v, got %v", tc.expected.EmitBenchmarks, opts.EmitBenchmarks)
+			}
+			
+			if opts.Report != tc.expected.Report {
+				t.Errorf("Report: expected %s, got %s", tc.expected.Report, opts.Report)
+			}
+			
+			if opts.Diff != tc.expected.Diff {
+				t.Errorf("Diff: expected %v, got %v", tc.expected.Diff, opts.Diff)
+			}
+			
+			if opts.Check != tc.expected.Check {
+				t.Errorf("Check: expected %v, got %v", tc.expected.Check, opts.Check)
+			}
+			
+			if opts.StrictRedact != tc.expected.StrictRedact {
+				t.Errorf("StrictRedact: expected %v, got %v", tc.expected.StrictRedact, opts.StrictRedact)
+			}
+			
+			if opts.StrictFields != tc.expected.StrictFields {
+				t.Errorf("StrictFields: expected %v, got %v", tc.expected.StrictFields, opts.StrictFields)
+			}
+			
+			if opts.StrictTypes != tc.expected.StrictTypes {
+				t.Errorf("StrictTypes: expected %v, got %v", tc.expected.StrictTypes, opts.StrictTypes)
+			}
+			
+			if opts.KeepGoing != tc.expected.KeepGoing {
+				t.Errorf("KeepGoing: expected %v, got %v", tc.expected.KeepGoing, opts.KeepGoing)
+			}
+			
+			if opts.Fix != tc.expected.Fix {
+				t.Errorf("Fix: expected %v, got %v", tc.expected.Fix, opts.Fix)
+			}
+			
+			if opts.Force != tc.expected.Force {
+				t.Errorf("Force: expected %v, got %v", tc.expected.Force, opts.Force)
+			}
+			
+			if opts.Verbose != tc.expected.Verbose {
+				t.Errorf("Verbose: expected %v, got %v", tc.expected.Verbose, opts.Verbose)
+			}
+			
+			if opts.List != tc.expected.List {
+				t.Errorf("List: expected %v, got %v", tc.expected.List, opts.List)
+			}
+			
+			if opts.Init != tc.expected.Init {
+				t.Errorf("Init: expected %v, got %v", tc.expected.Init, opts.Init)
+			}
+			
+			if opts.Timeout != tc.expected.Timeout {
+				t.Errorf("Timeout: expected %v, got %v", tc.expected.Timeout, opts.Timeout)
+			}
+			
+			if opts.JSONErrors != tc.expected.JSONErrors {
+				t.Errorf("JSONErrors: expected %v, got %v", tc.expected.JSONErrors, opts.JSONErrors)
+			}
+			
 			if opts.Help != tc.expected.Help {
 				t.Errorf("Help: expected %v, got %v", tc.expected.Help, opts.Help)
 			}
@@ -146,6 +395,42 @@ func TestValidate(t *testing.T) {
 			hasError: true,
 			errorMsg: "--source and --package flags cannot be used together",
 		},
+		{
+			name: "fix with diff",
+			opts: &Options{
+				Fix:  true,
+				Diff: true,
+			},
+			hasError: true,
+			errorMsg: "--fix and --diff flags cannot be used together",
+		},
+		{
+			name: "check with fix",
+			opts: &Options{
+				Check: true,
+				Diff:  true,
+				Fix:   true,
+			},
+			hasError: true,
+			errorMsg: "check cannot be used with --fix or --list",
+		},
+		{
+			name: "negative timeout",
+			opts: &Options{
+				Timeout: -time.Second,
+			},
+			hasError: true,
+			errorMsg: "invalid timeout -1s: must be positive",
+		},
+		{
+			name: "list with diff",
+			opts: &Options{
+				List: true,
+				Diff: true,
+			},
+			hasError: true,
+			errorMsg: "--list cannot be used with --fix or --diff",
+		},
 		{
 			name: "non-existent source file",
 			opts: &Options{
@@ -170,6 +455,48 @@ func TestValidate(t *testing.T) {
 			hasError: true,
 			errorMsg: "package path does not exist",
 		},
+		{
+			name: "type with package",
+			opts: &Options{
+				PackagePath: testDir,
+				TypeName:    "Reservation",
+			},
+			hasError: false,
+		},
+		{
+			name: "type with single positional path",
+			opts: &Options{
+				PositionalArgs: []string{testDir},
+				TypeName:       "Reservation",
+			},
+			hasError: false,
+		},
+		{
+			name: "type with recursive path",
+			opts: &Options{
+				PositionalArgs: []string{"./..."},
+				TypeName:       "Reservation",
+			},
+			hasError: true,
+			errorMsg: "--type requires a single",
+		},
+		{
+			name: "type without target",
+			opts: &Options{
+				TypeName: "Reservation",
+			},
+			hasError: true,
+			errorMsg: "--type requires a single",
+		},
+		{
+			name: "invalid type name",
+			opts: &Options{
+				PackagePath: testDir,
+				TypeName:    "booking.Reservation",
+			},
+			hasError: true,
+			errorMsg: "invalid type name",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -295,6 +622,146 @@ func TestHasGoFilesInDir(t *testing.T) {
 	}
 }
 
+func TestFindGoPackages(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	for _, dir := range []string{"a", "a/b", "nogo", ".hidden", "vendor/dep"} {
+		os.MkdirAll(filepath.Join(tempDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tempDir, "a", "a.go"), []byte("package a"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "a", "b", "b.go"), []byte("package b"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "nogo", "readme.txt"), []byte("readme"), 0644)
+	os.WriteFile(filepath.Join(tempDir, ".hidden", "h.go"), []byte("package h"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "vendor", "dep", "d.go"), []byte("package d"), 0644)
+	
+	t.Chdir(tempDir)
+	
+	packages, err := findGoPackages(".")
+	if err != nil {
+		t.Fatalf("findGoPackages failed: %v", err)
+	}
+	
+	expected := []string{"a", filepath.Join("a", "b")}
+	if len(packages) != len(expected) {
+		t.Fatalf("Expected packages %v, got %v", expected, packages)
+	}
+	for i, pkg := range expected {
+		if packages[i] != pkg {
+			t.Errorf("Package %d: expected %s, got %s", i, pkg, packages[i])
+		}
+	}
+}
+
+func TestFindGoPackagesSkipsNestedModules(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	for _, dir := range []string{"a", "tools/lint", "tools/lint/rules"} {
+		os.MkdirAll(filepath.Join(tempDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tempDir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "a", "a.go"), []byte("package a"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "tools", "tools.go"), []byte("package tools"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "tools", "lint", "go.mod"), []byte("module example.com/lint\n"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "tools", "lint", "lint.go"), []byte("package lint"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "tools", "lint", "rules", "rules.go"), []byte("package rules"), 0644)
+	
+	t.Chdir(tempDir)
+	
+	// The root's own go.mod does not stop the walk
+	packages, err := findGoPackages(".")
+	if err != nil {
+		t.Fatalf("findGoPackages failed: %v", err)
+	}
+	expected := []string{"a", "tools"}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected packages %v, got %v", expected, packages)
+	}
+	
+	// Walking from within the nested module covers it
+	packages, err = findGoPackages(filepath.Join("tools", "lint"))
+	if err != nil {
+		t.Fatalf("findGoPackages failed: %v", err)
+	}
+	expected = []string{filepath.Join("tools", "lint"), filepath.Join("tools", "lint", "rules")}
+	if !reflect.DeepEqual(packages, expected) {
+		t.Errorf("Expected packages %v, got %v", expected, packages)
+	}
+}
+
+func TestExpandPathsGlob(t *testing.T) {
+	tempDir := t.TempDir()
+	
+	for _, dir := range []string{"internal/users/handlers", "internal/orders/handlers", "internal/orders/handlers/v2", "internal/empty/handlers", "internal/users/models"} {
+		os.MkdirAll(filepath.Join(tempDir, dir), 0755)
+	}
+	os.WriteFile(filepath.Join(tempDir, "internal/users/handlers/h.go"), []byte("package handlers"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "internal/orders/handlers/h.go"), []byte("package handlers"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "internal/orders/handlers/v2/h.go"), []byte("package v2"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "internal/users/models/m.go"), []byte("package models"), 0644)
+	os.WriteFile(filepath.Join(tempDir, "internal/users/handlers/README"), []byte("readme"), 0644)
+	
+	t.Chdir(tempDir)
+	
+	testCases := []struct {
+		name     string
+		paths    []string
+		expected []string
+	}{
+		{
+			name:     "glob matches packages only",
+			paths:    []string{"./internal/*/handlers"},
+			expected: []string{"internal/orders/handlers", "internal/users/handlers"},
+		},
+		{
+			name:     "glob skips files",
+			paths:    []string{"./internal/users/handlers/*"},
+			expected: []string{},
+		},
+		{
+			name:     "recursive glob",
+			paths:    []string{"./internal/o*/..."},
+			expected: []string{"internal/orders/handlers", "internal/orders/handlers/v2"},
+		},
+		{
+			name:     "overlapping patterns are deduplicated",
+			paths:    []string{"./internal/*/handlers", "internal/users/handlers"},
+			expected: []string{"internal/orders/handlers", "internal/users/handlers"},
+		},
+		{
+			name:     "nonexistent glob",
+			paths:    []string{"./cmd/*/handlers"},
+			expected: []string{},
+		},
+		{
+			name:     "plain paths are kept",
+			paths:    []string{"./internal/users/models"},
+			expected: []string{"./internal/users/models"},
+		},
+	}
+	
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, err := ExpandPaths(tc.paths)
+			if err != nil {
+				t.Fatalf("ExpandPaths failed: %v", err)
+			}
+			
+			if len(paths) != len(tc.expected) {
+				t.Fatalf("Expected paths %v, got %v", tc.expected, paths)
+			}
+			for i, path := range tc.expected {
+				if paths[i] != filepath.FromSlash(path) {
+					t.Errorf("Path %d: expected %s, got %s", i, path, paths[i])
+				}
+			}
+		})
+	}
+	
+	if _, err := ExpandPaths([]string{"./internal/[/handlers"}); err == nil {
+		t.Errorf("Expected error for malformed glob")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 || 
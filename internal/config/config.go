@@ -1,32 +1,310 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/token"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Key casing styles for attribute keys
+const (
+	KeyCaseAsIs  = "asis"  // Use the Go field name verbatim
+	KeyCaseSnake = "snake" // user_id
+	KeyCaseCamel = "camel" // userId
+	KeyCaseKebab = "kebab" // user-id
+)
+
+// Loaders for reading Go source
+const (
+	LoaderAST      = "ast"      // Parse syntax only with go/parser (fast)
+	LoaderPackages = "packages" // Load type information with go/packages
+)
+
+// Output styles for nested structs
+const (
+	OutputStyleGrouped   = "grouped"   // Nested structs are logged as groups
+	OutputStyleFlattened = "flattened" // Nested fields are hoisted under dotted keys
+)
+
+// Strategies for building the body of slog LogValue methods
+const (
+	LogValueStyleSlice   = "slice"   // Append attributes to a preallocated slice
+	LogValueStyleClosure = "closure" // Pass every attribute to slog.GroupValue, using closures for conditions
+	LogValueStyleCompact = "compact" // Slice style, appending adjacent unconditional attributes together
+)
+
+// Orders in which fields are logged
+const (
+	FieldOrderSource       = "source"       // Declaration order
+	FieldOrderAlphabetical = "alphabetical" // Sorted by attribute key
+)
+
+// Ways time.Duration fields are logged
+const (
+	DurationFormatNanos  = "nanos"  // Log with slog.Duration, which JSON handlers write as nanoseconds
+	DurationFormatString = "string" // Log the String form, e.g. "1h30m0s"
+)
+
+// Ways nil pointer fields are logged
+const (
+	NilBehaviorNull = "null" // Log the string "null"
+	NilBehaviorOmit = "omit" // Leave the field out
+	NilBehaviorZero = "zero" // Log the zero value of the pointed-to type
+)
+
+// Logging backends generated code targets
+const (
+	BackendSlog    = "slog"    // LogValue methods for log/slog
+	BackendZap     = "zap"     // ZapFields methods for go.uber.org/zap
+	BackendZerolog = "zerolog" // MarshalZerologObject methods for github.com/rs/zerolog
+)
+
+// DefaultMethodName is the name of generated slog methods, which implement
+// slog.LogValuer under it
+const DefaultMethodName = "LogValue"
+
+// DefaultHashLength is the default number of hex characters logged for
+// fields tagged log:"hash"
+const DefaultHashLength = 16
+
+// DefaultMaxDepth is the default nesting depth of recursive structs logged
+// before their fields are replaced with a marker
+const DefaultMaxDepth = 5
+
+// Receiver forms for generated methods
+const (
+	ReceiverValue   = "value"   // func (u User) LogValue()
+	ReceiverPointer = "pointer" // func (u *User) LogValue()
+	ReceiverAuto    = "auto"    // Pointer receivers for structs with many fields
+)
+
 // Config represents the Oak configuration loaded from oak.yaml
 type Config struct {
-	// Packages is a list of package paths to scan for //go:generate oak directives
+	// Packages is a list of package paths to scan for //go:generate oak
+	// directives; entries may be glob patterns or end in "/..."
 	Packages []string `yaml:"packages"`
 	
-	// RedactKeys is a list of field names to automatically redact (case-insensitive)
+	// RedactKeys is a list of field names to automatically redact
+	// (case-insensitive); names may contain * wildcards, e.g. *password*
 	RedactKeys []string `yaml:"redactKeys"`
 	
-	// RedactMessage is the message to use for redacted fields
+	// RedactMessage is the message to use for redacted fields; {field} and
+	// {type} are replaced with the field's name and type
 	RedactMessage string `yaml:"redactMessage"`
+
+	// HashSalt is prepended to the values of fields tagged log:"hash" before
+	// hashing, so hashes cannot be matched against hashes of known values
+	HashSalt string `yaml:"hashSalt"`
+
+	// HashLength is the number of hex characters of the SHA-256 hash logged
+	// for fields tagged log:"hash" (1 to 64)
+	HashLength int `yaml:"hashLength"`
+
+	// Allowlist skips every field unless it is tagged log:"log" or its name
+	// matches AllowKeys, so new fields are not logged by accident
+	Allowlist bool `yaml:"allowlist"`
+
+	// AllowKeys is a list of field names logged when Allowlist is set
+	// (case-insensitive); names may contain * wildcards like redact keys
+	AllowKeys []string `yaml:"allowKeys"`
+
+	// Include is a list of glob or regex patterns matched against struct names;
+	// when non-empty, only matching structs are generated
+	Include []string `yaml:"include"`
+
+	// TimeFormat is the layout used to log time.Time fields as strings, either a
+	// time package constant name (e.g. RFC3339) or a custom layout; when empty,
+	// times are logged with slog.Time
+	TimeFormat string `yaml:"timeFormat"`
+
+	// DurationFormat controls how time.Duration fields are logged: nanos
+	// (default) logs them with slog.Duration, and string logs their String
+	// form such as "1h30m0s"
+	DurationFormat string `yaml:"durationFormat"`
+
+	// KeyCase controls how field names are converted into attribute keys
+	// (asis, snake, camel, or kebab)
+	KeyCase string `yaml:"keyCase"`
+
+	// UseJSONTagAsKey uses a field's json tag name as its attribute key; an
+	// explicit log:"name=..." still takes precedence
+	UseJSONTagAsKey bool `yaml:"useJSONTagAsKey"`
+	
+	// KeyPrefix is prepended verbatim to every attribute key (e.g. "booking."),
+	// after the key is chosen and cased, to namespace attributes by domain
+	KeyPrefix string `yaml:"keyPrefix"`
+
+	// GenerateRedacted additionally generates a Redacted() method returning a
+	// copy of the struct with sensitive fields replaced, for non-slog output
+	GenerateRedacted bool `yaml:"generateRedacted"`
+
+	// LogInterfaceTypes logs interface-typed fields as a group holding the
+	// value and its dynamic type name
+	LogInterfaceTypes bool `yaml:"logInterfaceTypes"`
+
+	// LogFuncFields logs fields of function type as "func" (or "null" when
+	// nil) instead of skipping them
+	LogFuncFields bool `yaml:"logFuncFields"`
+
+	// LogChanFields logs fields of channel type as their type, such as
+	// "<-chan int" (or "null" when nil), instead of skipping them
+	LogChanFields bool `yaml:"logChanFields"`
+
+	// LogEmbeddedInterfaces logs embedded interface fields (e.g. an embedded
+	// io.Reader) instead of skipping them
+	LogEmbeddedInterfaces bool `yaml:"logEmbeddedInterfaces"`
+
+	// Loader selects how Go source is read: ast parses syntax only, while
+	// packages type-checks the module so imported types can be resolved
+	Loader string `yaml:"loader"`
+
+	// OutputStyle controls how fields holding generated structs are logged:
+	// as a nested group (grouped) or hoisted under dotted keys (flattened)
+	OutputStyle string `yaml:"outputStyle"`
+
+	// LogValueStyle selects how slog LogValue methods build their group:
+	// slice (append to a preallocated []slog.Attr), compact (the same, with
+	// one append call per run of unconditional fields), or closure (a single
+	// slog.GroupValue call, with closures for pointer and omitted fields)
+	LogValueStyle string `yaml:"logValueStyle"`
+
+	// Format runs go/format on generated code (default true); disabling it
+	// writes the raw template output, e.g. to inspect code that fails to format
+	Format bool `yaml:"format"`
+
+	// SortMapKeys logs map fields as a group with an entry per key, in sorted
+	// key order, so output is deterministic; maps of generated structs are
+	// logged in sorted key order too
+	SortMapKeys bool `yaml:"sortMapKeys"`
+
+	// NilBehavior controls how nil pointer fields are logged: null (the
+	// string "null"), omit (left out), or zero (the zero value of the
+	// pointed-to type, or "null" when it cannot be named)
+	NilBehavior string `yaml:"nilBehavior"`
+
+	// OmitZero skips fields holding their zero value (empty string, 0, false,
+	// nil) at runtime; fields tagged log:"always" are still logged
+	OmitZero bool `yaml:"omitZero"`
+
+	// OmitZeroTime skips only time.Time fields holding the zero time
+	// (0001-01-01), which is rarely meaningful, unless tagged log:"always"
+	OmitZeroTime bool `yaml:"omitZeroTime"`
+
+	// CustomFormatters maps a field type (e.g. net.IP) to a fully-qualified
+	// function returning a slog.Attr (e.g. github.com/acme/logfmt.IPAttr),
+	// called as fn(key, value) in place of the built-in handling
+	CustomFormatters map[string]string `yaml:"customFormatters"`
+
+	// ContextPolicy is a fully-qualified function (e.g.
+	// github.com/acme/logpolicy.Apply) with the signature
+	// func(context.Context, slog.Value) slog.Value; when set, a LogValueCtx
+	// method passing the LogValue result through it is also generated
+	ContextPolicy string `yaml:"contextPolicy"`
+
+	// OutputDir, when set, is a directory (relative to oak.yaml) under which
+	// generated files are written at their path relative to oak.yaml, instead
+	// of beside the source files
+	OutputDir string `yaml:"outputDir"`
+
+	// InlineOutput writes the generated methods into the source file of each
+	// struct, between // oak:begin and // oak:end comments, instead of into a
+	// separate generated file
+	InlineOutput bool `yaml:"inlineOutput"`
+
+	// ExportedOnly generates code only for exported structs; unexported
+	// structs with the directive are skipped
+	ExportedOnly bool `yaml:"exportedOnly"`
+
+	// RedactUnexported redacts every unexported field, which often holds
+	// internal state such as credentials, whatever the redact keys
+	RedactUnexported bool `yaml:"redactUnexported"`
+
+	// IncludeTests scans _test.go files for directives; their structs are
+	// generated into test files
+	IncludeTests bool `yaml:"includeTests"`
+
+	// FieldOrder controls the order fields are logged in: source
+	// (declaration order) or alphabetical (by attribute key)
+	FieldOrder string `yaml:"fieldOrder"`
+
+	// MaxDepth limits how deeply structs nested in fields of their own type
+	// (directly or through other structs) are logged, so cyclic values do not
+	// recurse forever
+	MaxDepth int `yaml:"maxDepth"`
+
+	// MaxFields is the number of fields a struct may log before oak warns
+	// that its generated method is getting too large; zero means no limit
+	MaxFields int `yaml:"maxFields"`
+
+	// MaxSliceLen caps the number of elements logged for slice fields; longer
+	// slices are logged as their first MaxSliceLen elements followed by a
+	// "...(N more)" marker. Zero means no limit.
+	MaxSliceLen int `yaml:"maxSliceLen"`
+
+	// Backend selects the logging library generated code targets
+	Backend string `yaml:"backend"`
+
+	// MethodName is the name of the generated slog method; methods named
+	// other than LogValue do not implement slog.LogValuer
+	MethodName string `yaml:"methodName"`
+
+	// ReceiverType selects the receiver of generated LogValue methods: value,
+	// pointer, or auto (pointer for structs with many fields)
+	ReceiverType string `yaml:"receiverType"`
+
+	// PostHook is a shell command run after each generated file is written,
+	// with {file} replaced by the file's quoted path (e.g. goimports -w {file})
+	PostHook string `yaml:"postHook"`
+
+	// Dir is the directory of the loaded oak.yaml, which outputDir is
+	// relative to; empty for configurations not loaded from a file
+	Dir string `yaml:"-"`
+
+	// Overrides maps a package path, glob, or "/..." pattern to settings merged
+	// over the global configuration when generating matching packages
+	Overrides map[string]Override `yaml:"overrides"`
+}
+
+// Override holds per-package settings. Redact keys are added to the global
+// ones, and a non-empty redact message or key prefix replaces the global one.
+type Override struct {
+	RedactKeys    []string `yaml:"redactKeys"`
+	RedactMessage string   `yaml:"redactMessage"`
+	KeyPrefix     string   `yaml:"keyPrefix"`
 }
 
 // DefaultConfig returns a Config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		Packages:      []string{"."},
-		RedactKeys:    []string{},
-		RedactMessage: "[REDACTED]",
+		Packages:       []string{"."},
+		RedactKeys:     []string{},
+		RedactMessage:  "[REDACTED]",
+		Include:        []string{},
+		KeyCase:        KeyCaseAsIs,
+		OutputStyle:    OutputStyleGrouped,
+		LogValueStyle:  LogValueStyleSlice,
+		DurationFormat: DurationFormatNanos,
+		FieldOrder:     FieldOrderSource,
+		NilBehavior:    NilBehaviorNull,
+		Loader:         LoaderAST,
+		ReceiverType:   ReceiverValue,
+		Backend:        BackendSlog,
+		MaxDepth:       DefaultMaxDepth,
+		MethodName:     DefaultMethodName,
+		HashLength:     DefaultHashLength,
+		Format:         true,
 	}
 }
 
@@ -44,7 +322,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	if err := decodeConfig(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
@@ -52,6 +330,9 @@ func LoadConfig() (*Config, error) {
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration in %s: %w", configPath, err)
 	}
+	if abs, err := filepath.Abs(configPath); err == nil {
+		config.Dir = filepath.Dir(abs)
+	}
 
 	return config, nil
 }
@@ -64,7 +345,7 @@ func LoadConfigFromPath(configPath string) (*Config, error) {
 	}
 
 	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	if err := decodeConfig(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
@@ -72,10 +353,92 @@ func LoadConfigFromPath(configPath string) (*Config, error) {
 	if err := config.validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration in %s: %w", configPath, err)
 	}
+	if abs, err := filepath.Abs(configPath); err == nil {
+		config.Dir = filepath.Dir(abs)
+	}
 
 	return config, nil
 }
 
+// unknownFieldPattern matches yaml's error for a key with no matching field
+var unknownFieldPattern = regexp.MustCompile(`^line (\d+): field (\S+) not found in type`)
+
+// decodeConfig decodes YAML into config, rejecting keys that do not
+// correspond to a configuration option
+func decodeConfig(data []byte, config *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(config)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil // An empty file leaves the defaults in place
+	}
+
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	var messages []string
+	for _, message := range typeErr.Errors {
+		match := unknownFieldPattern.FindStringSubmatch(message)
+		if match == nil {
+			messages = append(messages, message)
+			continue
+		}
+
+		message = fmt.Sprintf("unknown key %q on line %s", match[2], match[1])
+		if suggestion := closestKey(match[2]); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		messages = append(messages, message)
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// closestKey returns the configuration key most similar to key, or an empty
+// string if none is close enough to be a likely typo
+func closestKey(key string) string {
+	best, bestDistance := "", 3
+	configType := reflect.TypeOf(Config{})
+
+	for i := 0; i < configType.NumField(); i++ {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if distance := editDistance(strings.ToLower(key), strings.ToLower(name)); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
 // findConfigFile searches for oak.yaml starting from the current directory
 // and moving up the directory tree until found or reaching the root
 func findConfigFile() (string, error) {
@@ -106,6 +469,16 @@ func (c *Config) validate() error {
 	// Normalize redact keys to lowercase for case-insensitive matching
 	for i, key := range c.RedactKeys {
 		c.RedactKeys[i] = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid redact key %s: %w", key, err)
+		}
+	}
+
+	for i, key := range c.AllowKeys {
+		c.AllowKeys[i] = strings.ToLower(key)
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid allow key %s: %w", key, err)
+		}
 	}
 
 	// Ensure redact message is not empty
@@ -113,11 +486,189 @@ func (c *Config) validate() error {
 		c.RedactMessage = "[REDACTED]"
 	}
 
+	// Validate the hash length, which cannot exceed a hex SHA-256 digest
+	if c.HashLength == 0 {
+		c.HashLength = DefaultHashLength
+	}
+	if c.HashLength < 1 || c.HashLength > 64 {
+		return fmt.Errorf("invalid hashLength %d: must be between 1 and 64", c.HashLength)
+	}
+
+	// Validate the key casing style
+	switch c.KeyCase {
+	case "":
+		c.KeyCase = KeyCaseAsIs
+	case KeyCaseAsIs, KeyCaseSnake, KeyCaseCamel, KeyCaseKebab:
+	default:
+		return fmt.Errorf("invalid keyCase %q: must be one of asis, snake, camel, kebab", c.KeyCase)
+	}
+
+	// Validate the source loader
+	switch c.Loader {
+	case "":
+		c.Loader = LoaderAST
+	case LoaderAST, LoaderPackages:
+	default:
+		return fmt.Errorf("invalid loader %q: must be one of ast, packages", c.Loader)
+	}
+
+	// Validate the nested struct output style
+	switch c.OutputStyle {
+	case "":
+		c.OutputStyle = OutputStyleGrouped
+	case OutputStyleGrouped, OutputStyleFlattened:
+	default:
+		return fmt.Errorf("invalid outputStyle %q: must be one of grouped, flattened", c.OutputStyle)
+	}
+
+	// Validate the LogValue body strategy
+	switch c.LogValueStyle {
+	case "":
+		c.LogValueStyle = LogValueStyleSlice
+	case LogValueStyleSlice, LogValueStyleClosure, LogValueStyleCompact:
+	default:
+		return fmt.Errorf("invalid logValueStyle %q: must be one of slice, closure, compact", c.LogValueStyle)
+	}
+
+	// Validate the duration format
+	switch c.DurationFormat {
+	case "":
+		c.DurationFormat = DurationFormatNanos
+	case DurationFormatNanos, DurationFormatString:
+	default:
+		return fmt.Errorf("invalid durationFormat %q: must be one of nanos, string", c.DurationFormat)
+	}
+
+	// Validate the field order
+	switch c.FieldOrder {
+	case "":
+		c.FieldOrder = FieldOrderSource
+	case FieldOrderSource, FieldOrderAlphabetical:
+	default:
+		return fmt.Errorf("invalid fieldOrder %q: must be one of source, alphabetical", c.FieldOrder)
+	}
+
+	// Validate the handling of nil pointers
+	switch c.NilBehavior {
+	case "":
+		c.NilBehavior = NilBehaviorNull
+	case NilBehaviorNull, NilBehaviorOmit, NilBehaviorZero:
+	default:
+		return fmt.Errorf("invalid nilBehavior %q: must be one of null, omit, zero", c.NilBehavior)
+	}
+
+	// Validate the logging backend
+	switch c.Backend {
+	case "":
+		c.Backend = BackendSlog
+	case BackendSlog, BackendZap, BackendZerolog:
+	default:
+		return fmt.Errorf("invalid backend %q: must be slog, zap, or zerolog", c.Backend)
+	}
+
+	// Validate the method name; other backends implement interfaces
+	// requiring their own names
+	switch {
+	case c.MethodName == "":
+		c.MethodName = DefaultMethodName
+	case !token.IsIdentifier(c.MethodName) || !token.IsExported(c.MethodName):
+		return fmt.Errorf("invalid methodName %q: must be an exported identifier", c.MethodName)
+	case c.MethodName != DefaultMethodName && c.Backend != BackendSlog:
+		return fmt.Errorf("methodName requires backend slog, got %s", c.Backend)
+	}
+
+	// Validate the nesting depth of recursive structs
+	switch {
+	case c.MaxDepth == 0:
+		c.MaxDepth = DefaultMaxDepth
+	case c.MaxDepth < 0:
+		return fmt.Errorf("invalid maxDepth %d: must be positive", c.MaxDepth)
+	}
+	if c.MaxFields < 0 {
+		return fmt.Errorf("invalid maxFields %d: must not be negative", c.MaxFields)
+	}
+	if c.MaxSliceLen < 0 {
+		return fmt.Errorf("invalid maxSliceLen %d: must not be negative", c.MaxSliceLen)
+	}
+	if c.InlineOutput && c.OutputDir != "" {
+		return fmt.Errorf("inlineOutput cannot be used with outputDir: generated code is written into the source files")
+	}
+
+	// Validate the receiver form
+	switch c.ReceiverType {
+	case "":
+		c.ReceiverType = ReceiverValue
+	case ReceiverValue, ReceiverPointer, ReceiverAuto:
+	default:
+		return fmt.Errorf("invalid receiverType %q: must be one of value, pointer, auto", c.ReceiverType)
+	}
+
+	// Validate include patterns are usable as a glob or a regex
+	for _, pattern := range c.Include {
+		if pattern == "" {
+			return fmt.Errorf("empty pattern in include list")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+			}
+		}
+	}
+
+	// Validate custom formatters name a type and a function
+	for typeName, function := range c.CustomFormatters {
+		if typeName == "" || function == "" {
+			return fmt.Errorf("custom formatter must map a type to a function, got %q: %q", typeName, function)
+		}
+		if strings.HasPrefix(typeName, "*") {
+			return fmt.Errorf("custom formatter type %s must not be a pointer; pointers to it are handled automatically", typeName)
+		}
+		name := function[strings.LastIndex(function, ".")+1:]
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("invalid custom formatter function %s for type %s", function, typeName)
+		}
+	}
+
+	// Validate the context policy names a function; LogValueCtx methods are
+	// only generated for slog
+	if c.ContextPolicy != "" {
+		name := c.ContextPolicy[strings.LastIndex(c.ContextPolicy, ".")+1:]
+		if !token.IsIdentifier(name) {
+			return fmt.Errorf("invalid contextPolicy function %s", c.ContextPolicy)
+		}
+		if c.Backend != BackendSlog {
+			return fmt.Errorf("contextPolicy requires backend slog, got %s", c.Backend)
+		}
+	}
+
+	// Validate override patterns and normalize their redact keys
+	for pattern, override := range c.Overrides {
+		if pattern == "" {
+			return fmt.Errorf("empty package pattern in overrides")
+		}
+		if _, err := filepath.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+			return fmt.Errorf("invalid override pattern %s: %w", pattern, err)
+		}
+		for i, key := range override.RedactKeys {
+			override.RedactKeys[i] = strings.ToLower(key)
+			if _, err := path.Match(key, ""); err != nil {
+				return fmt.Errorf("invalid redact key %s in override %s: %w", key, pattern, err)
+			}
+		}
+	}
+
 	// Validate package paths exist (basic validation)
 	for _, pkg := range c.Packages {
 		if pkg == "" {
 			return fmt.Errorf("empty package path in packages list")
 		}
+		// Patterns are expanded later and may match nothing
+		if strings.HasSuffix(pkg, "/...") || strings.ContainsAny(pkg, "*?[") {
+			if _, err := filepath.Match(strings.TrimSuffix(pkg, "/..."), ""); err != nil {
+				return fmt.Errorf("invalid package pattern %s: %w", pkg, err)
+			}
+			continue
+		}
 		// Convert relative paths to absolute for validation
 		if !filepath.IsAbs(pkg) {
 			absPath, err := filepath.Abs(pkg)
@@ -136,15 +687,141 @@ func (c *Config) validate() error {
 
 // ShouldRedactField checks if a field name should be redacted based on the configuration
 func (c *Config) ShouldRedactField(fieldName string) bool {
+	_, ok := c.MatchRedactKey(fieldName)
+	return ok
+}
+
+// MatchRedactKey returns the configured redact key matching a field name.
+// Keys may be glob patterns such as *password*, matched with path.Match.
+func (c *Config) MatchRedactKey(fieldName string) (string, bool) {
+	return matchKey(c.RedactKeys, fieldName)
+}
+
+// IsAllowedField reports whether a field name matches one of the allow keys
+func (c *Config) IsAllowedField(fieldName string) bool {
+	_, ok := matchKey(c.AllowKeys, fieldName)
+	return ok
+}
+
+// matchKey returns the lowercase key or glob pattern matching a field name
+func matchKey(keys []string, fieldName string) (string, bool) {
 	fieldLower := strings.ToLower(fieldName)
-	for _, redactKey := range c.RedactKeys {
-		if fieldLower == redactKey {
+	for _, key := range keys {
+		if fieldLower == key {
+			return key, true
+		}
+		if matched, _ := path.Match(key, fieldLower); matched {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// AllRedactKeys returns the global redact keys along with those of every
+// override, sorted and deduplicated
+func (c *Config) AllRedactKeys() []string {
+	keys := slices.Clone(c.RedactKeys)
+	for _, override := range c.Overrides {
+		keys = append(keys, override.RedactKeys...)
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// ShouldIncludeStruct checks if a struct name matches the include patterns.
+// All structs are included when no patterns are configured.
+func (c *Config) ShouldIncludeStruct(structName string) bool {
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, pattern := range c.Include {
+		if matchPattern(pattern, structName) {
 			return true
 		}
 	}
 	return false
 }
 
+// matchPattern reports whether name matches pattern, trying the pattern as a
+// glob first and then as a regex anchored to the whole name
+func matchPattern(pattern, name string) bool {
+	if matched, err := path.Match(pattern, name); err == nil && matched {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
+
+// ForPackage returns the configuration for the package in directory dir, with
+// the matching overrides merged over the global settings. Overrides with
+// longer, more specific patterns are applied last and so take precedence. The
+// receiver is returned unchanged when no override matches.
+func (c *Config) ForPackage(dir string) *Config {
+	var patterns []string
+	for pattern := range c.Overrides {
+		if matchPackage(pattern, dir) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return c
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) < len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	merged := *c
+	merged.RedactKeys = slices.Clone(c.RedactKeys)
+	for _, pattern := range patterns {
+		override := c.Overrides[pattern]
+		merged.RedactKeys = append(merged.RedactKeys, override.RedactKeys...)
+		if override.RedactMessage != "" {
+			merged.RedactMessage = override.RedactMessage
+		}
+		if override.KeyPrefix != "" {
+			merged.KeyPrefix = override.KeyPrefix
+		}
+	}
+
+	return &merged
+}
+
+// matchPackage reports whether the package directory dir matches pattern, a
+// package path that may be a glob or end in "/..." as in Packages
+func matchPackage(pattern, dir string) bool {
+	base, recursive := strings.CutSuffix(pattern, "/...")
+	base, dir = absPath(base), absPath(dir)
+
+	for {
+		if matched, _ := filepath.Match(base, dir); matched {
+			return true
+		}
+
+		// Recursive patterns also match any package below the base
+		parent := filepath.Dir(dir)
+		if !recursive || parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// absPath returns the absolute form of path, or the cleaned path if it cannot
+// be made absolute
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
 // GetPackages returns the list of packages to process
 func (c *Config) GetPackages() []string {
 	if len(c.Packages) == 0 {
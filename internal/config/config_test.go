@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -128,6 +130,48 @@ redactMessage: "[HIDDEN]"`
 	if config.RedactMessage != "[HIDDEN]" {
 		t.Errorf("Expected redact message to be '[HIDDEN]', got %s", config.RedactMessage)
 	}
+
+	if config.Dir != tempDir {
+		t.Errorf("Expected config dir to be %s, got %s", tempDir, config.Dir)
+	}
+}
+
+func TestLoadConfigFromPathUnknownKey(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "oak.yaml")
+
+	configContent := `redactMessage: "[HIDDEN]"
+redactKey:
+  - password`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	_, err := LoadConfigFromPath(configPath)
+	if err == nil {
+		t.Fatalf("Expected error for unknown key")
+	}
+
+	expected := `unknown key "redactKey" on line 2 (did you mean "redactKeys"?)`
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error to contain %q, got %q", expected, err.Error())
+	}
+}
+
+func TestLoadConfigFromPathEmpty(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "oak.yaml")
+	if err := os.WriteFile(configPath, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load empty config: %v", err)
+	}
+	if config.RedactMessage != "[REDACTED]" {
+		t.Errorf("Expected default redact message, got %s", config.RedactMessage)
+	}
 }
 
 func TestGetPackages(t *testing.T) {
@@ -151,3 +195,586 @@ func TestGetPackages(t *testing.T) {
 		t.Errorf("Expected default package ['.'], got %v", packages)
 	}
 }
+
+func TestShouldIncludeStruct(t *testing.T) {
+	testCases := []struct {
+		name       string
+		include    []string
+		structName string
+		expected   bool
+	}{
+		{"empty include matches all", nil, "User", true},
+		{"literal name match", []string{"User"}, "User", true},
+		{"literal name mismatch", []string{"User"}, "UserProfile", false},
+		{"glob wildcard match", []string{"User*"}, "UserProfile", true},
+		{"glob wildcard mismatch", []string{"User*"}, "Account", false},
+		{"regex match", []string{"(Order|Invoice)Item"}, "InvoiceItem", true},
+		{"regex is anchored", []string{"Order"}, "PurchaseOrder", false},
+		{"any pattern matches", []string{"Account", "*Request"}, "CreateRequest", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Config{Include: tc.include}
+			result := config.ShouldIncludeStruct(tc.structName)
+			if result != tc.expected {
+				t.Errorf("ShouldIncludeStruct(%s) with %v = %v, expected %v",
+					tc.structName, tc.include, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConfigValidationPackagePatterns(t *testing.T) {
+	config := &Config{
+		Packages: []string{"./internal/*/handlers", "./pkg/...", "./missing/*"},
+	}
+	if err := config.validate(); err != nil {
+		t.Errorf("Package patterns should not need to exist: %v", err)
+	}
+
+	config = &Config{
+		Packages: []string{"./internal/[/handlers"},
+	}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for malformed package pattern")
+	}
+}
+
+func TestConfigValidationCustomFormatters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		formatters map[string]string
+		hasError   bool
+	}{
+		{"qualified function", map[string]string{"net.IP": "github.com/acme/logfmt.IPAttr"}, false},
+		{"local function", map[string]string{"Status": "statusAttr"}, false},
+		{"empty function", map[string]string{"net.IP": ""}, true},
+		{"pointer type", map[string]string{"*net.IP": "logfmt.IPAttr"}, true},
+		{"missing function name", map[string]string{"net.IP": "github.com/acme/logfmt."}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Config{CustomFormatters: tc.formatters}
+			err := config.validate()
+			if tc.hasError && err == nil {
+				t.Errorf("Expected error for formatters %v", tc.formatters)
+			}
+			if !tc.hasError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigValidationLoader(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if config.Loader != LoaderAST {
+		t.Errorf("Expected empty loader to default to %s, got %s", LoaderAST, config.Loader)
+	}
+
+	config = &Config{Loader: LoaderPackages}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for packages loader: %v", err)
+	}
+
+	config = &Config{Loader: "gopls"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid loader")
+	}
+}
+
+func TestConfigValidationInvalidInclude(t *testing.T) {
+	config := &Config{
+		Include: []string{"User["},
+	}
+
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid include pattern")
+	}
+}
+
+func TestConfigValidationKeyCase(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if config.KeyCase != KeyCaseAsIs {
+		t.Errorf("Expected empty key case to default to %s, got %s", KeyCaseAsIs, config.KeyCase)
+	}
+
+	config = &Config{KeyCase: "SCREAMING"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid key case")
+	}
+}
+
+func TestConfigValidationOutputStyle(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if config.OutputStyle != OutputStyleGrouped {
+		t.Errorf("Expected empty output style to default to %s, got %s", OutputStyleGrouped, config.OutputStyle)
+	}
+
+	config = &Config{OutputStyle: OutputStyleFlattened}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for flattened output style: %v", err)
+	}
+
+	config = &Config{OutputStyle: "nested"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid output style")
+	}
+}
+
+func TestForPackage(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "oak.yaml")
+
+	configContent := `redactKeys:
+  - password
+keyPrefix: app.
+overrides:
+  ./internal/...:
+    redactKeys:
+      - Token
+  ./internal/payments:
+    redactKeys:
+      - cardNumber
+    redactMessage: "[PCI]"
+    keyPrefix: payments.
+  ./internal/*/handlers:
+    redactMessage: "[HANDLER]"
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	t.Chdir(tempDir)
+
+	config, err := LoadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	testCases := []struct {
+		dir           string
+		redactKeys    []string
+		redactMessage string
+		keyPrefix     string
+	}{
+		// No override applies
+		{"./cmd/server", []string{"password"}, "[REDACTED]", "app."},
+		// Recursive patterns match the base and packages below it
+		{"./internal", []string{"password", "token"}, "[REDACTED]", "app."},
+		{"./internal/users", []string{"password", "token"}, "[REDACTED]", "app."},
+		// More specific patterns are merged last
+		{"./internal/payments", []string{"password", "token", "cardnumber"}, "[PCI]", "payments."},
+		{"./internal/users/handlers", []string{"password", "token"}, "[HANDLER]", "app."},
+		// Absolute directories, as from the packages loader, match too
+		{filepath.Join(tempDir, "internal", "payments"), []string{"password", "token", "cardnumber"}, "[PCI]", "payments."},
+	}
+
+	for _, tc := range testCases {
+		resolved := config.ForPackage(tc.dir)
+		if !reflect.DeepEqual(resolved.RedactKeys, tc.redactKeys) {
+			t.Errorf("%s: expected redact keys %v, got %v", tc.dir, tc.redactKeys, resolved.RedactKeys)
+		}
+		if resolved.RedactMessage != tc.redactMessage {
+			t.Errorf("%s: expected redact message %s, got %s", tc.dir, tc.redactMessage, resolved.RedactMessage)
+		}
+		if resolved.KeyPrefix != tc.keyPrefix {
+			t.Errorf("%s: expected key prefix %s, got %s", tc.dir, tc.keyPrefix, resolved.KeyPrefix)
+		}
+	}
+
+	// Resolving an override leaves the global configuration untouched
+	if !reflect.DeepEqual(config.RedactKeys, []string{"password"}) || config.RedactMessage != "[REDACTED]" {
+		t.Errorf("Global configuration was modified: %v %s", config.RedactKeys, config.RedactMessage)
+	}
+	if config.ForPackage("./cmd/server") != config {
+		t.Errorf("Expected the global configuration when no override matches")
+	}
+}
+
+func TestConfigValidationOverrides(t *testing.T) {
+	config := &Config{Overrides: map[string]Override{"./internal/[": {}}}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for malformed override pattern")
+	}
+
+	config = &Config{Overrides: map[string]Override{"": {}}}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for empty override pattern")
+	}
+}
+
+func TestMatchRedactKey(t *testing.T) {
+	config := &Config{
+		RedactKeys: []string{"password", "token"},
+		Overrides: map[string]Override{
+			"./a": {RedactKeys: []string{"ssn", "token"}},
+		},
+	}
+
+	if key, ok := config.MatchRedactKey("APIToken"); ok {
+		t.Errorf("Expected no match for APIToken, got %s", key)
+	}
+	if key, ok := config.MatchRedactKey("Token"); !ok || key != "token" {
+		t.Errorf("Expected match token for Token, got %q", key)
+	}
+
+	expected := []string{"password", "ssn", "token"}
+	if keys := config.AllRedactKeys(); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("AllRedactKeys: expected %v, got %v", expected, keys)
+	}
+}
+
+func TestConfigValidationReceiverType(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.ReceiverType != ReceiverValue {
+		t.Errorf("Expected receiverType to default to %s, got %s", ReceiverValue, config.ReceiverType)
+	}
+
+	config = &Config{ReceiverType: "reference"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid receiverType")
+	}
+}
+
+func TestConfigValidationBackend(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.Backend != BackendSlog {
+		t.Errorf("Expected backend to default to %s, got %s", BackendSlog, config.Backend)
+	}
+
+	for _, backend := range []string{BackendZap, BackendZerolog} {
+		config = &Config{Backend: backend}
+		if err := config.validate(); err != nil {
+			t.Errorf("Unexpected error for %s backend: %v", backend, err)
+		}
+	}
+
+	config = &Config{Backend: "logrus"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid backend")
+	}
+}
+
+func TestConfigValidationFieldOrder(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.FieldOrder != FieldOrderSource {
+		t.Errorf("Expected fieldOrder to default to %s, got %s", FieldOrderSource, config.FieldOrder)
+	}
+
+	config = &Config{FieldOrder: FieldOrderAlphabetical}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for alphabetical fieldOrder: %v", err)
+	}
+
+	config = &Config{FieldOrder: "random"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid fieldOrder")
+	}
+}
+
+func TestConfigValidationNilBehavior(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.NilBehavior != NilBehaviorNull {
+		t.Errorf("Expected nilBehavior to default to %s, got %s", NilBehaviorNull, config.NilBehavior)
+	}
+
+	for _, behavior := range []string{NilBehaviorNull, NilBehaviorOmit, NilBehaviorZero} {
+		config = &Config{NilBehavior: behavior}
+		if err := config.validate(); err != nil {
+			t.Errorf("Unexpected error for %s nilBehavior: %v", behavior, err)
+		}
+	}
+
+	config = &Config{NilBehavior: "empty"}
+	err := config.validate()
+	if err == nil {
+		t.Fatalf("Expected error for invalid nilBehavior")
+	}
+	expected := `invalid nilBehavior "empty": must be one of null, omit, zero`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestConfigValidationContextPolicy(t *testing.T) {
+	testCases := []struct {
+		policy    string
+		backend   string
+		expectErr bool
+	}{
+		{"github.com/acme/logpolicy.Apply", "", false},
+		{"policy", BackendSlog, false},
+		{"github.com/acme/logpolicy.", "", true},
+		{"github.com/acme/logpolicy.Apply", BackendZap, true},
+	}
+
+	for _, tc := range testCases {
+		config := &Config{ContextPolicy: tc.policy, Backend: tc.backend}
+		err := config.validate()
+		if tc.expectErr && err == nil {
+			t.Errorf("Expected error for contextPolicy %q with backend %q", tc.policy, tc.backend)
+		}
+		if !tc.expectErr && err != nil {
+			t.Errorf("Unexpected error for contextPolicy %q: %v", tc.policy, err)
+		}
+	}
+}
+
+func TestConfigValidationMaxDepth(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.MaxDepth != DefaultMaxDepth {
+		t.Errorf("Expected maxDepth to default to %d, got %d", DefaultMaxDepth, config.MaxDepth)
+	}
+
+	config = &Config{MaxDepth: 2}
+	if err := config.validate(); err != nil || config.MaxDepth != 2 {
+		t.Errorf("Expected maxDepth 2 to be kept, got %d (%v)", config.MaxDepth, err)
+	}
+
+	config = &Config{MaxDepth: -1}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for negative maxDepth")
+	}
+}
+
+func TestConfigValidationMaxFields(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil || config.MaxFields != 0 {
+		t.Errorf("Expected maxFields to default to unlimited, got %d (%v)", config.MaxFields, err)
+	}
+
+	config = &Config{MaxFields: -1}
+	err := config.validate()
+	if err == nil {
+		t.Fatalf("Expected error for negative maxFields")
+	}
+	if err.Error() != "invalid maxFields -1: must not be negative" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidationMaxSliceLen(t *testing.T) {
+	config := &Config{MaxSliceLen: 10}
+	if err := config.validate(); err != nil || config.MaxSliceLen != 10 {
+		t.Errorf("Expected maxSliceLen 10 to be kept, got %d (%v)", config.MaxSliceLen, err)
+	}
+
+	config = &Config{MaxSliceLen: -1}
+	err := config.validate()
+	if err == nil {
+		t.Fatalf("Expected error for negative maxSliceLen")
+	}
+	if err.Error() != "invalid maxSliceLen -1: must not be negative" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidationInlineOutput(t *testing.T) {
+	config := &Config{InlineOutput: true}
+	if err := config.validate(); err != nil {
+		t.Errorf("Expected inlineOutput to be valid, got %v", err)
+	}
+
+	config = &Config{InlineOutput: true, OutputDir: "gen"}
+	err := config.validate()
+	if err == nil {
+		t.Fatalf("Expected error for inlineOutput with outputDir")
+	}
+	if !strings.Contains(err.Error(), "inlineOutput cannot be used with outputDir") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidationLogValueStyle(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.LogValueStyle != LogValueStyleSlice {
+		t.Errorf("Expected logValueStyle to default to %s, got %s", LogValueStyleSlice, config.LogValueStyle)
+	}
+
+	config = &Config{LogValueStyle: LogValueStyleClosure}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for closure logValueStyle: %v", err)
+	}
+
+	config = &Config{LogValueStyle: LogValueStyleCompact}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for compact logValueStyle: %v", err)
+	}
+
+	config = &Config{LogValueStyle: "builder"}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for invalid logValueStyle")
+	}
+}
+
+func TestConfigValidationDurationFormat(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.DurationFormat != DurationFormatNanos {
+		t.Errorf("Expected durationFormat to default to %s, got %s", DurationFormatNanos, config.DurationFormat)
+	}
+
+	config = &Config{DurationFormat: DurationFormatString}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for string durationFormat: %v", err)
+	}
+
+	config = &Config{DurationFormat: "seconds"}
+	err := config.validate()
+	if err == nil {
+		t.Fatalf("Expected error for invalid durationFormat")
+	}
+	if err.Error() != `invalid durationFormat "seconds": must be one of nanos, string` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConfigValidationHashLength(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.HashLength != DefaultHashLength {
+		t.Errorf("Expected hashLength to default to %d, got %d", DefaultHashLength, config.HashLength)
+	}
+
+	config = &Config{HashLength: 64}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for hashLength 64: %v", err)
+	}
+
+	for _, length := range []int{-1, 65} {
+		config = &Config{HashLength: length}
+		if err := config.validate(); err == nil {
+			t.Errorf("Expected error for hashLength %d", length)
+		}
+	}
+}
+
+func TestConfigValidationMethodName(t *testing.T) {
+	config := &Config{}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if config.MethodName != DefaultMethodName {
+		t.Errorf("Expected methodName to default to %s, got %s", DefaultMethodName, config.MethodName)
+	}
+
+	config = &Config{MethodName: "SlogValue"}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for methodName SlogValue: %v", err)
+	}
+
+	for _, name := range []string{"logValue", "Log-Value", "func"} {
+		config = &Config{MethodName: name}
+		if err := config.validate(); err == nil {
+			t.Errorf("Expected error for methodName %q", name)
+		}
+	}
+
+	config = &Config{MethodName: "SlogValue", Backend: BackendZap}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for methodName with backend zap")
+	}
+
+	config = &Config{MethodName: DefaultMethodName, Backend: BackendZap}
+	if err := config.validate(); err != nil {
+		t.Errorf("Unexpected error for default methodName with backend zap: %v", err)
+	}
+}
+
+func TestIsAllowedField(t *testing.T) {
+	config := &Config{AllowKeys: []string{"ID", "*status"}}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	testCases := []struct {
+		fieldName string
+		expected  bool
+	}{
+		{"ID", true},
+		{"id", true},
+		{"OrderStatus", true},
+		{"StatusCode", false},
+		{"Email", false},
+	}
+
+	for _, tc := range testCases {
+		if result := config.IsAllowedField(tc.fieldName); result != tc.expected {
+			t.Errorf("IsAllowedField(%s): expected %v, got %v", tc.fieldName, tc.expected, result)
+		}
+	}
+
+	config = &Config{AllowKeys: []string{"[id"}}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for malformed allow key pattern")
+	}
+}
+
+func TestMatchRedactKeyWildcards(t *testing.T) {
+	config := &Config{RedactKeys: []string{"*password*", "secret*", "*token", "api*key"}}
+	if err := config.validate(); err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+
+	testCases := []struct {
+		fieldName string
+		expected  string
+	}{
+		{"Password", "*password*"},
+		{"OldPassword", "*password*"},
+		{"password2", "*password*"},
+		{"SecretValue", "secret*"},
+		{"Secret", "secret*"},
+		{"TopSecret", ""},
+		{"AccessToken", "*token"},
+		{"TokenType", ""},
+		{"APIKey", "api*key"},
+		{"APIPrivateKey", "api*key"},
+		{"KeyAPI", ""},
+	}
+
+	for _, tc := range testCases {
+		key, ok := config.MatchRedactKey(tc.fieldName)
+		if ok != (tc.expected != "") || key != tc.expected {
+			t.Errorf("MatchRedactKey(%s): expected %q, got %q (%v)", tc.fieldName, tc.expected, key, ok)
+		}
+	}
+
+	config = &Config{RedactKeys: []string{"[password"}}
+	if err := config.validate(); err == nil {
+		t.Errorf("Expected error for malformed redact key pattern")
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// exampleConfig is the commented oak.yaml written by WriteExample
+const exampleConfig = `# Oak configuration. See https://github.com/stuckinforloop/oak for all options.
+
+# Packages to scan for //go:generate oak directives. Entries may be glob
+# patterns or end in "/..." to include all packages below a directory
+packages:
+  - ./...
+
+# Field names to redact automatically (case-insensitive). Names may contain
+# * wildcards: *password* also redacts OldPassword and password2
+redactKeys:
+  - "*password*"
+  - secret
+  - token
+  - apikey
+
+# Message logged in place of redacted fields. {field} and {type} are replaced
+# with the field's name and type
+redactMessage: "[REDACTED]"
+`
+
+// WriteExample writes a commented example oak.yaml into dir and returns its
+// path. An existing oak.yaml is never overwritten.
+func WriteExample(dir string) (string, error) {
+	configPath := filepath.Join(dir, "oak.yaml")
+
+	file, err := os.OpenFile(configPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("%s already exists", configPath)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", configPath, err)
+	}
+
+	if _, err := file.WriteString(exampleConfig); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+
+	return configPath, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteExample(t *testing.T) {
+	dir := t.TempDir()
+
+	configPath, err := WriteExample(dir)
+	if err != nil {
+		t.Fatalf("WriteExample failed: %v", err)
+	}
+	if configPath != filepath.Join(dir, "oak.yaml") {
+		t.Errorf("Expected path %s, got %s", filepath.Join(dir, "oak.yaml"), configPath)
+	}
+
+	// The example is a valid configuration
+	config, err := LoadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load example config: %v", err)
+	}
+	if !reflect.DeepEqual(config.Packages, []string{"./..."}) {
+		t.Errorf("Expected packages [./...], got %v", config.Packages)
+	}
+	if !config.ShouldRedactField("OldPassword") {
+		t.Errorf("Expected OldPassword to be redacted")
+	}
+	if config.RedactMessage != "[REDACTED]" {
+		t.Errorf("Expected redact message [REDACTED], got %s", config.RedactMessage)
+	}
+
+	// An existing file is not overwritten
+	_, err = WriteExample(dir)
+	if err == nil {
+		t.Fatalf("Expected error for existing oak.yaml")
+	}
+	if !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected already exists error, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change
+const contextLines = 3
+
+// edit is a single line of an edit script: an unchanged (' '), deleted ('-'),
+// or inserted ('+') line
+type edit struct {
+	kind byte
+	line string
+}
+
+// Unified returns a unified diff turning old into new, labelling the files
+// oldName and newName, or an empty string if they are equal
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	edits := lineEdits(splitLines(old), splitLines(new))
+
+	// Line numbers in old and new before each edit
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.kind != '+' {
+			oldLine[i+1]++
+		}
+		if e.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk over changes separated by little enough context
+		start, end := max(i-contextLines, 0), i
+		for end < len(edits) {
+			if edits[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].kind == ' ' {
+				run++
+			}
+			if run == len(edits) || run-end > 2*contextLines {
+				end = min(end+contextLines, len(edits))
+				break
+			}
+			end = run
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			hunkRange(newLine[start], newLine[end]-newLine[start]))
+		for _, e := range edits[start:end] {
+			out.WriteByte(e.kind)
+			out.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return out.String()
+}
+
+// hunkRange formats the range of a hunk that starts after line before and
+// spans count lines, omitting a count of one as diff does
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// splitLines splits text into lines, each keeping its trailing newline
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEdits returns the shortest edit script turning a into b, using Myers'
+// O(ND) algorithm
+func lineEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+
+	// Record the furthest reaching x for each diagonal k before each round
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Move down: insert from b
+			} else {
+				x = v[offset+k-1] + 1 // Move right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end, collecting edits in reverse
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{'+', b[y-1]})
+				y--
+			} else {
+				edits = append(edits, edit{'-', a[x-1]})
+				x--
+			}
+		}
+	}
+
+	slices.Reverse(edits)
+	return edits
+}
@@ -0,0 +1,59 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "new file",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name:     "single line removed",
+			old:      "a\n",
+			new:      "",
+			expected: "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name:     "missing final newline",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Unified("old", "new", tc.old, tc.new)
+			if got != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stuckinforloop/oak/internal/config"
+	"github.com/stuckinforloop/oak/internal/parser"
+	"github.com/stuckinforloop/oak/internal/types"
+)
+
+// fakeEmitter logs every field as a string constant naming its action
+type fakeEmitter struct{}
+
+func (fakeEmitter) Field(analysis types.FieldAnalysis, receiverName string) string {
+	return fmt.Sprintf("slog.String(%q, %q)", analysis.Field.Name, analysis.Action)
+}
+
+func TestGenerateForStructsEmitter(t *testing.T) {
+	fake := slogBackend
+	fake.newEmitter = func(*types.TypeAnalyzer) types.Emitter { return fakeEmitter{} }
+	backends["fake"] = fake
+	defer delete(backends, "fake")
+
+	cfg := config.DefaultConfig()
+	cfg.Backend = "fake"
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Name", Type: "string"},
+				{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+				{Name: "Internal", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`slog.String("Name", "log")`,
+		`slog.String("Password", "redact")`,
+	}
+	for _, exp := range expected {
+		if !strings.Contains(result.Content, exp) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", exp, result.Content)
+		}
+	}
+	if strings.Contains(result.Content, `"Internal"`) {
+		t.Errorf("Expected skipped field to be omitted, got:\n%s", result.Content)
+	}
+}
+
+func TestGenerateForStructsUnknownBackend(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Backend = "unknown"
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields:      []parser.FieldInfo{{Name: "Name", Type: "string"}},
+		},
+	}
+
+	_, err := generator.GenerateForStructs(structs)
+	if err == nil || !strings.Contains(err.Error(), `unknown backend "unknown"`) {
+		t.Errorf("Expected unknown backend error, got %v", err)
+	}
+}
+
+func TestGenerateForStructsZap(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Backend = config.BackendZap
+	cfg.RedactKeys = []string{"password"}
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    "/tmp/models.go",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Password", Type: "string"},
+				{Name: "Internal", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expected := `import "go.uber.org/zap"
+
+// ZapFields returns the zap fields logging User
+func (u User) ZapFields() []zap.Field {
+	return []zap.Field{
+		zap.Int64("ID", int64(u.ID)),
+		zap.String("Password", "[REDACTED]"),
+	}
+}
+`
+	if !strings.HasSuffix(result.Content, expected) {
+		t.Errorf("Expected generated code to end with:\n%s\ngot:\n%s", expected, result.Content)
+	}
+	if strings.Contains(result.Content, "slog") {
+		t.Errorf("Expected no slog references in zap output, got:\n%s", result.Content)
+	}
+
+	bench, err := generator.GenerateBenchmarks(structs)
+	if err != nil {
+		t.Fatalf("GenerateBenchmarks failed: %v", err)
+	}
+	if !strings.Contains(bench.Content, "func BenchmarkUserZapFields(b *testing.B)") || !strings.Contains(bench.Content, "_ = v.ZapFields()") {
+		t.Errorf("Expected benchmark of ZapFields, got:\n%s", bench.Content)
+	}
+}
+
+func TestGenerateForStructsZerolog(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Backend = config.BackendZerolog
+	cfg.RedactKeys = []string{"password"}
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    "/tmp/models.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Age", Type: "int", Doc: "Age in years"},
+				{Name: "Name", Type: "string"},
+				{Name: "Password", Type: "string"},
+				{Name: "Internal", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expected := `import "github.com/rs/zerolog"
+
+var _ zerolog.LogObjectMarshaler = User{}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler for User
+func (u User) MarshalZerologObject(evt *zerolog.Event) {
+	evt.
+		// Age in years
+		Int64("Age", int64(u.Age)).
+		Str("Name", u.Name).
+		Str("Password", "[REDACTED]")
+}
+`
+	if !strings.HasSuffix(result.Content, expected) {
+		t.Errorf("Expected generated code to end with:\n%s\ngot:\n%s", expected, result.Content)
+	}
+
+	bench, err := generator.GenerateBenchmarks(structs)
+	if err != nil {
+		t.Fatalf("GenerateBenchmarks failed: %v", err)
+	}
+	for _, exp := range []string{`"github.com/rs/zerolog"`, "v.MarshalZerologObject(zerolog.Dict())"} {
+		if !strings.Contains(bench.Content, exp) {
+			t.Errorf("Expected benchmark to contain %q, got:\n%s", exp, bench.Content)
+		}
+	}
+}
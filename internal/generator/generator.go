@@ -2,30 +2,117 @@ package generator
 
 import (
 	"bytes"
+	"cmp"
+	"errors"
 	"fmt"
+	"go/build"
 	"go/format"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/stuckinforloop/oak/internal/config"
 	"github.com/stuckinforloop/oak/internal/parser"
 	"github.com/stuckinforloop/oak/internal/types"
+	"github.com/stuckinforloop/oak/internal/version"
 )
 
-const outputFilename = "oak_gen.go"
+const (
+	outputFilename    = "oak_gen.go"
+	benchmarkFilename = "oak_log_bench_test.go"
+
+	// Structs declared in test files are generated into test files, so they
+	// are only compiled along with the tests
+	testOutputFilename         = "oak_gen_test.go"
+	externalTestOutputFilename = "oak_gen_external_test.go"
+)
+
+// pointerReceiverThreshold is the number of fields above which the auto
+// receiver type generates pointer receivers to avoid copying the struct
+const pointerReceiverThreshold = 8
+
+// ErrNoLoggableStructs is returned when every field of every struct is
+// skipped, in which case no file should be written
+var ErrNoLoggableStructs = errors.New("no structs with loggable fields found")
+
+// backend describes the code generated for a logging library
+type backend struct {
+	newEmitter func(*types.TypeAnalyzer) types.Emitter // Emitter for field statements
+	template   string                                  // Template for the generated methods
+	imports    []string                                // Packages every generated file imports
+	method     string                                  // Name of the generated method
+
+	benchmarkCall    string   // Statement calling the method on a value v
+	benchmarkImports []string // Packages the benchmark call depends on
+}
+
+// backends maps each configured backend to the code generated for it;
+// configs without a backend use slog
+var backends = map[string]backend{
+	config.BackendSlog: slogBackend,
+	config.BackendZap: {
+		newEmitter: types.NewZapEmitter,
+		template:   zapTemplate,
+		imports:    []string{"go.uber.org/zap"},
+		method:     "ZapFields",
+
+		benchmarkCall: "_ = v.ZapFields()",
+	},
+	config.BackendZerolog: {
+		newEmitter: types.NewZerologEmitter,
+		template:   zerologTemplate,
+		imports:    []string{"github.com/rs/zerolog"},
+		method:     "MarshalZerologObject",
+
+		benchmarkCall:    "v.MarshalZerologObject(zerolog.Dict())",
+		benchmarkImports: []string{"github.com/rs/zerolog"},
+	},
+	"": slogBackend,
+}
+
+// slogBackend generates slog.LogValuer implementations
+var slogBackend = backend{
+	newEmitter: types.NewSlogEmitter,
+	template:   logValueTemplate,
+	imports:    []string{"log/slog"},
+	method:     "LogValue",
+
+	benchmarkCall: "_ = v.LogValue()",
+}
 
 // GenerationResult represents the result of code generation
 type GenerationResult struct {
 	PackageName string // Name of the package
 	FilePath    string // Path where the generated file should be written
 	Content     string // Generated Go code content
+
+	// Inline is set for code generated with inlineOutput, in which case
+	// Content holds only declarations, spliced into the source file at
+	// FilePath between sentinel comments, and Imports lists the packages
+	// they may refer to
+	Inline  bool
+	Imports []string
+
+	Structs  []StructAnalysis // Analyses of the generated structs, if any
+	Warnings []string         // Diagnostics such as structs logging more fields than maxFields
+}
+
+// StructAnalysis records how each field of a generated struct is logged
+type StructAnalysis struct {
+	Name   string
+	Fields []types.FieldAnalysis
 }
 
 // Generator handles code generation for LogValue methods
 type Generator struct {
-	config       *config.Config
-	typeAnalyzer *types.TypeAnalyzer
-	template     *template.Template
+	config            *config.Config
+	typeAnalyzer      *types.TypeAnalyzer
+	version           string                        // Oak version recorded in the generated header
+	templates         map[string]*template.Template // Templates by backend
+	benchmarkTemplate *template.Template
 }
 
 // New creates a new Generator instance
@@ -35,76 +122,394 @@ func New(cfg *config.Config) *Generator {
 	gen := &Generator{
 		config:       cfg,
 		typeAnalyzer: analyzer,
+		version:      version.Get(),
+	}
+
+	// Parse the template of each backend along with the shared templates
+	gen.templates = make(map[string]*template.Template, len(backends))
+	for name, b := range backends {
+		tmpl, err := template.New("logvalue").Funcs(gen.templateFuncs()).Parse(sharedTemplates + b.template)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to parse template: %v", err))
+		}
+		gen.templates[name] = tmpl
 	}
 
-	// Parse the template
-	tmpl, err := template.New("logvalue").Funcs(gen.templateFuncs()).Parse(logValueTemplate)
+	benchTmpl, err := template.New("benchmark").Parse(sharedTemplates + benchmarkTemplate)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to parse template: %v", err))
+		panic(fmt.Sprintf("Failed to parse benchmark template: %v", err))
 	}
-	gen.template = tmpl
+	gen.benchmarkTemplate = benchTmpl
 
 	return gen
 }
 
 // GenerateForStructs generates LogValue methods for a list of structs
 func (g *Generator) GenerateForStructs(structs []parser.StructInfo) (*GenerationResult, error) {
+	p, err := g.preparePackage(structs)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := p.gen.render(p.template, p.data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The generated file is written beside the package's source files
+	result := &GenerationResult{
+		PackageName: p.data.PackageName,
+		FilePath:    p.outputFile,
+		Content:     content,
+		Warnings:    p.warnings,
+	}
+	for _, s := range p.data.Structs {
+		result.Structs = append(result.Structs, StructAnalysis{Name: s.Name, Fields: s.analyses})
+	}
+
+	return result, nil
+}
+
+// GenerateInline generates LogValue methods for a list of structs as one
+// result per source file, to be spliced into the file the structs are
+// declared in. Package-level helpers are declared once, in the first file.
+func (g *Generator) GenerateInline(structs []parser.StructInfo) ([]*GenerationResult, error) {
+	p, err := g.preparePackage(structs)
+	if err != nil {
+		return nil, err
+	}
+
+	fileStructs := make(map[string][]StructTemplateData)
+	var files []string
+	for _, s := range p.data.Structs {
+		if _, ok := fileStructs[s.filePath]; !ok {
+			files = append(files, s.filePath)
+		}
+		fileStructs[s.filePath] = append(fileStructs[s.filePath], s)
+	}
+	sort.Strings(files)
+
+	var results []*GenerationResult
+	for i, file := range files {
+		data := p.data
+		data.Inline = true
+		data.Structs = fileStructs[file]
+		if i > 0 {
+			data.HashFunc = ""
+			data.CapSliceFunc = ""
+		}
+
+		content, err := p.gen.render(p.template, data)
+		if err != nil {
+			return nil, err
+		}
+
+		result := &GenerationResult{
+			PackageName: data.PackageName,
+			FilePath:    file,
+			Content:     content,
+			Inline:      true,
+			Imports:     data.Imports,
+		}
+		if i == 0 {
+			result.Warnings = p.warnings
+		}
+		for _, s := range data.Structs {
+			result.Structs = append(result.Structs, StructAnalysis{Name: s.Name, Fields: s.analyses})
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// preparedPackage is the template data for the generated code of a package
+type preparedPackage struct {
+	gen        *Generator // Generator configured for the package
+	template   *template.Template
+	data       TemplateData
+	outputFile string   // Generated file the package's code is written to
+	warnings   []string // Diagnostics from preparing the structs
+}
+
+// preparePackage analyzes the loggable structs of a package and prepares the
+// data its generated code is rendered from
+func (g *Generator) preparePackage(structs []parser.StructInfo) (*preparedPackage, error) {
 	if len(structs) == 0 {
 		return nil, fmt.Errorf("no structs provided for generation")
 	}
 
 	// All structs should be from the same package
 	packageName := structs[0].PackageName
+	g = g.forPackage(filepath.Dir(structs[0].FilePath))
 
-	// Filter structs that have loggable fields
-	var validStructs []StructTemplateData
-	for _, structInfo := range structs {
+	structs, err := uniqueStructs(structs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter structs that have loggable fields, in name order so output does
+	// not depend on the order files were parsed in
+	var loggable []parser.StructInfo
+	for _, structInfo := range sortedByName(structs) {
 		if g.typeAnalyzer.HasLoggableFields(structInfo) {
-			templateData := g.prepareStructData(structInfo)
-			validStructs = append(validStructs, templateData)
+			loggable = append(loggable, structInfo)
+		}
+	}
+
+	// Fields holding another generated struct are logged through its LogValue.
+	// In-package test files are compiled along with the package's generated
+	// file, so they declare a hash function of their own.
+	analyzer := g.typeAnalyzer.WithKnownStructs(loggable)
+	outputFile := outputPath(structs[0])
+	if filepath.Base(outputFile) == testOutputFilename {
+		analyzer = analyzer.WithHashFunc(types.DefaultHashFunc + "Test").
+			WithCapSliceFunc(types.DefaultCapSliceFunc + "Test")
+	}
+
+	b, err := g.backend()
+	if err != nil {
+		return nil, err
+	}
+	emitter := b.newEmitter(analyzer)
+
+	var validStructs []StructTemplateData
+	var warnings []string
+	for _, structInfo := range loggable {
+		if err := checkLogTags(structInfo, structInfo.Fields); err != nil {
+			return nil, err
+		}
+		data := g.prepareStructData(analyzer, emitter, structInfo)
+		if warning := g.checkMaxFields(structInfo, data); warning != "" {
+			warnings = append(warnings, warning)
 		}
+		validStructs = append(validStructs, data)
 	}
 
 	if len(validStructs) == 0 {
-		return nil, fmt.Errorf("no structs with loggable fields found")
+		return nil, ErrNoLoggableStructs
 	}
 
 	// Prepare template data
 	data := TemplateData{
+		Version:     g.version,
 		PackageName: packageName,
+		Imports:     collectImports(b.imports, validStructs),
+		Method:      b.method,
 		Structs:     validStructs,
 	}
+	if b.template == logValueTemplate && g.config.LogValueStyle != config.LogValueStyleClosure {
+		data.AttrSlice = types.SlogAttrs
+	}
 
-	// Generate code
-	var buf bytes.Buffer
-	if err := g.template.Execute(&buf, data); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
+	// The import path of the package is only known with the packages loader
+	if err := checkImportCycle(structs[0], data.Imports); err != nil {
+		return nil, err
+	}
+	for _, s := range validStructs {
+		if types.UsesHash(s.analyses) {
+			data.HashFunc = analyzer.HashFunc()
+			data.HashSalt = g.config.HashSalt
+			data.HashLength = cmp.Or(g.config.HashLength, config.DefaultHashLength)
+		}
+		if types.UsesCapSlice(s.analyses) {
+			data.CapSliceFunc = analyzer.CapSliceFunc()
+			data.MaxSliceLen = g.config.MaxSliceLen
+		}
 	}
 
-	// Format the generated code
-	formatted, err := format.Source(buf.Bytes())
+	return &preparedPackage{
+		gen:        g,
+		template:   g.templates[g.config.Backend],
+		data:       data,
+		outputFile: outputFile,
+		warnings:   warnings,
+	}, nil
+}
+
+// GenerateBenchmarks generates a test file with a benchmark for the LogValue
+// method of each struct that has loggable fields
+func (g *Generator) GenerateBenchmarks(structs []parser.StructInfo) (*GenerationResult, error) {
+	if len(structs) == 0 {
+		return nil, fmt.Errorf("no structs provided for generation")
+	}
+
+	packageName := structs[0].PackageName
+	g = g.forPackage(filepath.Dir(structs[0].FilePath))
+
+	b, err := g.backend()
 	if err != nil {
-		return nil, fmt.Errorf("failed to format generated code: %w", err)
+		return nil, err
+	}
+
+	structs, err = uniqueStructs(structs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generic structs are left out, since benchmarks cannot know which type
+	// arguments to instantiate them with
+	var validStructs []StructTemplateData
+	for _, structInfo := range sortedByName(structs) {
+		if len(structInfo.TypeParams) == 0 && g.typeAnalyzer.HasLoggableFields(structInfo) {
+			validStructs = append(validStructs, StructTemplateData{Name: structInfo.Name})
+		}
+	}
+
+	if len(validStructs) == 0 {
+		return nil, ErrNoLoggableStructs
+	}
+
+	data := TemplateData{
+		Version:     g.version,
+		PackageName: packageName,
+		Imports:     append([]string{"testing"}, b.benchmarkImports...),
+		Method:      b.method,
+		Call:        b.benchmarkCall,
+		Structs:     validStructs,
+	}
+
+	content, err := g.render(g.benchmarkTemplate, data)
+	if err != nil {
+		return nil, err
 	}
 
-	// Determine output file path
 	result := &GenerationResult{
 		PackageName: packageName,
-		FilePath:    outputFilename,
-		Content:     string(formatted),
+		FilePath:    filepath.Join(filepath.Dir(structs[0].FilePath), benchmarkFilename),
+		Content:     content,
 	}
 
 	return result, nil
 }
 
+// checkMaxFields returns a diagnostic pointing at a struct that logs more
+// fields than maxFields allows, or "" when it is within the limit
+func (g *Generator) checkMaxFields(structInfo parser.StructInfo, data StructTemplateData) string {
+	if g.config.MaxFields == 0 || len(data.Fields) <= g.config.MaxFields {
+		return ""
+	}
+	return fmt.Sprintf("%s: struct %s logs %d fields, more than maxFields (%d); consider splitting it",
+		structInfo.FilePath, structInfo.Name, len(data.Fields), g.config.MaxFields)
+}
+
+// outputPath returns the path of the file generated for a struct, beside its
+// source file
+func outputPath(structInfo parser.StructInfo) string {
+	dir := filepath.Dir(structInfo.FilePath)
+	switch {
+	case !parser.IsTestFile(structInfo.FilePath):
+		return filepath.Join(dir, outputFilename)
+	case strings.HasSuffix(structInfo.PackageName, "_test"):
+		return filepath.Join(dir, externalTestOutputFilename)
+	default:
+		return filepath.Join(dir, testOutputFilename)
+	}
+}
+
+// backend returns the code generated for the configured logging library, with
+// the slog method named as configured
+func (g *Generator) backend() (backend, error) {
+	b, ok := backends[g.config.Backend]
+	if !ok {
+		return backend{}, fmt.Errorf("unknown backend %q", g.config.Backend)
+	}
+	if b.template == logValueTemplate && g.config.MethodName != "" {
+		b.method = g.config.MethodName
+		b.benchmarkCall = "_ = v." + b.method + "()"
+	}
+	return b, nil
+}
+
+// forPackage returns a generator using the configuration resolved for the
+// package directory, or g itself when no override applies
+func (g *Generator) forPackage(dir string) *Generator {
+	cfg := g.config.ForPackage(dir)
+	if cfg == g.config {
+		return g
+	}
+
+	pkg := *g
+	pkg.config = cfg
+	pkg.typeAnalyzer = types.NewTypeAnalyzer(cfg)
+	return &pkg
+}
+
+// uniqueStructs returns structs without repeats of a struct parsed more than
+// once from the same file, as when overlapping paths are processed. Structs
+// of the same name declared in different files would generate duplicate
+// methods, so they are reported as an error naming both files.
+func uniqueStructs(structs []parser.StructInfo) ([]parser.StructInfo, error) {
+	declared := make(map[string]parser.StructInfo, len(structs))
+	var unique []parser.StructInfo
+
+	for _, s := range structs {
+		if previous, ok := declared[s.Name]; ok {
+			if sameFile(previous.FilePath, s.FilePath) {
+				continue
+			}
+			files := []string{previous.FilePath, s.FilePath}
+			sort.Strings(files)
+			return nil, fmt.Errorf("struct %s is declared in both %s and %s", s.Name, files[0], files[1])
+		}
+		declared[s.Name] = s
+		unique = append(unique, s)
+	}
+
+	return unique, nil
+}
+
+// sameFile reports whether two paths, either of which may be relative, name
+// the same file
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// sortedByName returns a copy of structs sorted by name. Fields keep their
+// source order.
+func sortedByName(structs []parser.StructInfo) []parser.StructInfo {
+	sorted := slices.Clone(structs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// render executes a template and formats the resulting Go code
+func (g *Generator) render(tmpl *template.Template, data TemplateData) (string, error) {
+	// Generate code
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	if !g.config.Format {
+		return buf.String(), nil
+	}
+
+	// Format the generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+
+	return string(formatted), nil
+}
+
 // prepareStructData prepares template data for a single struct
-func (g *Generator) prepareStructData(structInfo parser.StructInfo) StructTemplateData {
-	analyses := g.typeAnalyzer.AnalyzeStruct(structInfo)
+func (g *Generator) prepareStructData(analyzer *types.TypeAnalyzer, emitter types.Emitter, structInfo parser.StructInfo) StructTemplateData {
+	analyses := analyzer.AnalyzeStruct(structInfo)
 
 	// Generate receiver name (first letter of struct name, lowercase)
 	receiverName := strings.ToLower(string(structInfo.Name[0]))
 
 	var fields []FieldTemplateData
+	var imports []string
 	for _, analysis := range analyses {
 		if analysis.Action == types.ActionSkip {
 			continue // Skip fields marked with log:"-"
@@ -112,56 +517,365 @@ func (g *Generator) prepareStructData(structInfo parser.StructInfo) StructTempla
 
 		fieldData := FieldTemplateData{
 			Name:         analysis.Field.Name,
-			LogStatement: g.typeAnalyzer.GenerateLogStatement(analysis, receiverName),
+			Doc:          analysis.Field.Doc,
+			LogStatement: emitter.Field(analysis, receiverName),
 		}
 		fields = append(fields, fieldData)
+		imports = append(imports, analysis.Imports...)
+	}
+
+	if g.config.LogValueStyle == config.LogValueStyleCompact && g.config.Backend == config.BackendSlog {
+		compactAppends(fields)
+	}
+
+	// The context policy is only called from the slog LogValueCtx method
+	var contextPolicy string
+	if g.config.ContextPolicy != "" && g.config.Backend == config.BackendSlog {
+		var importPath string
+		contextPolicy, importPath = types.QualifiedFunction(g.config.ContextPolicy)
+		imports = append(imports, "context")
+		if importPath != "" {
+			imports = append(imports, importPath)
+		}
+	}
+
+	var redactStatements []string
+	if g.config.GenerateRedacted {
+		redactStatements = analyzer.GenerateRedactStatements(structInfo, receiverName)
+	}
+
+	typeName := structInfo.Name
+	if len(structInfo.TypeParams) > 0 {
+		typeName += "[" + strings.Join(structInfo.TypeParams, ", ") + "]"
 	}
 
 	return StructTemplateData{
-		Name:         structInfo.Name,
-		ReceiverName: receiverName,
-		Fields:       fields,
+		Name:             structInfo.Name,
+		TypeName:         typeName,
+		Generic:          len(structInfo.TypeParams) > 0,
+		ReceiverName:     receiverName,
+		PointerReceiver:  g.usePointerReceiver(structInfo),
+		Recursive:        analyzer.IsRecursive(structInfo.Name),
+		Fields:           fields,
+		Imports:          imports,
+		ContextPolicy:    contextPolicy,
+		Redacted:         g.config.GenerateRedacted,
+		RedactStatements: redactStatements,
+		analyses:         analyses,
+		filePath:         structInfo.FilePath,
+	}
+}
+
+// compactAppends marks the runs of adjacent fields appended unconditionally,
+// replacing their statements with the appended attributes, so that the
+// compact style appends each run with a single call
+func compactAppends(fields []FieldTemplateData) {
+	for start := 0; start < len(fields); {
+		end := start
+		for end < len(fields) {
+			if _, ok := types.AppendedAttr(fields[end].LogStatement); !ok {
+				break
+			}
+			end++
+		}
+
+		if end-start > 1 {
+			for i := start; i < end; i++ {
+				fields[i].LogStatement, _ = types.AppendedAttr(fields[i].LogStatement)
+				fields[i].Appended = true
+			}
+			fields[start].RunStart = true
+			fields[end-1].RunEnd = true
+		}
+		start = max(end, start+1)
+	}
+}
+
+// usePointerReceiver reports whether the LogValue method of a struct takes a
+// pointer receiver according to the configured receiver type
+func (g *Generator) usePointerReceiver(structInfo parser.StructInfo) bool {
+	switch g.config.ReceiverType {
+	case config.ReceiverPointer:
+		return true
+	case config.ReceiverAuto:
+		return len(structInfo.Fields) > pointerReceiverThreshold
+	default:
+		return false
+	}
+}
+
+// collectImports aggregates the imports required by all structs into a
+// deduplicated, sorted list that always includes the backend's imports
+func collectImports(base []string, structs []StructTemplateData) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, imp := range base {
+		seen[imp] = true
+		imports = append(imports, imp)
+	}
+
+	for _, s := range structs {
+		for _, imp := range s.Imports {
+			if !seen[imp] {
+				seen[imp] = true
+				imports = append(imports, imp)
+			}
+		}
+	}
+
+	sort.Strings(imports)
+	return imports
+}
+
+// checkImportCycle reports an error when a package imported by the generated
+// code, such as one holding custom formatters, is the struct's package or
+// imports it. Only imports outside the standard library are checked, and only
+// when the import path of the struct's package is known.
+func checkImportCycle(structInfo parser.StructInfo, imports []string) error {
+	if structInfo.PackagePath == "" {
+		return nil
+	}
+
+	var external []string
+	for _, imp := range imports {
+		if imp == structInfo.PackagePath {
+			return fmt.Errorf("import cycle: generated code for %s would import the package itself", structInfo.PackagePath)
+		}
+		if !isStandardPackage(imp) {
+			external = append(external, imp)
+		}
+	}
+	if len(external) == 0 {
+		return nil
+	}
+
+	chain, err := parser.ImportChain(filepath.Dir(structInfo.FilePath), external, structInfo.PackagePath)
+	if err != nil {
+		return fmt.Errorf("failed to check imports of %s: %w", structInfo.PackagePath, err)
+	}
+	if chain != nil {
+		return fmt.Errorf("import cycle: generated code for %s would import %s", structInfo.PackagePath, strings.Join(chain, " -> "))
+	}
+	return nil
+}
+
+// isStandardPackage reports whether an import path names a package of the
+// standard library, found under GOROOT, which cannot import the struct's
+// package. Module paths need not contain a dot, so myapp/logfmt is not one.
+func isStandardPackage(path string) bool {
+	if build.Default.GOROOT == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(build.Default.GOROOT, "src", path))
+	return err == nil && info.IsDir()
+}
+
+// checkLogTags returns an error for a field of a struct, including the fields
+// of inline structs, whose log tag has options that would not take effect
+func checkLogTags(structInfo parser.StructInfo, fields []parser.FieldInfo) error {
+	for _, field := range fields {
+		if err := types.ValidateLogOptions(field.LogOptions); err != nil {
+			return fmt.Errorf("field %s of %s: %w", field.Name, structInfo.Name, err)
+		}
+		if err := checkLogTags(structInfo, field.Fields); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // templateFuncs returns template functions for use in the template
 func (g *Generator) templateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"join": strings.Join,
+		"join":  strings.Join,
+		"lines": docLines,
+	}
+}
+
+// docLines splits a doc comment into its non-empty lines
+func docLines(doc string) []string {
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
 	}
+	return lines
 }
 
 // TemplateData represents data passed to the template
 type TemplateData struct {
+	Version     string // Oak version for the generated header
+	Inline      bool   // Whether to leave out the header, for code spliced into a source file
 	PackageName string
+	Imports     []string // Sorted, deduplicated import paths
+	Method      string   // Name of the generated logging method
+	Call        string   // Statement calling the method in benchmarks
+	AttrSlice   string   // Slice slog LogValue methods append to, if any
 	Structs     []StructTemplateData
+
+	HashFunc   string // Generated function hashing fields, if any field is hashed
+	HashSalt   string // Salt prepended to hashed values
+	HashLength int    // Number of hex characters of hashes logged
+
+	CapSliceFunc string // Generated function capping slices, if any slice is capped
+	MaxSliceLen  int    // Number of slice elements logged before the marker
 }
 
 // StructTemplateData represents data for a single struct
 type StructTemplateData struct {
-	Name         string
-	ReceiverName string
-	Fields       []FieldTemplateData
+	Name            string
+	TypeName        string // Receiver type, with the type parameters of generic structs, e.g. Box[T]
+	Generic         bool   // Whether the struct has type parameters, so no value can be asserted to implement the interface
+	ReceiverName    string
+	PointerReceiver bool // Whether LogValue takes a pointer receiver
+	Recursive       bool // Whether the struct holds itself, so logging tracks the nesting depth
+	Fields          []FieldTemplateData
+	Imports         []string // Imports required by the struct's fields
+
+	ContextPolicy    string   // Policy called by LogValueCtx, if generated
+	Redacted         bool     // Whether to generate a Redacted() method
+	RedactStatements []string // Assignments blanking sensitive fields
+
+	analyses []types.FieldAnalysis // Analyses the struct was generated from
+	filePath string                // Source file declaring the struct
 }
 
 // FieldTemplateData represents data for a single field
 type FieldTemplateData struct {
 	Name         string
+	Doc          string
 	LogStatement string
+
+	Appended bool // Whether LogStatement is an attribute appended along with adjacent fields
+	RunStart bool // Whether the field starts a run of appended fields
+	RunEnd   bool // Whether the field ends a run of appended fields
 }
 
-// logValueTemplate is the Go template for generating LogValue methods
-const logValueTemplate = `// Code generated by oak. DO NOT EDIT.
+// sharedTemplates defines the file header and Redacted method shared by the
+// templates of all backends
+const sharedTemplates = `{{define "header"}}{{if not .Inline}}// Code generated by oak {{.Version}}; DO NOT EDIT.
+
 package {{.PackageName}}
 
-import "log/slog"
+{{if eq (len .Imports) 1}}import "{{index .Imports 0}}"{{else}}import (
+	{{range .Imports}}"{{.}}"
+	{{end}}
+){{end}}
+{{end}}{{end}}{{define "redacted"}}{{if .Redacted}}
+// Redacted returns a copy of {{.Name}} with sensitive fields redacted
+func ({{.ReceiverName}} {{.TypeName}}) Redacted() {{.TypeName}} {
+	{{range .RedactStatements}}{{.}}
+	{{end}}return {{.ReceiverName}}
+}
+{{end}}{{end}}{{define "hash"}}{{if .HashFunc}}
+// {{.HashFunc}} returns the first {{.HashLength}} hex characters of the SHA-256 hash of
+// the {{if .HashSalt}}salted {{end}}value, logged in place of fields tagged log:"hash"
+func {{.HashFunc}}(value string) string {
+	sum := sha256.Sum256([]byte({{if .HashSalt}}{{printf "%q" .HashSalt}} + {{end}}value))
+	return hex.EncodeToString(sum[:])[:{{.HashLength}}]
+}
+{{end}}{{end}}{{define "capSlice"}}{{if .CapSliceFunc}}
+// {{.CapSliceFunc}} returns s, or when it holds more than {{.MaxSliceLen}} elements its first
+// {{.MaxSliceLen}} followed by a marker counting the rest, for slices capped by maxSliceLen
+func {{.CapSliceFunc}}[T any](s []T) any {
+	if len(s) <= {{.MaxSliceLen}} {
+		return s
+	}
+	capped := make([]any, 0, {{.MaxSliceLen}}+1)
+	for _, v := range s[:{{.MaxSliceLen}}] {
+		capped = append(capped, v)
+	}
+	return append(capped, fmt.Sprintf("...(%d more)", len(s)-{{.MaxSliceLen}}))
+}
+{{end}}{{end}}`
+
+// logValueTemplate is the Go template for generating LogValue methods
+const logValueTemplate = `{{template "header" .}}
+{{range .Structs}}{{if eq $.Method "LogValue"}}{{if not .Generic}}
+var _ slog.LogValuer = {{if .PointerReceiver}}(*{{.Name}})(nil){{else}}{{.Name}}{}{{end}}
+{{end}}
+// LogValue implements slog.LogValuer for {{.Name}}{{else}}
+// {{$.Method}} returns the slog.Value logging {{.Name}}{{end}}
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) {{$.Method}}() slog.Value {
+	{{if .Recursive}}return {{.ReceiverName}}.logValue(0)
+}
+
+// logValue logs {{.Name}} nested depth levels deep within itself
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) logValue(depth int) slog.Value {
+	{{end}}{{if .PointerReceiver}}if {{.ReceiverName}} == nil {
+		return slog.StringValue("null")
+	}
+	{{end}}{{if $.AttrSlice}}{{$.AttrSlice}} := make([]slog.Attr, 0, {{len .Fields}})
+	{{range .Fields}}{{if .RunStart}}{{$.AttrSlice}} = append({{$.AttrSlice}},
+	{{end}}{{range lines .Doc}}// {{.}}
+	{{end}}{{.LogStatement}}{{if .Appended}},{{end}}
+	{{if .RunEnd}})
+	{{end}}{{end}}return slog.GroupValue({{$.AttrSlice}}...){{else}}return slog.GroupValue(
+		{{range .Fields}}{{range lines .Doc}}// {{.}}
+		{{end}}{{.LogStatement}},
+		{{end}}
+	){{end}}
+}
+{{if .ContextPolicy}}
+// {{$.Method}}Ctx returns the {{$.Method}} of {{.Name}} as adjusted by the context policy
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) {{$.Method}}Ctx(ctx context.Context) slog.Value {
+	return {{.ContextPolicy}}(ctx, {{.ReceiverName}}.{{$.Method}}())
+}
+{{end}}{{template "redacted" .}}{{end}}{{template "hash" .}}{{template "capSlice" .}}`
 
+// zapTemplate is the Go template for generating ZapFields methods
+const zapTemplate = `{{template "header" .}}
 {{range .Structs}}
-// LogValue implements slog.LogValuer for {{.Name}}
-func ({{.ReceiverName}} {{.Name}}) LogValue() slog.Value {
-	return slog.GroupValue(
-		{{range $i, $field := .Fields}}{{if $i}},
-		{{end}}{{$field.LogStatement}}{{end}},
-	)
+// ZapFields returns the zap fields logging {{.Name}}
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) ZapFields() []zap.Field {
+	{{if .Recursive}}return {{.ReceiverName}}.zapFields(0)
+}
+
+// zapFields logs {{.Name}} nested depth levels deep within itself
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) zapFields(depth int) []zap.Field {
+	{{end}}{{if .PointerReceiver}}if {{.ReceiverName}} == nil {
+		return nil
+	}
+	{{end}}return []zap.Field{
+		{{range .Fields}}{{range lines .Doc}}// {{.}}
+		{{end}}{{.LogStatement}},
+		{{end}}
+	}
+}
+{{template "redacted" .}}{{end}}{{template "hash" .}}{{template "capSlice" .}}`
+
+// zerologTemplate is the Go template for generating zerolog.LogObjectMarshaler
+// implementations, logging fields through a single event chain
+const zerologTemplate = `{{template "header" .}}
+{{range .Structs}}{{if not .Generic}}
+var _ zerolog.LogObjectMarshaler = {{if .PointerReceiver}}(*{{.Name}})(nil){{else}}{{.Name}}{}{{end}}
+{{end}}
+// MarshalZerologObject implements zerolog.LogObjectMarshaler for {{.Name}}
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) MarshalZerologObject(evt *zerolog.Event) {
+	{{if .Recursive}}{{.ReceiverName}}.marshalZerologObject(evt, 0)
+}
+
+// marshalZerologObject logs {{.Name}} nested depth levels deep within itself
+func ({{.ReceiverName}} {{if .PointerReceiver}}*{{end}}{{.TypeName}}) marshalZerologObject(evt *zerolog.Event, depth int) {
+	{{end}}{{if .PointerReceiver}}if {{.ReceiverName}} == nil {
+		return
+	}
+	{{end}}evt.{{range $i, $field := .Fields}}{{if $i}}.{{end}}
+		{{range lines .Doc}}// {{.}}
+		{{end}}{{.LogStatement}}{{end}}
+}
+{{template "redacted" .}}{{end}}{{template "hash" .}}{{template "capSlice" .}}`
+
+// benchmarkTemplate is the Go template for generating LogValue benchmarks
+const benchmarkTemplate = `{{template "header" .}}
+{{range .Structs}}
+// Benchmark{{.Name}}{{$.Method}} measures the cost of logging a zero {{.Name}}
+func Benchmark{{.Name}}{{$.Method}}(b *testing.B) {
+	var v {{.Name}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		{{$.Call}}
+	}
 }
 {{end}}`
@@ -1,11 +1,28 @@
 package generator
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/stuckinforloop/oak/internal/config"
 	"github.com/stuckinforloop/oak/internal/parser"
+	"github.com/stuckinforloop/oak/internal/version"
 )
 
 func TestGenerateForStructs(t *testing.T) {
@@ -24,7 +41,7 @@ func TestGenerateForStructs(t *testing.T) {
 				{Name: "ID", Type: "int"},
 				{Name: "Username", Type: "string"},
 				{Name: "Password", Type: "string"},
-				{Name: "Notes", Type: "string", LogTag: "-"},
+				{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
 			},
 		},
 	}
@@ -42,9 +59,14 @@ func TestGenerateForStructs(t *testing.T) {
 		t.Errorf("Expected file path to end with 'main_logvalue.go', got %s", result.FilePath)
 	}
 
+	// The generated file is written beside the struct's source file
+	if result.FilePath != "/tmp/oak_gen.go" {
+		t.Errorf("Expected file path '/tmp/oak_gen.go', got %s", result.FilePath)
+	}
+
 	// Check that the generated code contains expected elements
 	expectedElements := []string{
-		"// Code generated by oak. DO NOT EDIT.",
+		"// Code generated by oak " + version.Get() + "; DO NOT EDIT.",
 		"package main",
 		"import \"log/slog\"",
 		"func (u User) LogValue() slog.Value",
@@ -66,6 +88,46 @@ func TestGenerateForStructs(t *testing.T) {
 	}
 }
 
+func TestGeneratedHeader(t *testing.T) {
+	generator := New(&config.Config{RedactMessage: "[REDACTED]"})
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields:      []parser.FieldInfo{{Name: "ID", Type: "int"}},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	bench, err := generator.GenerateBenchmarks(structs)
+	if err != nil {
+		t.Fatalf("GenerateBenchmarks failed: %v", err)
+	}
+
+	// The header follows the convention recognized by go vet and linters
+	header := regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+	expected := "// Code generated by oak " + version.Get() + "; DO NOT EDIT."
+
+	for _, content := range []string{result.Content, bench.Content} {
+		lines := strings.Split(content, "\n")
+		if !header.MatchString(lines[0]) {
+			t.Errorf("Header %q does not match %s", lines[0], header)
+		}
+		if lines[0] != expected {
+			t.Errorf("Expected header %q, got %q", expected, lines[0])
+		}
+
+		// A blank line keeps the header from becoming the package doc comment
+		if lines[1] != "" || !strings.HasPrefix(lines[2], "package ") {
+			t.Errorf("Expected blank line and package clause after header, got %q", lines[1:3])
+		}
+	}
+}
+
 func TestGenerateForStructsWithMultipleStructs(t *testing.T) {
 	cfg := config.DefaultConfig()
 	generator := New(cfg)
@@ -122,6 +184,113 @@ func TestGenerateForStructsWithMultipleStructs(t *testing.T) {
 	}
 }
 
+func TestGenerateForStructsWithFieldDocs(t *testing.T) {
+	cfg := config.DefaultConfig()
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int", Doc: "ID is the primary key\nassigned by the database"},
+				{Name: "Name", Type: "string"},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expected := "// ID is the primary key\n\t// assigned by the database\n\tattrs = append(attrs, slog.Int64(\"ID\", int64(u.ID)))"
+	if !strings.Contains(result.Content, expected) {
+		t.Errorf("Generated code missing field doc comment, got:\n%s", result.Content)
+	}
+}
+
+func TestGenerateForStructsWithByteArray(t *testing.T) {
+	cfg := config.DefaultConfig()
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Session",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "[16]byte"},
+				{Name: "Parent", Type: "*[16]byte", IsPointer: true},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expectedElements := []string{
+		"\"encoding/hex\"",
+		"\"log/slog\"",
+		"slog.String(\"ID\", hex.EncodeToString(s.ID[:]))",
+		"attrs = append(attrs, slog.String(\"Parent\", hex.EncodeToString(s.Parent[:])))",
+	}
+
+	for _, expected := range expectedElements {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+}
+
+func TestGenerateForStructsImports(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TimeFormat = "RFC3339"
+	generator := New(cfg)
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Event",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields: []parser.FieldInfo{
+				{Name: "At", Type: "time.Time"},
+				{Name: "Payload", Type: "[]byte"},
+				{Name: "Signature", Type: "[]byte"},
+			},
+		},
+		{
+			Name:        "Audit",
+			PackageName: "main",
+			FilePath:    "/tmp/main.go",
+			Fields: []parser.FieldInfo{
+				{Name: "UpdatedAt", Type: "*time.Time", IsPointer: true},
+				{Name: "Body", Type: "[]byte"},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expectedImports := []string{"\"encoding/base64\"", "\"log/slog\"", "\"time\""}
+	for _, expected := range expectedImports {
+		if count := strings.Count(result.Content, expected); count != 1 {
+			t.Errorf("Expected import %s exactly once, found %d times", expected, count)
+		}
+	}
+
+	importBlock := "import (\n\t\"encoding/base64\"\n\t\"log/slog\"\n\t\"time\"\n)"
+	if !strings.Contains(result.Content, importBlock) {
+		t.Errorf("Expected sorted import block, got:\n%s", result.Content)
+	}
+}
+
 func TestGenerateForStructsNoLoggableFields(t *testing.T) {
 	cfg := config.DefaultConfig()
 	generator := New(cfg)
@@ -132,20 +301,23 @@ func TestGenerateForStructsNoLoggableFields(t *testing.T) {
 			PackageName: "main",
 			FilePath:    "/tmp/main.go",
 			Fields: []parser.FieldInfo{
-				{Name: "Field1", Type: "string", LogTag: "-"},
-				{Name: "Field2", Type: "int", LogTag: "-"},
+				{Name: "Field1", Type: "string", LogOptions: parser.ParseLogTag("-")},
+				{Name: "Field2", Type: "int", LogOptions: parser.ParseLogTag("-")},
 			},
 		},
 	}
 
-	_, err := generator.GenerateForStructs(structs)
-	if err == nil {
-		t.Errorf("Expected error for struct with no loggable fields")
+	result, err := generator.GenerateForStructs(structs)
+	if !errors.Is(err, ErrNoLoggableStructs) {
+		t.Errorf("Expected ErrNoLoggableStructs, got %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected no result for struct with no loggable fields")
 	}
 
-	expectedError := "no structs with loggable fields found"
-	if !strings.Contains(err.Error(), expectedError) {
-		t.Errorf("Expected error to contain %q, got %q", expectedError, err.Error())
+	// Benchmarks are skipped the same way
+	if _, err := generator.GenerateBenchmarks(structs); !errors.Is(err, ErrNoLoggableStructs) {
+		t.Errorf("Expected ErrNoLoggableStructs from GenerateBenchmarks, got %v", err)
 	}
 }
 
@@ -177,11 +349,11 @@ func TestPrepareStructData(t *testing.T) {
 			{Name: "ID", Type: "int"},
 			{Name: "Name", Type: "string"},
 			{Name: "Secret", Type: "string"},
-			{Name: "Notes", Type: "string", LogTag: "-"},
+			{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
 		},
 	}
 
-	result := generator.prepareStructData(structInfo)
+	result := generator.prepareStructData(generator.typeAnalyzer, slogBackend.newEmitter(generator.typeAnalyzer), structInfo)
 
 	if result.Name != "TestStruct" {
 		t.Errorf("Expected struct name 'TestStruct', got %s", result.Name)
@@ -237,10 +409,1886 @@ func TestReceiverNameGeneration(t *testing.T) {
 			},
 		}
 
-		result := generator.prepareStructData(structInfo)
+		result := generator.prepareStructData(generator.typeAnalyzer, slogBackend.newEmitter(generator.typeAnalyzer), structInfo)
 		if result.ReceiverName != tc.expectedName {
 			t.Errorf("Struct %s: expected receiver name %s, got %s",
 				tc.structName, tc.expectedName, result.ReceiverName)
 		}
 	}
 }
+
+func TestGenerateBenchmarks(t *testing.T) {
+	cfg := config.DefaultConfig()
+	generator := New(cfg)
+
+	source := `package models
+
+type User struct {
+	ID    int
+	Name  string
+	Email *string
+}
+
+type Hidden struct {
+	Secret string
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Name", Type: "string"},
+				{Name: "Email", Type: "*string", IsPointer: true},
+			},
+		},
+		{
+			Name:        "Hidden",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	bench, err := generator.GenerateBenchmarks(structs)
+	if err != nil {
+		t.Fatalf("GenerateBenchmarks failed: %v", err)
+	}
+
+	if !strings.HasSuffix(bench.FilePath, "_log_bench_test.go") {
+		t.Errorf("Expected benchmark file path to end with '_log_bench_test.go', got %s", bench.FilePath)
+	}
+
+	expectedElements := []string{
+		"// Code generated by oak " + version.Get() + "; DO NOT EDIT.",
+		"package models",
+		"import \"testing\"",
+		"func BenchmarkUserLogValue(b *testing.B)",
+		"_ = v.LogValue()",
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(bench.Content, expected) {
+			t.Errorf("Benchmark code missing expected element: %s", expected)
+		}
+	}
+
+	// Structs without loggable fields have no LogValue method to benchmark
+	if strings.Contains(bench.Content, "BenchmarkHiddenLogValue") {
+		t.Errorf("Benchmark code should not include structs without loggable fields")
+	}
+
+	typeCheck(t, map[string]string{
+		"models.go":            source,
+		result.FilePath:        result.Content,
+		"models_bench_test.go": bench.Content,
+	})
+}
+
+func TestGenerateForStructsRedacted(t *testing.T) {
+	cfg := &config.Config{
+		RedactKeys:       []string{"password", "pin"},
+		RedactMessage:    "[REDACTED]",
+		GenerateRedacted: true,
+	}
+	generator := New(cfg)
+
+	source := `package models
+
+type User struct {
+	Name     string
+	Password string
+	PIN      int
+	Recovery *string
+	Notes    string
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Name", Type: "string"},
+				{Name: "Password", Type: "string"},
+				{Name: "PIN", Type: "int"},
+				{Name: "Recovery", Type: "*string", IsPointer: true, LogOptions: parser.ParseLogTag("redact")},
+				{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expectedElements := []string{
+		"func (u User) Redacted() User {",
+		"u.Password = \"[REDACTED]\"",
+		"u.PIN = 0",
+		"u.Recovery = &redacted",
+		"return u",
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+
+	if strings.Contains(result.Content, "u.Name =") || strings.Contains(result.Content, "u.Notes =") {
+		t.Errorf("Redacted() should only touch sensitive fields, got:\n%s", result.Content)
+	}
+
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+
+	// The method is only generated when enabled
+	cfg.GenerateRedacted = false
+	result, err = New(cfg).GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if strings.Contains(result.Content, "Redacted()") {
+		t.Errorf("Redacted() should not be generated unless enabled")
+	}
+}
+
+func TestGenerateForStructsOutputStyle(t *testing.T) {
+	source := `package models
+
+type Example struct {
+	ID     int
+	Nested NestedExample
+	Parent *NestedExample
+}
+
+type NestedExample struct {
+	BoolValue bool
+	Inner     Inner
+}
+
+type Inner struct {
+	Count int
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Example",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Nested", Type: "NestedExample"},
+				{Name: "Parent", Type: "*NestedExample", IsPointer: true},
+			},
+		},
+		{
+			Name:        "NestedExample",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "BoolValue", Type: "bool"},
+				{Name: "Inner", Type: "Inner"},
+			},
+		},
+		{
+			Name:        "Inner",
+			PackageName: "models",
+			Fields:      []parser.FieldInfo{{Name: "Count", Type: "int"}},
+		},
+	}
+
+	testCases := []struct {
+		style    string
+		expected []string
+	}{
+		{
+			style: config.OutputStyleGrouped,
+			expected: []string{
+				`slog.Attr{Key: "Nested", Value: e.Nested.LogValue()}`,
+				`slog.Attr{Key: "Inner", Value: n.Inner.LogValue()}`,
+				`slog.Attr{Key: "Parent", Value: e.Parent.LogValue()}`,
+			},
+		},
+		{
+			style: config.OutputStyleFlattened,
+			expected: []string{
+				`slog.Bool("Nested.BoolValue", e.Nested.BoolValue)`,
+				`slog.Int64("Nested.Inner.Count", int64(e.Nested.Inner.Count))`,
+				`slog.Int64("Inner.Count", int64(n.Inner.Count))`,
+				`slog.Int64("Parent.Inner.Count", int64(e.Parent.Inner.Count))`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputStyle = tc.style
+
+			result, err := New(cfg).GenerateForStructs(structs)
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("Generated code missing expected element: %s\n%s", expected, result.Content)
+				}
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+}
+
+func TestGenerateForStructsCustomFormatters(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CustomFormatters = map[string]string{
+		"net.IP": "log/slog.Any",
+		"Money":  "moneyAttr",
+		"Cents":  "github.com/acme/logfmt.Cents",
+	}
+	generator := New(cfg)
+
+	source := `package models
+
+import (
+	"log/slog"
+	"net"
+)
+
+type Money int64
+
+func moneyAttr(key string, m Money) slog.Attr {
+	return slog.Float64(key, float64(m)/100)
+}
+
+type Server struct {
+	Addr  net.IP
+	Price *Money
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Server",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Addr", Type: "net.IP"},
+				{Name: "Price", Type: "*Money", IsPointer: true},
+			},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	for _, expected := range []string{`slog.Any("Addr", s.Addr)`, `attrs = append(attrs, moneyAttr("Price", *s.Price))`} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+
+	// The formatter's package is imported alongside log/slog
+	structs[0].Fields = append(structs[0].Fields, parser.FieldInfo{Name: "Fee", Type: "Cents"})
+	result, err = generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	for _, expected := range []string{`"github.com/acme/logfmt"`, `logfmt.Cents("Fee", s.Fee)`} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing expected element: %s", expected)
+		}
+	}
+}
+
+func TestGenerateForStructsMapOfStructs(t *testing.T) {
+	generator := New(config.DefaultConfig())
+
+	source := `package models
+
+type Order struct {
+	ID int
+}
+
+type Customer struct {
+	Orders map[string]Order
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Order",
+			PackageName: "models",
+			Fields:      []parser.FieldInfo{{Name: "ID", Type: "int"}},
+		},
+		{
+			Name:        "Customer",
+			PackageName: "models",
+			Fields:      []parser.FieldInfo{{Name: "Orders", Type: "map[string]Order"}},
+		},
+	}
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	if !strings.Contains(result.Content, "for k, v := range c.Orders {") {
+		t.Errorf("Expected per-entry attributes for Orders, got:\n%s", result.Content)
+	}
+
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+}
+
+func TestGenerateForStructsIsIdempotent(t *testing.T) {
+	generator := New(config.DefaultConfig())
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    "models/user.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Name", Type: "string"},
+				{Name: "ID", Type: "int"},
+			},
+		},
+		{
+			Name:        "Account",
+			PackageName: "models",
+			FilePath:    "models/account.go",
+			Fields:      []parser.FieldInfo{{Name: "Balance", Type: "float64"}},
+		},
+	}
+
+	first, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	second, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if first.Content != second.Content {
+		t.Errorf("Repeated generation differs:\nfirst:\n%s\nsecond:\n%s", first.Content, second.Content)
+	}
+
+	// Structs parsed in a different order produce the same bytes
+	reversed, err := generator.GenerateForStructs([]parser.StructInfo{structs[1], structs[0]})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if first.Content != reversed.Content {
+		t.Errorf("Generation depends on struct order:\nfirst:\n%s\nreversed:\n%s", first.Content, reversed.Content)
+	}
+
+	// Structs are ordered by name and fields keep their source order
+	account := strings.Index(first.Content, "func (a Account)")
+	user := strings.Index(first.Content, "func (u User)")
+	if account < 0 || user < 0 || account > user {
+		t.Errorf("Expected Account before User, got:\n%s", first.Content)
+	}
+	if strings.Index(first.Content, `"Name"`) > strings.Index(first.Content, `"ID"`) {
+		t.Errorf("Expected fields in source order, got:\n%s", first.Content)
+	}
+}
+
+func TestGenerateForStructsOverrides(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	cfg.Overrides = map[string]config.Override{
+		"/src/payments": {RedactKeys: []string{"card"}, RedactMessage: "[PCI]"},
+	}
+	generator := New(cfg)
+
+	fields := []parser.FieldInfo{
+		{Name: "Password", Type: "string"},
+		{Name: "Card", Type: "string"},
+	}
+	payments := []parser.StructInfo{{Name: "Payment", PackageName: "payments", FilePath: "/src/payments/payment.go", Fields: fields}}
+	users := []parser.StructInfo{{Name: "User", PackageName: "users", FilePath: "/src/users/user.go", Fields: fields}}
+
+	result, err := generator.GenerateForStructs(payments)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	for _, expected := range []string{`slog.String("Password", "[PCI]")`, `slog.String("Card", "[PCI]")`} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code for overridden package missing %s", expected)
+		}
+	}
+
+	// Other packages use the global configuration
+	result, err = generator.GenerateForStructs(users)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	for _, expected := range []string{`slog.String("Password", "[REDACTED]")`, `slog.String("Card", u.Card)`} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code for other package missing %s", expected)
+		}
+	}
+}
+
+func TestGenerateForStructsReceiverType(t *testing.T) {
+	small := parser.StructInfo{
+		Name:        "Small",
+		PackageName: "models",
+		Fields:      []parser.FieldInfo{{Name: "ID", Type: "int"}},
+	}
+	large := parser.StructInfo{Name: "Large", PackageName: "models"}
+	for i := 0; i <= pointerReceiverThreshold; i++ {
+		large.Fields = append(large.Fields, parser.FieldInfo{Name: fmt.Sprintf("F%d", i), Type: "int"})
+	}
+
+	var source strings.Builder
+	source.WriteString("package models\n\ntype Small struct {\n\tID int\n}\n\ntype Large struct {\n")
+	for _, field := range large.Fields {
+		fmt.Fprintf(&source, "\t%s int\n", field.Name)
+	}
+	source.WriteString("}\n")
+
+	value := map[string]string{
+		"Small": "var _ slog.LogValuer = Small{}\n\n// LogValue implements slog.LogValuer for Small\nfunc (s Small) LogValue() slog.Value {",
+		"Large": "var _ slog.LogValuer = Large{}\n\n// LogValue implements slog.LogValuer for Large\nfunc (l Large) LogValue() slog.Value {",
+	}
+	pointer := map[string]string{
+		"Small": "var _ slog.LogValuer = (*Small)(nil)\n\n// LogValue implements slog.LogValuer for Small\nfunc (s *Small) LogValue() slog.Value {\n\tif s == nil {",
+		"Large": "var _ slog.LogValuer = (*Large)(nil)\n\n// LogValue implements slog.LogValuer for Large\nfunc (l *Large) LogValue() slog.Value {\n\tif l == nil {",
+	}
+
+	testCases := []struct {
+		receiverType string
+		expected     map[string]string
+	}{
+		{config.ReceiverValue, value},
+		{config.ReceiverPointer, pointer},
+		// Only structs above the field threshold get pointer receivers
+		{config.ReceiverAuto, map[string]string{"Small": value["Small"], "Large": pointer["Large"]}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.receiverType, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.ReceiverType = tc.receiverType
+			generator := New(cfg)
+
+			result, err := generator.GenerateForStructs([]parser.StructInfo{small, large})
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			for name, expected := range tc.expected {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("%s: generated code missing:\n%s\ngot:\n%s", name, expected, result.Content)
+				}
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source.String(),
+				result.FilePath: result.Content,
+			})
+		})
+	}
+}
+
+func TestGenerateForStructsGeneric(t *testing.T) {
+	source := `package models
+
+type Box[T any] struct {
+	Value T
+	Ptr   *T
+	Label string
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K
+	Inner Box[V]
+}
+`
+
+	structs := []parser.StructInfo{
+		{
+			Name:        "Box",
+			TypeParams:  []string{"T"},
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Value", Type: "T"},
+				{Name: "Ptr", Type: "*T", IsPointer: true},
+				{Name: "Label", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+			},
+		},
+		{
+			Name:        "Pair",
+			TypeParams:  []string{"K", "V"},
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Key", Type: "K"},
+				{Name: "Inner", Type: "Box[V]"},
+			},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GenerateRedacted = true
+	generator := New(cfg)
+
+	result, err := generator.GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expectedElements := []string{
+		"func (b Box[T]) LogValue() slog.Value {",
+		`slog.Any("Value", b.Value)`,
+		"func (b Box[T]) Redacted() Box[T] {",
+		"func (p Pair[K, V]) LogValue() slog.Value {",
+		`slog.Any("Inner", p.Inner)`,
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing expected element: %s\ngot:\n%s", expected, result.Content)
+		}
+	}
+
+	// Generic types cannot be asserted to implement slog.LogValuer without
+	// type arguments
+	if strings.Contains(result.Content, "var _ slog.LogValuer") {
+		t.Errorf("Generated code should not assert generic types implement slog.LogValuer:\n%s", result.Content)
+	}
+
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+
+	// Benchmarks cannot instantiate generic types, so none are generated
+	if _, err := generator.GenerateBenchmarks(structs); !errors.Is(err, ErrNoLoggableStructs) {
+		t.Errorf("GenerateBenchmarks: expected %v, got %v", ErrNoLoggableStructs, err)
+	}
+}
+
+func TestGenerateForStructsMaxFields(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    filepath.Join("models", "user.go"),
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Name", Type: "string"},
+				{Name: "Email", Type: "string"},
+				{Name: "Internal", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+	}
+
+	testCases := []struct {
+		maxFields int
+		expected  []string
+	}{
+		{0, nil},
+		// Skipped fields do not count towards the limit
+		{3, nil},
+		{2, []string{filepath.Join("models", "user.go") + ": struct User logs 3 fields, more than maxFields (2); consider splitting it"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprint(tc.maxFields), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.MaxFields = tc.maxFields
+			result, err := New(cfg).GenerateForStructs(structs)
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+			if !reflect.DeepEqual(result.Warnings, tc.expected) {
+				t.Errorf("Warnings: expected %q, got %q", tc.expected, result.Warnings)
+			}
+		})
+	}
+}
+
+// typeCheck parses and type-checks the given files as a single package,
+// failing the test if the code does not compile
+func typeCheck(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for name, src := range files {
+		file, err := goparser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+		parsed = append(parsed, file)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("test", fset, parsed, nil); err != nil {
+		t.Fatalf("Generated code does not compile: %v", err)
+	}
+}
+
+// runGenerated writes the given files, which may only import the standard
+// library, into a main package of a new module and returns the output of
+// running it
+func runGenerated(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module test\n\ngo 1.24\n"
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to run generated code: %v\n%s", err, output)
+	}
+	return string(output)
+}
+
+func TestGenerateForStructsEmbeddedShadowing(t *testing.T) {
+	source := `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+type Node struct {
+	Val int
+	ID  string
+}
+
+type Meta struct {
+	ID   string
+	Kind string
+}
+
+type Tree struct {
+	Val string
+	*Node
+	Meta
+}
+
+func main() {
+	tree := Tree{Val: "outer", Node: &Node{Val: 1, ID: "node"}, Meta: Meta{ID: "meta", Kind: "leaf"}}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key != "tree" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("", "tree", tree)
+	encoded, _ := json.Marshal(tree)
+	fmt.Printf("{\"tree\":%s}\n", encoded)
+}
+`
+	structs := []parser.StructInfo{
+		{
+			Name:        "Node",
+			PackageName: "main",
+			Fields: []parser.FieldInfo{
+				{Name: "Val", Type: "int"},
+				{Name: "ID", Type: "string"},
+			},
+		},
+		{
+			Name:        "Meta",
+			PackageName: "main",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "string"},
+				{Name: "Kind", Type: "string"},
+			},
+		},
+		{
+			Name:        "Tree",
+			PackageName: "main",
+			Fields: []parser.FieldInfo{
+				{Name: "Val", Type: "string"},
+				{Name: "Node", Type: "*Node", IsPointer: true, Embedded: true},
+				{Name: "Meta", Type: "Meta", Embedded: true},
+			},
+		},
+	}
+
+	result, err := New(config.DefaultConfig()).GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	// Promoted fields are shadowed and ambiguous ones dropped as encoding/json
+	// does, so each key is logged once
+	output := runGenerated(t, map[string]string{"main.go": source, result.FilePath: result.Content})
+	logged, encoded, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	if expected := `{"tree":{"Val":"outer","Kind":"leaf"}}`; logged != expected {
+		t.Errorf("Expected %s, got %s", expected, logged)
+	}
+	if logged != encoded {
+		t.Errorf("Expected the log to match encoding/json %s, got %s", encoded, logged)
+	}
+}
+
+func TestGenerateForStructsFieldOrder(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    "/tmp/models.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Name", Type: "string"},
+				{Name: "Age", Type: "int"},
+				{Name: "Email", Type: "string"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		fieldOrder string
+		expected   string
+	}{
+		{config.FieldOrderSource, `
+	attrs = append(attrs, slog.String("Name", u.Name))
+	attrs = append(attrs, slog.Int64("Age", int64(u.Age)))
+	attrs = append(attrs, slog.String("Email", u.Email))
+`},
+		{config.FieldOrderAlphabetical, `
+	attrs = append(attrs, slog.Int64("Age", int64(u.Age)))
+	attrs = append(attrs, slog.String("Email", u.Email))
+	attrs = append(attrs, slog.String("Name", u.Name))
+`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fieldOrder, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.FieldOrder = tc.fieldOrder
+
+			result, err := New(cfg).GenerateForStructs(structs)
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+			if !strings.Contains(result.Content, tc.expected) {
+				t.Errorf("Expected fields in order:%s\ngot:\n%s", tc.expected, result.Content)
+			}
+		})
+	}
+}
+
+func TestGenerateForStructsTestFiles(t *testing.T) {
+	testCases := []struct {
+		filePath    string
+		packageName string
+		expected    string
+	}{
+		{"/tmp/models/user.go", "models", "/tmp/models/oak_gen.go"},
+		{"/tmp/models/user_test.go", "models", "/tmp/models/oak_gen_test.go"},
+		{"/tmp/models/user_test.go", "models_test", "/tmp/models/oak_gen_external_test.go"},
+	}
+
+	generator := New(config.DefaultConfig())
+	for _, tc := range testCases {
+		structs := []parser.StructInfo{
+			{
+				Name:        "User",
+				PackageName: tc.packageName,
+				FilePath:    tc.filePath,
+				Fields:      []parser.FieldInfo{{Name: "Name", Type: "string"}},
+			},
+		}
+
+		result, err := generator.GenerateForStructs(structs)
+		if err != nil {
+			t.Fatalf("GenerateForStructs failed: %v", err)
+		}
+		if result.FilePath != tc.expected {
+			t.Errorf("%s (%s): expected output %s, got %s", tc.filePath, tc.packageName, tc.expected, result.FilePath)
+		}
+	}
+}
+
+func TestGenerateInline(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			FilePath:    "/tmp/models/user.go",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("hash")},
+			},
+		},
+		{
+			Name:        "Account",
+			PackageName: "models",
+			FilePath:    "/tmp/models/account.go",
+			Fields:      []parser.FieldInfo{{Name: "Owner", Type: "User"}},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.InlineOutput = true
+	results, err := New(cfg).GenerateInline(structs)
+	if err != nil {
+		t.Fatalf("GenerateInline failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	// Results are in file order, each holding the structs of its file
+	expected := []struct {
+		filePath string
+		structs  string
+	}{
+		{"/tmp/models/account.go", "Account"},
+		{"/tmp/models/user.go", "User"},
+	}
+	for i, result := range results {
+		if result.FilePath != expected[i].filePath {
+			t.Errorf("Result %d: expected path %s, got %s", i, expected[i].filePath, result.FilePath)
+		}
+		if len(result.Structs) != 1 || result.Structs[0].Name != expected[i].structs {
+			t.Errorf("Result %d: expected struct %s, got %v", i, expected[i].structs, result.Structs)
+		}
+		if !result.Inline {
+			t.Errorf("Result %d: expected an inline result", i)
+		}
+		if !reflect.DeepEqual(result.Imports, []string{"crypto/sha256", "encoding/hex", "log/slog"}) {
+			t.Errorf("Result %d: expected package imports, got %v", i, result.Imports)
+		}
+
+		// Inline code has no header, since it is spliced into source files
+		if strings.Contains(result.Content, "package models") || strings.Contains(result.Content, "import") {
+			t.Errorf("Result %d: expected no header, got:\n%s", i, result.Content)
+		}
+	}
+
+	// The hash function is declared once, in the first file, even though
+	// only the second file uses it
+	if !strings.Contains(results[0].Content, "func oakHash(value string) string") {
+		t.Errorf("Expected hash function in first file, got:\n%s", results[0].Content)
+	}
+	if strings.Contains(results[1].Content, "func oakHash") {
+		t.Errorf("Expected no hash function in second file, got:\n%s", results[1].Content)
+	}
+	if !strings.Contains(results[1].Content, `slog.String("Email", oakHash(u.Email))`) {
+		t.Errorf("Expected hashed field in second file, got:\n%s", results[1].Content)
+	}
+}
+
+func TestGenerateForStructsDuplicateNames(t *testing.T) {
+	generator := New(config.DefaultConfig())
+	user := func(filePath string) parser.StructInfo {
+		return parser.StructInfo{
+			Name:        "Config",
+			PackageName: "models",
+			FilePath:    filePath,
+			Fields:      []parser.FieldInfo{{Name: "Name", Type: "string"}},
+		}
+	}
+
+	// The same struct parsed twice is generated once
+	result, err := generator.GenerateForStructs([]parser.StructInfo{user("/tmp/models/a.go"), user("/tmp/models/a.go")})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if count := strings.Count(result.Content, "func (c Config) LogValue()"); count != 1 {
+		t.Errorf("Expected 1 LogValue method, got %d", count)
+	}
+
+	// Structs of the same name in different files are an error naming both
+	_, err = generator.GenerateForStructs([]parser.StructInfo{user("/tmp/models/b.go"), user("/tmp/models/a.go")})
+	expected := "struct Config is declared in both /tmp/models/a.go and /tmp/models/b.go"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+	if _, err := generator.GenerateBenchmarks([]parser.StructInfo{user("/tmp/models/b.go"), user("/tmp/models/a.go")}); err == nil {
+		t.Errorf("Expected error from GenerateBenchmarks for duplicate structs")
+	}
+}
+
+func TestGenerateForStructsContextPolicy(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "Name", Type: "string"},
+			{Name: "Password", Type: "string"},
+		},
+	}
+	source := "package models\n\ntype User struct {\n\tName     string\n\tPassword string\n}\n"
+	policy := "package models\n\nimport (\n\t\"context\"\n\t\"log/slog\"\n)\n\nfunc policy(ctx context.Context, v slog.Value) slog.Value {\n\treturn v\n}\n"
+
+	// Without a policy no LogValueCtx method is generated
+	result, err := New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if strings.Contains(result.Content, "LogValueCtx") || strings.Contains(result.Content, `"context"`) {
+		t.Errorf("Expected no LogValueCtx method without a policy, got:\n%s", result.Content)
+	}
+
+	testCases := []struct {
+		receiverType string
+		expected     string
+	}{
+		{config.ReceiverValue, "func (u User) LogValueCtx(ctx context.Context) slog.Value {\n\treturn policy(ctx, u.LogValue())\n}"},
+		{config.ReceiverPointer, "func (u *User) LogValueCtx(ctx context.Context) slog.Value {\n\treturn policy(ctx, u.LogValue())\n}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.receiverType, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.ContextPolicy = "policy"
+			cfg.ReceiverType = tc.receiverType
+
+			result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+			if !strings.Contains(result.Content, tc.expected) {
+				t.Errorf("Generated code missing:\n%s\ngot:\n%s", tc.expected, result.Content)
+			}
+			if !strings.Contains(result.Content, "\t\"context\"\n") {
+				t.Errorf("Expected context import, got:\n%s", result.Content)
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				"policy.go":     policy,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+
+	// Policies in other packages are imported
+	cfg := config.DefaultConfig()
+	cfg.ContextPolicy = "github.com/acme/logpolicy.Apply"
+	result, err = New(cfg).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	for _, expected := range []string{"\t\"github.com/acme/logpolicy\"\n", "return logpolicy.Apply(ctx, u.LogValue())"} {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+		}
+	}
+}
+
+func TestGenerateForStructsRecursive(t *testing.T) {
+	node := parser.StructInfo{
+		Name:        "Node",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "Name", Type: "string"},
+			{Name: "Next", Type: "*Node", IsPointer: true},
+			{Name: "Children", Type: "map[string]*Node"},
+		},
+	}
+	source := "package models\n\ntype Node struct {\n\tName     string\n\tNext     *Node\n\tChildren map[string]*Node\n}\n"
+
+	for _, outputStyle := range []string{config.OutputStyleGrouped, config.OutputStyleFlattened} {
+		t.Run(outputStyle, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputStyle = outputStyle
+			result, err := New(cfg).GenerateForStructs([]parser.StructInfo{node})
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			// LogValue starts at depth 0 and nested nodes stop at the limit
+			for _, expected := range []string{
+				"func (n Node) LogValue() slog.Value {\n\treturn n.logValue(0)\n}",
+				"func (n Node) logValue(depth int) slog.Value {",
+				"if depth >= 5 {",
+				`Value: n.Next.logValue(depth + 1)`,
+				`Value: v.logValue(depth + 1)`,
+			} {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+				}
+			}
+			if strings.Contains(result.Content, "n.Next.LogValue()") {
+				t.Errorf("Expected no unbounded LogValue call, got:\n%s", result.Content)
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+}
+
+func TestGenerateForStructsLogValueStyle(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "Name", Type: "string"},
+			{Name: "Email", Type: "*string", IsPointer: true},
+			{Name: "Bio", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+		},
+	}
+	source := "package models\n\ntype User struct {\n\tName  string\n\tEmail *string\n\tBio   string\n}\n"
+
+	testCases := []struct {
+		style      string
+		expected   []string
+		unexpected string
+	}{
+		{config.LogValueStyleSlice, []string{
+			"attrs := make([]slog.Attr, 0, 3)",
+			"attrs = append(attrs, slog.String(\"Name\", u.Name))",
+			"if u.Email == nil {\n\t\tattrs = append(attrs, slog.String(\"Email\", \"null\"))\n\t} else {\n\t\tattrs = append(attrs, slog.String(\"Email\", *u.Email))\n\t}",
+			"if u.Bio != \"\" {\n\t\tattrs = append(attrs, slog.String(\"Bio\", u.Bio))\n\t}",
+			"return slog.GroupValue(attrs...)",
+		}, "func() slog.Attr"},
+		{config.LogValueStyleClosure, []string{
+			"return slog.GroupValue(\n\t\tslog.String(\"Name\", u.Name),",
+			"func() slog.Attr {",
+		}, "attrs"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.LogValueStyle = tc.style
+			result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+				}
+			}
+			if strings.Contains(result.Content, tc.unexpected) {
+				t.Errorf("Generated code should not contain %q, got:\n%s", tc.unexpected, result.Content)
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+}
+
+func TestGenerateForStructsLogValueStyleCompact(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", Type: "int"},
+			{Name: "Name", Type: "string"},
+			{Name: "Email", Type: "*string", IsPointer: true},
+			{Name: "Bio", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+			{Name: "Active", Type: "bool"},
+		},
+	}
+	source := "package models\n\ntype User struct {\n\tID     int\n\tName   string\n\tEmail  *string\n\tBio    string\n\tActive bool\n}\n"
+
+	generate := func(style string) string {
+		cfg := config.DefaultConfig()
+		cfg.LogValueStyle = style
+		result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+		if err != nil {
+			t.Fatalf("GenerateForStructs failed: %v", err)
+		}
+		typeCheck(t, map[string]string{
+			"models.go":     source,
+			result.FilePath: result.Content,
+		})
+		return result.Content
+	}
+	slice := generate(config.LogValueStyleSlice)
+	compact := generate(config.LogValueStyleCompact)
+
+	expected := "attrs = append(attrs,\n\t\tslog.Int64(\"ID\", int64(u.ID)),\n\t\tslog.String(\"Name\", u.Name),\n\t)"
+	if !strings.Contains(compact, expected) {
+		t.Errorf("Generated code missing %q, got:\n%s", expected, compact)
+	}
+
+	// Every attribute appended by the slice style is appended by the
+	// compact style as well, single fields keeping their own statement
+	for _, line := range strings.Split(slice, "\n") {
+		attr, ok := strings.CutPrefix(strings.TrimSpace(line), "attrs = append(attrs, ")
+		if !ok {
+			continue
+		}
+		attr = strings.TrimSuffix(attr, ")")
+		if !strings.Contains(compact, attr) {
+			t.Errorf("compact: expected attribute %q, got:\n%s", attr, compact)
+		}
+	}
+	if !strings.Contains(compact, "attrs = append(attrs, slog.Bool(\"Active\", u.Active))") {
+		t.Errorf("compact: expected single append for Active, got:\n%s", compact)
+	}
+}
+
+// benchUser is logged below by copies of the LogValue bodies generated for it
+// with each logValueStyle, which TestLogValueStyleBenchmarkBodies keeps in sync
+type benchUser struct {
+	ID      int
+	Name    string
+	Email   *string
+	Manager *string
+	Bio     string `log:"omitzero"`
+}
+
+// logValueClosure is the LogValue body generated with logValueStyle: closure
+func (b benchUser) logValueClosure() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("ID", int64(b.ID)),
+		slog.String("Name", b.Name),
+		func() slog.Attr {
+			if b.Email == nil {
+				return slog.String("Email", "null")
+			}
+			return slog.String("Email", *b.Email)
+		}(),
+		func() slog.Attr {
+			if b.Manager == nil {
+				return slog.String("Manager", "null")
+			}
+			return slog.String("Manager", *b.Manager)
+		}(),
+		func() slog.Attr {
+			if b.Bio == "" {
+				return slog.Attr{}
+			}
+			return slog.String("Bio", b.Bio)
+		}(),
+	)
+}
+
+// logValueSlice is the LogValue body generated with logValueStyle: slice
+func (b benchUser) logValueSlice() slog.Value {
+	attrs := make([]slog.Attr, 0, 5)
+	attrs = append(attrs, slog.Int64("ID", int64(b.ID)))
+	attrs = append(attrs, slog.String("Name", b.Name))
+	if b.Email == nil {
+		attrs = append(attrs, slog.String("Email", "null"))
+	} else {
+		attrs = append(attrs, slog.String("Email", *b.Email))
+	}
+	if b.Manager == nil {
+		attrs = append(attrs, slog.String("Manager", "null"))
+	} else {
+		attrs = append(attrs, slog.String("Manager", *b.Manager))
+	}
+	if b.Bio != "" {
+		attrs = append(attrs, slog.String("Bio", b.Bio))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func benchmarkLogValueStyle(b *testing.B, logValue func(benchUser) slog.Value) {
+	email := "ada@example.com"
+	user := benchUser{ID: 1, Name: "Ada", Email: &email}
+	handler := slog.NewJSONHandler(io.Discard, nil)
+	logger := slog.New(handler)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		logger.Info("user", "user", logValue(user))
+	}
+}
+
+func BenchmarkLogValueStyleClosure(b *testing.B) {
+	benchmarkLogValueStyle(b, benchUser.logValueClosure)
+}
+
+func BenchmarkLogValueStyleSlice(b *testing.B) {
+	benchmarkLogValueStyle(b, benchUser.logValueSlice)
+}
+
+func TestLogValueStyleBenchmarkBodies(t *testing.T) {
+	source, err := os.ReadFile("generator_test.go")
+	if err != nil {
+		t.Fatalf("Failed to read test source: %v", err)
+	}
+	structs := []parser.StructInfo{
+		{
+			Name:        "benchUser",
+			PackageName: "generator",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Name", Type: "string"},
+				{Name: "Email", Type: "*string", IsPointer: true},
+				{Name: "Manager", Type: "*string", IsPointer: true},
+				{Name: "Bio", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+			},
+		},
+	}
+
+	// The benchmarked copies must be what the generator emits for benchUser
+	for style, method := range map[string]string{
+		config.LogValueStyleClosure: "logValueClosure",
+		config.LogValueStyleSlice:   "logValueSlice",
+	} {
+		cfg := config.DefaultConfig()
+		cfg.LogValueStyle = style
+		result, err := New(cfg).GenerateForStructs(structs)
+		if err != nil {
+			t.Fatalf("%s: GenerateForStructs failed: %v", style, err)
+		}
+
+		generated := functionBody(result.Content, "func (b benchUser) LogValue() slog.Value {")
+		copied := functionBody(string(source), "func (b benchUser) "+method+"() slog.Value {")
+		if generated == "" || generated != copied {
+			t.Errorf("%s: expected %s to match the generated body:\n%s\ngot:\n%s", style, method, generated, copied)
+		}
+	}
+}
+
+// functionBody returns the body of the gofmt-formatted function declared by
+// header in src, or an empty string if there is none
+func functionBody(src, header string) string {
+	_, body, ok := strings.Cut(src, header+"\n")
+	if !ok {
+		return ""
+	}
+	body, _, _ = strings.Cut(body, "\n}\n")
+	return body
+}
+
+func TestGenerateForStructsHash(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", Type: "int", LogOptions: parser.ParseLogTag("hash")},
+			{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("hash")},
+			{Name: "Name", Type: "string"},
+		},
+	}
+	source := "package models\n\ntype User struct {\n\tID    int\n\tEmail string\n\tName  string\n}\n"
+
+	testCases := []struct {
+		name     string
+		salt     string
+		length   int
+		expected []string
+	}{
+		{"unsalted", "", 0, []string{
+			`slog.String("Email", oakHash(u.Email))`,
+			"func oakHash(value string) string {\n\tsum := sha256.Sum256([]byte(value))\n\treturn hex.EncodeToString(sum[:])[:16]\n}",
+		}},
+		{"salted", "pepper", 8, []string{
+			"sum := sha256.Sum256([]byte(\"pepper\" + value))\n\treturn hex.EncodeToString(sum[:])[:8]",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.HashSalt = tc.salt
+			cfg.HashLength = tc.length
+			result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+				}
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+
+	// Files generated for in-package tests compile along with the package's
+	// generated file, so they declare a hash function of their own
+	user.FilePath = "/tmp/models/user.go"
+	result, err := New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	testUser := user
+	testUser.Name = "TestUser"
+	testUser.FilePath = "/tmp/models/user_test.go"
+	testResult, err := New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{testUser})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if !strings.Contains(testResult.Content, "func oakHashTest(value string) string {") {
+		t.Errorf("Generated test code missing oakHashTest, got:\n%s", testResult.Content)
+	}
+	typeCheck(t, map[string]string{
+		"models.go":       source + "\ntype TestUser User\n",
+		"oak_gen.go":      result.Content,
+		"oak_gen_test.go": testResult.Content,
+	})
+
+	// Structs without hashed fields need no hash function
+	result, err = New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{{
+		Name:        "Order",
+		PackageName: "models",
+		Fields:      []parser.FieldInfo{{Name: "ID", Type: "int"}},
+	}})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if strings.Contains(result.Content, "oakHash") {
+		t.Errorf("Generated code should not contain a hash function, got:\n%s", result.Content)
+	}
+}
+
+// sqlNullUser is logged below by a hand-written copy of the LogValue method
+// generated for its sql.NullString field
+type sqlNullUser struct {
+	Nickname sql.NullString
+}
+
+func (u sqlNullUser) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 1)
+	if !u.Nickname.Valid {
+		attrs = append(attrs, slog.String("Nickname", "null"))
+	} else {
+		attrs = append(attrs, slog.String("Nickname", u.Nickname.String))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func TestGenerateForStructsSQLNull(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "Nickname", Type: "sql.NullString"},
+			{Name: "DeletedAt", Type: "*sql.NullTime", IsPointer: true},
+		},
+	}
+	source := "package models\n\nimport \"database/sql\"\n\ntype User struct {\n\tNickname  sql.NullString\n\tDeletedAt *sql.NullTime\n}\n"
+
+	result, err := New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	expectedElements := []string{
+		"if !u.Nickname.Valid {\n\t\tattrs = append(attrs, slog.String(\"Nickname\", \"null\"))\n\t} else {\n\t\tattrs = append(attrs, slog.String(\"Nickname\", u.Nickname.String))\n\t}",
+		"} else if !u.DeletedAt.Valid {",
+		`slog.Time("DeletedAt", u.DeletedAt.Time)`,
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+		}
+	}
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+
+	// Valid values log the string they hold, and invalid ones null
+	testCases := []struct {
+		nickname sql.NullString
+		expected string
+	}{
+		{sql.NullString{String: "ada", Valid: true}, `"Nickname":"ada"`},
+		{sql.NullString{String: "", Valid: true}, `"Nickname":""`},
+		{sql.NullString{String: "stale", Valid: false}, `"Nickname":"null"`},
+	}
+	for _, tc := range testCases {
+		var buf strings.Builder
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("user", "user", sqlNullUser{Nickname: tc.nickname})
+		if !strings.Contains(buf.String(), tc.expected) {
+			t.Errorf("%+v: expected %s, got %s", tc.nickname, tc.expected, buf.String())
+		}
+	}
+}
+
+// capSlice3 is the slice capping function generated with maxSliceLen: 3
+func capSlice3[T any](s []T) any {
+	if len(s) <= 3 {
+		return s
+	}
+	capped := make([]any, 0, 3+1)
+	for _, v := range s[:3] {
+		capped = append(capped, v)
+	}
+	return append(capped, fmt.Sprintf("...(%d more)", len(s)-3))
+}
+
+func TestGenerateForStructsMaxSliceLen(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "Tags", Type: "[]string"},
+			{Name: "Scores", Type: "*[]int", IsPointer: true},
+			{Name: "Avatar", Type: "[]byte"},
+		},
+	}
+	source := "package models\n\ntype User struct {\n\tTags   []string\n\tScores *[]int\n\tAvatar []byte\n}\n"
+
+	cfg := config.DefaultConfig()
+	cfg.MaxSliceLen = 3
+	result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+
+	// Byte slices are logged base64-encoded rather than capped
+	expectedElements := []string{
+		`slog.Any("Tags", oakCapSlice(u.Tags))`,
+		`slog.Any("Scores", oakCapSlice(*u.Scores))`,
+		`slog.String("Avatar", base64.StdEncoding.EncodeToString(u.Avatar))`,
+		`"fmt"`,
+		"func oakCapSlice[T any](s []T) any {\n\tif len(s) <= 3 {\n\t\treturn s\n\t}\n\tcapped := make([]any, 0, 3+1)\n\tfor _, v := range s[:3] {",
+		`return append(capped, fmt.Sprintf("...(%d more)", len(s)-3))`,
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(result.Content, expected) {
+			t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+		}
+	}
+	typeCheck(t, map[string]string{
+		"models.go":     source,
+		result.FilePath: result.Content,
+	})
+
+	// Slices up to the cap are logged whole; longer ones log their first
+	// elements and a marker counting the rest
+	testCases := []struct {
+		tags     []string
+		expected string
+	}{
+		{nil, `"tags":null`},
+		{[]string{"a", "b"}, `"tags":["a","b"]`},
+		{[]string{"a", "b", "c"}, `"tags":["a","b","c"]`},
+		{[]string{"a", "b", "c", "d", "e"}, `"tags":["a","b","c","...(2 more)"]`},
+	}
+	for _, tc := range testCases {
+		var buf strings.Builder
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("user", slog.Any("tags", capSlice3(tc.tags)))
+		if !strings.Contains(buf.String(), tc.expected) {
+			t.Errorf("%d tags: expected %s, got %s", len(tc.tags), tc.expected, buf.String())
+		}
+	}
+
+	// Without a cap, slices are logged as is and no function is generated
+	result, err = New(config.DefaultConfig()).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if strings.Contains(result.Content, "oakCapSlice") {
+		t.Errorf("Generated code should not contain a slice capping function, got:\n%s", result.Content)
+	}
+
+	// Files generated for in-package tests declare a function of their own
+	user.FilePath = "/tmp/models/user_test.go"
+	result, err = New(cfg).GenerateForStructs([]parser.StructInfo{user})
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	if !strings.Contains(result.Content, "func oakCapSliceTest[T any](s []T) any {") {
+		t.Errorf("Generated test code missing oakCapSliceTest, got:\n%s", result.Content)
+	}
+}
+
+func TestGenerateForStructsFormat(t *testing.T) {
+	user := parser.StructInfo{
+		Name:        "User",
+		PackageName: "models",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", Type: "int"},
+			{Name: "Email", Type: "*string", IsPointer: true},
+		},
+	}
+
+	generate := func(enabled bool) string {
+		cfg := config.DefaultConfig()
+		cfg.Format = enabled
+		result, err := New(cfg).GenerateForStructs([]parser.StructInfo{user})
+		if err != nil {
+			t.Fatalf("GenerateForStructs failed: %v", err)
+		}
+		return result.Content
+	}
+	formatted := generate(true)
+	raw := generate(false)
+
+	if gofmt, err := format.Source([]byte(formatted)); err != nil || string(gofmt) != formatted {
+		t.Errorf("Expected formatted output to be gofmt-clean, got:\n%s", formatted)
+	}
+	if raw == formatted {
+		t.Error("Expected raw output to differ from formatted output")
+	}
+
+	// The raw output is the same code before formatting
+	gofmt, err := format.Source([]byte(raw))
+	if err != nil {
+		t.Fatalf("Raw output does not parse: %v", err)
+	}
+	if string(gofmt) != formatted {
+		t.Errorf("Expected formatted raw output:\n%s\ngot:\n%s", formatted, gofmt)
+	}
+}
+
+func TestGenerateForStructsImportCycle(t *testing.T) {
+	// The logfmt package, holding a custom formatter, imports models
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/shop\n\ngo 1.21\n",
+		"models/models.go": "package models\n\ntype Money int64\n\ntype Order struct {\n\tPrice Money\n}\n",
+		"logfmt/logfmt.go": `package logfmt
+
+import (
+	"log/slog"
+
+	"example.com/shop/models"
+)
+
+func Money(key string, m models.Money) slog.Attr {
+	return slog.Int64(key, int64(m))
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	order := parser.StructInfo{
+		Name:        "Order",
+		PackageName: "models",
+		PackagePath: "example.com/shop/models",
+		FilePath:    filepath.Join(dir, "models", "models.go"),
+		Fields:      []parser.FieldInfo{{Name: "Price", Type: "Money"}},
+	}
+
+	testCases := []struct {
+		formatter string
+		expected  string
+	}{
+		{"example.com/shop/logfmt.Money", "import cycle: generated code for example.com/shop/models would import example.com/shop/logfmt -> example.com/shop/models"},
+		{"example.com/shop/models.MoneyAttr", "import cycle: generated code for example.com/shop/models would import the package itself"},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.DefaultConfig()
+		cfg.CustomFormatters = map[string]string{"Money": tc.formatter}
+
+		_, err := New(cfg).GenerateForStructs([]parser.StructInfo{order})
+		if err == nil {
+			t.Errorf("%s: expected import cycle error", tc.formatter)
+			continue
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("%s: expected error %q, got %q", tc.formatter, tc.expected, err.Error())
+		}
+	}
+
+	// Without the import path of the package, nothing is checked
+	order.PackagePath = ""
+	cfg := config.DefaultConfig()
+	cfg.CustomFormatters = map[string]string{"Money": "example.com/shop/logfmt.Money"}
+	if _, err := New(cfg).GenerateForStructs([]parser.StructInfo{order}); err != nil {
+		t.Errorf("Expected no error without the package path, got %v", err)
+	}
+}
+
+func TestGenerateForStructsImportCycleDotlessModule(t *testing.T) {
+	// Module paths without a dot are checked like any other
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module myapp\n\ngo 1.21\n",
+		"models/models.go": "package models\n\ntype Money int64\n\ntype Order struct {\n\tPrice Money\n}\n",
+		"logfmt/logfmt.go": "package logfmt\n\nimport (\n\t\"log/slog\"\n\n\t\"myapp/models\"\n)\n\nfunc Money(key string, m models.Money) slog.Attr {\n\treturn slog.Int64(key, int64(m))\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	order := parser.StructInfo{
+		Name:        "Order",
+		PackageName: "models",
+		PackagePath: "myapp/models",
+		FilePath:    filepath.Join(dir, "models", "models.go"),
+		Fields:      []parser.FieldInfo{{Name: "Price", Type: "Money"}},
+	}
+	cfg := config.DefaultConfig()
+	cfg.CustomFormatters = map[string]string{"Money": "myapp/logfmt.Money"}
+
+	_, err := New(cfg).GenerateForStructs([]parser.StructInfo{order})
+	expected := "import cycle: generated code for myapp/models would import myapp/logfmt -> myapp/models"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestIsStandardPackage(t *testing.T) {
+	testCases := map[string]bool{
+		"log/slog":                  true,
+		"encoding/hex":              true,
+		"myapp/logfmt":              false,
+		"example.com/shop/logfmt":   false,
+		"github.com/acme/logfmt/v2": false,
+	}
+	for path, expected := range testCases {
+		if result := isStandardPackage(path); result != expected {
+			t.Errorf("isStandardPackage(%q): expected %v, got %v", path, expected, result)
+		}
+	}
+}
+
+func TestGenerateForStructsMethodName(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "Address",
+			PackageName: "models",
+			Fields:      []parser.FieldInfo{{Name: "City", Type: "string"}},
+		},
+		{
+			Name:        "User",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Name", Type: "string"},
+				{Name: "Home", Type: "Address"},
+			},
+		},
+	}
+	source := "package models\n\ntype Address struct {\n\tCity string\n}\n\ntype User struct {\n\tName string\n\tHome Address\n}\n"
+
+	testCases := []struct {
+		methodName string
+		expected   []string
+		assertion  bool
+	}{
+		{config.DefaultMethodName, []string{
+			"// LogValue implements slog.LogValuer for User\nfunc (u User) LogValue() slog.Value {",
+			"Value: u.Home.LogValue()",
+		}, true},
+		{"SlogValue", []string{
+			"// SlogValue returns the slog.Value logging User\nfunc (u User) SlogValue() slog.Value {",
+			"Value: u.Home.SlogValue()",
+		}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.methodName, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.MethodName = tc.methodName
+			result, err := New(cfg).GenerateForStructs(structs)
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			for _, expected := range tc.expected {
+				if !strings.Contains(result.Content, expected) {
+					t.Errorf("Generated code missing %q, got:\n%s", expected, result.Content)
+				}
+			}
+
+			// Only methods named LogValue implement slog.LogValuer
+			if assertion := strings.Contains(result.Content, "var _ slog.LogValuer"); assertion != tc.assertion {
+				t.Errorf("Expected slog.LogValuer assertion %v, got:\n%s", tc.assertion, result.Content)
+			}
+
+			typeCheck(t, map[string]string{
+				"models.go":     source,
+				result.FilePath: result.Content,
+			})
+		})
+	}
+}
+
+func TestGenerateForStructsInvalidLogFunc(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "Job",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Limits", Type: "struct{Timeout int64}", Fields: []parser.FieldInfo{
+					{Name: "Timeout", Type: "int64", LogOptions: parser.ParseLogTag("func=slog.Seconds")},
+				}},
+			},
+		},
+	}
+
+	_, err := New(config.DefaultConfig()).GenerateForStructs(structs)
+	if err == nil {
+		t.Fatalf("Expected error for unknown log function")
+	}
+	if !strings.HasPrefix(err.Error(), `field Timeout of Job: invalid log function "slog.Seconds": must be one of slog.Any,`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGenerateForStructsInvalidBoolStrings(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "Feature",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "Enabled", Type: "bool", LogOptions: parser.ParseLogTag("bool=on")},
+			},
+		},
+	}
+
+	_, err := New(config.DefaultConfig()).GenerateForStructs(structs)
+	if err == nil {
+		t.Fatalf("Expected error for malformed bool strings")
+	}
+	expected := `field Enabled of Feature: invalid bool strings "on": must be <true>/<false>, e.g. enabled/disabled`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestGenerateForStructsUnknownLogOption(t *testing.T) {
+	structs := []parser.StructInfo{
+		{
+			Name:        "User",
+			PackageName: "models",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("redcat")},
+			},
+		},
+	}
+
+	// A misspelled redact fails generation rather than logging the field
+	_, err := New(config.DefaultConfig()).GenerateForStructs(structs)
+	if err == nil {
+		t.Fatalf("Expected error for unknown log tag option")
+	}
+	if !strings.HasPrefix(err.Error(), `field Password of User: unknown log tag option "redcat"`) {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestGenerateForStructsRedactedNested(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password", "token"}
+	cfg.GenerateRedacted = true
+
+	source := `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Creds struct {
+	User     string
+	Password string
+}
+
+type Account struct {
+	Token  string
+	Creds  Creds
+	PCreds *Creds
+}
+
+func main() {
+	a := Account{Token: "tok-secret", Creds: Creds{"ann", "pw-secret"}, PCreds: &Creds{"bob", "ptr-secret"}}
+	redacted, _ := json.Marshal(a.Redacted())
+	original, _ := json.Marshal(a)
+	fmt.Printf("%s\n%s\n", redacted, original)
+}
+`
+	structs := []parser.StructInfo{
+		{
+			Name:        "Creds",
+			PackageName: "main",
+			Fields: []parser.FieldInfo{
+				{Name: "User", Type: "string"},
+				{Name: "Password", Type: "string"},
+			},
+		},
+		{
+			Name:        "Account",
+			PackageName: "main",
+			Fields: []parser.FieldInfo{
+				{Name: "Token", Type: "string"},
+				{Name: "Creds", Type: "Creds"},
+				{Name: "PCreds", Type: "*Creds", IsPointer: true},
+			},
+		},
+	}
+
+	for _, style := range []string{config.OutputStyleGrouped, config.OutputStyleFlattened} {
+		t.Run(style, func(t *testing.T) {
+			cfg.OutputStyle = style
+			result, err := New(cfg).GenerateForStructs(structs)
+			if err != nil {
+				t.Fatalf("GenerateForStructs failed: %v", err)
+			}
+
+			// Secrets of nested structs, including those behind pointers, are
+			// redacted in the copy, and the original is left untouched
+			output := runGenerated(t, map[string]string{"main.go": source, result.FilePath: result.Content})
+			redacted, original, _ := strings.Cut(output, "\n")
+			expected := `{"Token":"[REDACTED]","Creds":{"User":"ann","Password":"[REDACTED]"},"PCreds":{"User":"bob","Password":"[REDACTED]"}}`
+			if redacted != expected {
+				t.Errorf("Expected redacted copy %s, got %s", expected, redacted)
+			}
+			for _, secret := range []string{"tok-secret", "pw-secret", "ptr-secret"} {
+				if strings.Contains(redacted, secret) {
+					t.Errorf("Redacted copy contains %s: %s", secret, redacted)
+				}
+				if !strings.Contains(original, secret) {
+					t.Errorf("Original lost %s: %s", secret, original)
+				}
+			}
+		})
+	}
+}
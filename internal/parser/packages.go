@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadMode requests the syntax and type information needed to resolve field
+// types, including types imported from other packages
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+	packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps
+
+// LoadPackages loads packages with golang.org/x/tools/go/packages, which
+// type-checks them so that field types resolve across packages. Paths are
+// package directories or Go source files. Unlike ParsePackage, this requires
+// the packages to belong to a module, and is slower.
+//
+// With IncludeTests, test files are loaded and scanned as well.
+//
+// Type errors do not prevent generation, since code may refer to methods that
+// have not been generated yet; they are reported in the result's Errors.
+func (p *Parser) LoadPackages(paths ...string) (*ParseResult, error) {
+	result := &ParseResult{}
+
+	cfg := &packages.Config{
+		Mode:  loadMode,
+		Fset:  p.fileSet,
+		Tests: p.IncludeTests,
+	}
+
+	patterns := make([]string, len(paths))
+	for i, path := range paths {
+		patterns[i] = loadPattern(path)
+	}
+
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	// With tests, a package's files are also part of its test variant, so
+	// each file is only scanned once
+	seen := make(map[string]bool)
+
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			if pkgErr.Kind == packages.ListError {
+				return nil, fmt.Errorf("failed to load package %s: %w", pkg.PkgPath, pkgErr)
+			}
+			result.Errors = append(result.Errors, pkgErr)
+		}
+
+		aliases := p.collectAliases(pkg.Syntax...)
+		for _, file := range pkg.Syntax {
+			filePath := p.fileSet.Position(file.Package).Filename
+			if seen[filePath] {
+				continue
+			}
+			seen[filePath] = true
+
+			// Only files with the directive or opted-in structs yield structs
+			structs := p.extractStructs(file, filePath, aliases, nil, p.hasOakDirective(file))
+			for i := range structs {
+				structs[i].PackagePath = pkg.PkgPath
+				resolveFieldTypes(&structs[i], pkg.Types)
+			}
+			result.Structs = append(result.Structs, structs...)
+		}
+	}
+
+	return result, nil
+}
+
+// loadPattern converts a path into a go/packages query: files use the file=
+// query, and relative directories are made explicit so they are not
+// mistaken for import paths
+func loadPattern(path string) string {
+	if strings.HasSuffix(path, ".go") {
+		return "file=" + path
+	}
+	if filepath.IsAbs(path) || strings.HasPrefix(path, "."+string(filepath.Separator)) || path == "." || strings.HasPrefix(path, "..") {
+		return path
+	}
+	return "." + string(filepath.Separator) + path
+}
+
+// resolveFieldTypes records the type-checked type of each field of a
+// package-level struct
+func resolveFieldTypes(structInfo *StructInfo, pkg *types.Package) {
+	if pkg == nil {
+		return
+	}
+
+	obj, ok := pkg.Scope().Lookup(structInfo.Name).(*types.TypeName)
+	if !ok {
+		return
+	}
+
+	if structType, ok := obj.Type().Underlying().(*types.Struct); ok {
+		resolveFields(structInfo.Fields, structType)
+	}
+}
+
+// resolveFields sets the type information of fields parsed from a struct
+// type, including the fields of inline anonymous structs
+func resolveFields(fields []FieldInfo, structType *types.Struct) {
+	if structType.NumFields() != len(fields) {
+		return
+	}
+
+	for i := range fields {
+		fieldType := structType.Field(i).Type()
+		fields[i].TypeInfo = fieldType
+		if pointer, ok := fieldType.(*types.Pointer); ok {
+			fieldType = pointer.Elem()
+		}
+		fields[i].Interface = types.IsInterface(fieldType)
+		if inline, ok := fieldType.(*types.Struct); ok && len(fields[i].Fields) > 0 {
+			resolveFields(fields[i].Fields, inline)
+		}
+	}
+}
+
+// ImportChain reports a chain of imports leading from one of the packages
+// from to the package target, as import paths starting with the importing
+// package and ending with target, or nil when none of them depends on target.
+// Packages are loaded relative to dir.
+func ImportChain(dir string, from []string, target string) ([]string, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+
+	pkgs, err := packages.Load(cfg, from...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	visited := make(map[string]bool)
+	var visit func(pkg *packages.Package) []string
+	visit = func(pkg *packages.Package) []string {
+		if pkg.PkgPath == target {
+			return []string{target}
+		}
+		if visited[pkg.PkgPath] {
+			return nil
+		}
+		visited[pkg.PkgPath] = true
+
+		for _, imported := range pkg.Imports {
+			if chain := visit(imported); chain != nil {
+				return append([]string{pkg.PkgPath}, chain...)
+			}
+		}
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if chain := visit(pkg); chain != nil {
+			return chain, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,411 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeModule creates a module with the given files, relative to its root
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/shop\n\ngo 1.21\n"
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadPackages(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"money/money.go": `package money
+
+import "log/slog"
+
+type Amount int64
+
+func (a Amount) LogValue() slog.Value { return slog.Int64Value(int64(a)) }
+
+type Currency string
+
+func (c *Currency) String() string { return string(*c) }
+`,
+		"orders/orders.go": `package orders
+
+import "example.com/shop/money"
+
+//go:generate oak
+type Order struct {
+	ID       int
+	Total    money.Amount
+	Currency *money.Currency
+	Note     string ` + "`log:\"-\"`" + `
+}
+`,
+		"orders/helpers.go": `package orders
+
+type internal struct {
+	Value int
+}
+`,
+	})
+	t.Chdir(dir)
+
+	p := New()
+	result, err := p.LoadPackages("orders")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", result.Errors)
+	}
+
+	// Only files with the directive are considered
+	if len(result.Structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(result.Structs))
+	}
+
+	order := result.Structs[0]
+	if order.Name != "Order" || order.PackageName != "orders" {
+		t.Errorf("Expected orders.Order, got %s.%s", order.PackageName, order.Name)
+	}
+	if filepath.Base(order.FilePath) != "orders.go" {
+		t.Errorf("Expected file path orders.go, got %s", order.FilePath)
+	}
+	if order.Fields[3].LogOptions.Raw != "-" {
+		t.Errorf("Expected log tag to be parsed, got %q", order.Fields[3].LogOptions.Raw)
+	}
+
+	testCases := []struct {
+		field     string
+		typeName  string
+		logValuer bool
+		stringer  bool
+	}{
+		{"ID", "int", false, false},
+		{"Total", "example.com/shop/money.Amount", true, false},
+		{"Currency", "*example.com/shop/money.Currency", false, true},
+		{"Note", "string", false, false},
+	}
+
+	for i, tc := range testCases {
+		field := order.Fields[i]
+		if !field.HasTypeInfo() {
+			t.Errorf("Field %s: expected type information", tc.field)
+			continue
+		}
+		if field.TypeInfo.String() != tc.typeName {
+			t.Errorf("Field %s: expected type %s, got %s", tc.field, tc.typeName, field.TypeInfo)
+		}
+		if field.ImplementsLogValuer() != tc.logValuer {
+			t.Errorf("Field %s: expected ImplementsLogValuer %v", tc.field, tc.logValuer)
+		}
+		if field.ImplementsStringer() != tc.stringer {
+			t.Errorf("Field %s: expected ImplementsStringer %v", tc.field, tc.stringer)
+		}
+	}
+}
+
+func TestImportChain(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"models/models.go": "package models\n\ntype Order struct{}\n",
+		"store/store.go":   "package store\n\nimport \"example.com/shop/models\"\n\nvar Orders []models.Order\n",
+		"logfmt/logfmt.go": "package logfmt\n\nimport _ \"example.com/shop/store\"\n",
+		"money/money.go":   "package money\n\ntype Amount int64\n",
+	})
+
+	testCases := []struct {
+		from     []string
+		expected []string
+	}{
+		{[]string{"example.com/shop/logfmt"}, []string{"example.com/shop/logfmt", "example.com/shop/store", "example.com/shop/models"}},
+		{[]string{"example.com/shop/money", "example.com/shop/store"}, []string{"example.com/shop/store", "example.com/shop/models"}},
+		{[]string{"example.com/shop/money"}, nil},
+	}
+
+	for _, tc := range testCases {
+		chain, err := ImportChain(dir, tc.from, "example.com/shop/models")
+		if err != nil {
+			t.Fatalf("ImportChain failed: %v", err)
+		}
+		if !reflect.DeepEqual(chain, tc.expected) {
+			t.Errorf("%v: expected chain %v, got %v", tc.from, tc.expected, chain)
+		}
+	}
+}
+
+func TestLoadPackagesSourceFile(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"users/user.go": `package users
+
+//go:generate oak
+type User struct {
+	Name string
+}
+`,
+		"users/account.go": `package users
+
+//go:generate oak
+type Account struct {
+	Owner User
+}
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages(filepath.Join("users", "user.go"))
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+
+	// The file's package is loaded, so both structs are found
+	if len(result.Structs) != 2 {
+		t.Fatalf("Expected 2 structs, got %d", len(result.Structs))
+	}
+}
+
+func TestLoadPackagesEmbeddedInterfaces(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"service/base.go": `package service
+
+type Base struct {
+	ID int
+}
+`,
+		"service/service.go": `package service
+
+import "hash"
+
+//go:generate oak
+type Service struct {
+	hash.Hash
+	Base
+	Name string
+	Sum  hash.Hash
+	Prev *hash.Hash
+}
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages("service")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(result.Structs))
+	}
+
+	// Type information recognizes interfaces of any package, embedded or not
+	for _, field := range result.Structs[0].Fields {
+		expected := field.Name == "Hash" || field.Name == "Sum" || field.Name == "Prev"
+		if field.Interface != expected {
+			t.Errorf("%s: expected Interface %v, got %v", field.Name, expected, field.Interface)
+		}
+	}
+}
+
+func TestLoadPackagesInlineStructs(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"server/server.go": `package server
+
+import "time"
+
+//go:generate oak
+type Server struct {
+	Config *struct {
+		Started time.Time
+	}
+}
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages("server")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(result.Structs))
+	}
+
+	// Fields of inline structs are resolved too
+	config := result.Structs[0].Fields[0]
+	if len(config.Fields) != 1 || config.Fields[0].TypeInfo == nil {
+		t.Fatalf("Config: expected a resolved inline field, got %+v", config.Fields)
+	}
+	if typ := config.Fields[0].TypeInfo.String(); typ != "time.Time" {
+		t.Errorf("Started: expected type time.Time, got %s", typ)
+	}
+}
+
+func TestLoadPackagesTypeErrors(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"users/user.go": `package users
+
+//go:generate oak
+type User struct {
+	Name string
+}
+
+// Refers to a method that has not been generated yet
+var _ = User{}.LogValue
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages("users")
+	if err != nil {
+		t.Fatalf("Type errors should not fail loading: %v", err)
+	}
+	if len(result.Errors) == 0 {
+		t.Errorf("Expected type errors to be reported")
+	}
+	if len(result.Structs) != 1 {
+		t.Errorf("Expected structs despite type errors, got %d", len(result.Structs))
+	}
+}
+
+func TestFieldInfoPointerLogValuer(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"booking/booking.go": `package booking
+
+import "log/slog"
+
+type Reservation struct{ ID int }
+
+func (r *Reservation) LogValue() slog.Value { return slog.IntValue(r.ID) }
+
+type Guest struct{ Name string }
+
+func (g Guest) LogValue() slog.Value { return slog.StringValue(g.Name) }
+`,
+		"orders/orders.go": `package orders
+
+import "example.com/shop/booking"
+
+//go:generate oak
+type Order struct {
+	Reservation booking.Reservation
+	Previous    *booking.Reservation
+	Guest       booking.Guest
+}
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages("orders")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(result.Structs))
+	}
+
+	// Only values whose type lacks the pointer receiver's method need their address
+	expected := map[string]bool{"Reservation": true, "Previous": false, "Guest": false}
+	for _, field := range result.Structs[0].Fields {
+		if !field.ImplementsLogValuer() {
+			t.Errorf("Field %s: expected ImplementsLogValuer", field.Name)
+		}
+		if field.PointerLogValuer() != expected[field.Name] {
+			t.Errorf("Field %s: expected PointerLogValuer %v", field.Name, expected[field.Name])
+		}
+	}
+}
+
+func TestFieldInfoWithoutTypeInfo(t *testing.T) {
+	field := FieldInfo{Name: "Total", Type: "money.Amount"}
+	if field.HasTypeInfo() || field.ImplementsLogValuer() || field.PointerLogValuer() || field.ImplementsStringer() {
+		t.Errorf("Fields parsed without type information should not report implementations")
+	}
+	if constants, _ := field.EnumConstants(); constants != nil {
+		t.Errorf("Fields parsed without type information should not report enum constants")
+	}
+}
+
+func TestFieldInfoEnumConstants(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"status/status.go": `package status
+
+type Code uint8
+
+const (
+	OK Code = iota
+	Failed
+	unknown
+)
+`,
+		"users/user.go": `package users
+
+import "example.com/shop/status"
+
+type State int
+
+const (
+	Active State = iota
+	Inactive
+	Default = Active
+)
+
+type Level int
+
+type Name string
+
+const Admin Name = "admin"
+
+//go:generate oak
+type User struct {
+	State  State
+	Prev   *State
+	Result status.Code
+	Level  Level
+	Name   Name
+}
+`,
+	})
+	t.Chdir(dir)
+
+	result, err := New().LoadPackages("users")
+	if err != nil {
+		t.Fatalf("LoadPackages failed: %v", err)
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("Expected 1 struct, got %d", len(result.Structs))
+	}
+
+	testCases := []struct {
+		field      string
+		constants  []EnumConstant
+		importPath string
+	}{
+		// Default shares Active's value, so only the first is kept
+		{"State", []EnumConstant{{Name: "Active", Expr: "Active"}, {Name: "Inactive", Expr: "Inactive"}}, ""},
+		{"Prev", []EnumConstant{{Name: "Active", Expr: "Active"}, {Name: "Inactive", Expr: "Inactive"}}, ""},
+		// Unexported constants of other packages are inaccessible
+		{"Result", []EnumConstant{{Name: "OK", Expr: "status.OK"}, {Name: "Failed", Expr: "status.Failed"}}, "example.com/shop/status"},
+		// Types without constants, and string types, are not enums
+		{"Level", nil, ""},
+		{"Name", nil, ""},
+	}
+
+	for i, tc := range testCases {
+		constants, importPath := result.Structs[0].Fields[i].EnumConstants()
+		if !reflect.DeepEqual(constants, tc.constants) {
+			t.Errorf("Field %s: expected constants %v, got %v", tc.field, tc.constants, constants)
+		}
+		if importPath != tc.importPath {
+			t.Errorf("Field %s: expected import path %q, got %q", tc.field, tc.importPath, importPath)
+		}
+	}
+}
@@ -1,20 +1,31 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
 // StructInfo represents information about a struct that needs LogValue generation
 type StructInfo struct {
 	Name        string      // Name of the struct
+	TypeParams  []string    // Type parameter names of a generic struct, e.g. [K V]
 	PackageName string      // Package name
+	PackagePath string      // Import path of the package; only set by LoadPackages
 	Fields      []FieldInfo // List of fields in the struct
 	FilePath    string      // Path to the source file
+
+	Aliases map[string]string // Type aliases of the package, e.g. Celsius -> float64
+	Imports map[string]string // Renamed imports of the struct's file, e.g. t -> time
 }
 
 // FieldInfo represents information about a struct field
@@ -22,19 +33,29 @@ type FieldInfo struct {
 	Name     string // Field name
 	Type     string // Field type as string
 	Tag      string // Complete struct tag
-	LogTag   string // Value of the log tag (e.g., "redact", "-")
+	LogOptions LogTagOptions // Parsed log tag (e.g., "redact", "-", "redact,name=pw")
+	JSONName string // Name from the json tag without options (e.g., "guest_name")
 	IsPointer bool  // Whether the field is a pointer type
+	Embedded bool   // Whether the field is embedded, named after its type
+	Interface bool  // Whether the field's type, or the type it points to, is known to be an interface
+	Doc      string // Doc or line comment attached to the field
+	Fields   []FieldInfo // Fields of an inline anonymous struct type (or pointer to one)
+
+	TypeInfo types.Type // Type-checked field type; only set by LoadPackages
 }
 
 // ParseResult represents the result of parsing Go source files
 type ParseResult struct {
 	Structs []StructInfo // Structs that need LogValue generation
-	Errors  []error      // Any parsing errors encountered
+	Errors  []error      // Files that failed to parse, whose structs are left out
 }
 
 // Parser handles parsing Go source files for Oak directives
 type Parser struct {
 	fileSet *token.FileSet
+
+	// IncludeTests makes package parsing scan _test.go files for directives
+	IncludeTests bool
 }
 
 // New creates a new Parser instance
@@ -44,48 +65,74 @@ func New() *Parser {
 	}
 }
 
-// ParseFile parses a single Go source file for Oak directives
+// ParseFile parses a single Go source file for Oak directives. A syntax error
+// is recorded in the result's Errors rather than returned.
 func (p *Parser) ParseFile(filePath string) (*ParseResult, error) {
 	result := &ParseResult{}
 	
 	// Parse the Go source file
 	file, err := parser.ParseFile(p.fileSet, filePath, nil, parser.ParseComments)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
-	}
-	
-	// Check if the file has the //go:generate oak directive
-	if !p.hasOakDirective(file) {
-		return result, nil // No Oak directive found, return empty result
+		var syntaxErr scanner.ErrorList
+		if !errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+		}
+		result.Errors = append(result.Errors, fmt.Errorf("failed to parse file %s: %w", filePath, err))
+		return result, nil
 	}
 	
-	// Extract structs from the file
-	structs := p.extractStructs(file, filePath)
+	// Extract the structs of a file with the //go:generate oak directive, or
+	// those opted in with //oak:generate
+	structs := p.extractStructs(file, filePath, p.collectAliases(file), p.collectInterfaces(file), p.hasOakDirective(file))
 	result.Structs = structs
 	
 	return result, nil
 }
 
-// ParsePackage parses all Go files in a package directory for Oak directives
+// ParsePackage parses all Go files in a package directory for Oak directives.
+// Files that fail to parse are recorded in the result's Errors, and the
+// structs of the other files are still extracted.
 func (p *Parser) ParsePackage(packagePath string) (*ParseResult, error) {
 	result := &ParseResult{}
 	
-	// Parse all Go files in the package
-	packages, err := parser.ParseDir(p.fileSet, packagePath, nil, parser.ParseComments)
+	entries, err := os.ReadDir(packagePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse package %s: %w", packagePath, err)
 	}
+
+	// Parse all Go files in the package, leaving out test files unless
+	// requested, and group them by package name
+	packages := make(map[string]map[string]*ast.File)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || (!p.IncludeTests && IsTestFile(name)) {
+			continue
+		}
+		filePath := filepath.Join(packagePath, name)
+		file, err := parser.ParseFile(p.fileSet, filePath, nil, parser.ParseComments)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("failed to parse file %s: %w", filePath, err))
+			continue
+		}
+		if packages[file.Name.Name] == nil {
+			packages[file.Name.Name] = make(map[string]*ast.File)
+		}
+		packages[file.Name.Name][filePath] = file
+	}
 	
 	// Process each package (there should typically be only one)
-	for _, pkg := range packages {
-		for filePath, file := range pkg.Files {
-			// Check if this file has the Oak directive
-			if !p.hasOakDirective(file) {
-				continue
-			}
-			
+	for _, pkgFiles := range packages {
+		// Aliases and interfaces may be declared in any file of the package
+		var files []*ast.File
+		for _, file := range pkgFiles {
+			files = append(files, file)
+		}
+		aliases := p.collectAliases(files...)
+		interfaces := p.collectInterfaces(files...)
+
+		for filePath, file := range pkgFiles {
 			// Extract structs from this file
-			structs := p.extractStructs(file, filePath)
+			structs := p.extractStructs(file, filePath, aliases, interfaces, p.hasOakDirective(file))
 			result.Structs = append(result.Structs, structs...)
 		}
 	}
@@ -93,20 +140,110 @@ func (p *Parser) ParsePackage(packagePath string) (*ParseResult, error) {
 	return result, nil
 }
 
+// IsTestFile reports whether a path names a Go test file
+func IsTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
 // hasOakDirective checks if a file contains the //go:generate oak directive
+// in any comment, including the package doc comment. Comments on the line of
+// a type declaration opt in that type alone, so they are not counted.
 func (p *Parser) hasOakDirective(file *ast.File) bool {
+	lineComments := p.typeLineComments(file)
+	for _, commentGroup := range file.Comments {
+		for _, comment := range commentGroup.List {
+			if _, ok := lineComments[comment]; ok {
+				continue
+			}
+			if isOakDirective(commentText(comment)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commentText returns the text of a comment without its // or /* */ markers
+func commentText(comment *ast.Comment) string {
+	text := strings.TrimSpace(comment.Text)
+	if strings.HasPrefix(text, "//") {
+		text = strings.TrimSpace(text[2:])
+	} else if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
+		text = strings.TrimSpace(text[2 : len(text)-2])
+	}
+	return text
+}
+
+// typeLineComments maps the comments following a type declaration on its
+// first or last line, as in type User struct { ... } //go:generate oak, to
+// the declared type
+func (p *Parser) typeLineComments(file *ast.File) map[*ast.Comment]*ast.TypeSpec {
+	lines := make(map[int]*ast.TypeSpec)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			lines[p.fileSet.Position(typeSpec.Pos()).Line] = typeSpec
+			lines[p.fileSet.Position(typeSpec.End()).Line] = typeSpec
+		}
+	}
+
+	comments := make(map[*ast.Comment]*ast.TypeSpec)
 	for _, commentGroup := range file.Comments {
 		for _, comment := range commentGroup.List {
-			text := strings.TrimSpace(comment.Text)
-			// Remove // or /* */ comment markers
-			if strings.HasPrefix(text, "//") {
-				text = strings.TrimSpace(text[2:])
-			} else if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
-				text = strings.TrimSpace(text[2 : len(text)-2])
+			typeSpec, ok := lines[p.fileSet.Position(comment.Pos()).Line]
+			if ok && comment.Pos() > typeSpec.Pos() {
+				comments[comment] = typeSpec
 			}
-			
-			// Check for go:generate oak directive
-			if strings.HasPrefix(text, "go:generate oak") {
+		}
+	}
+	return comments
+}
+
+// lineDirectiveTypes returns the types opted in by a //go:generate oak or
+// //oak:generate directive on the line of their declaration
+func (p *Parser) lineDirectiveTypes(file *ast.File) map[*ast.TypeSpec]bool {
+	opted := make(map[*ast.TypeSpec]bool)
+	for comment, typeSpec := range p.typeLineComments(file) {
+		if isOakDirective(commentText(comment)) || strings.TrimSpace(comment.Text) == structDirective {
+			opted[typeSpec] = true
+		}
+	}
+	return opted
+}
+
+// isOakDirective reports whether comment text, without its markers, is a
+// go:generate directive running oak. Whitespace and the case of go:generate
+// are not significant, so "go:generate   oak" and "GO: generate oak" match,
+// but the command must be exactly oak rather than a longer name such as oakley.
+func isOakDirective(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) >= 3 && strings.EqualFold(fields[0], "go:") {
+		fields = append([]string{fields[0] + fields[1]}, fields[2:]...)
+	}
+	return len(fields) >= 2 && strings.EqualFold(fields[0], "go:generate") && fields[1] == "oak"
+}
+
+// structDirective is the comment opting a single struct into generation
+const structDirective = "//oak:generate"
+
+// hasStructDirective checks if a type declaration's doc comment contains the
+// //oak:generate directive. The comment above an unparenthesized declaration
+// belongs to the GenDecl, while specs in a group carry their own.
+func hasStructDirective(genDecl *ast.GenDecl, typeSpec *ast.TypeSpec) bool {
+	docs := []*ast.CommentGroup{typeSpec.Doc}
+	if !genDecl.Lparen.IsValid() {
+		docs = append(docs, genDecl.Doc)
+	}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, comment := range doc.List {
+			if strings.TrimSpace(comment.Text) == structDirective {
 				return true
 			}
 		}
@@ -114,54 +251,167 @@ func (p *Parser) hasOakDirective(file *ast.File) bool {
 	return false
 }
 
-// extractStructs extracts all struct declarations from a file
-func (p *Parser) extractStructs(file *ast.File, filePath string) []StructInfo {
+// extractStructs extracts the struct declarations from a file, recording the
+// type aliases their field types may refer to and which embedded fields are
+// interfaces of the package. Unless all is set, only structs carrying the
+// //oak:generate directive, or a directive on their declaration's line, are
+// extracted. Only package-level declarations are considered, since methods
+// cannot be declared on types local to a function.
+func (p *Parser) extractStructs(file *ast.File, filePath string, aliases map[string]string, interfaces map[string]bool, all bool) []StructInfo {
 	var structs []StructInfo
-	
-	// Walk the AST to find struct declarations
-	ast.Inspect(file, func(node ast.Node) bool {
-		switch n := node.(type) {
-		case *ast.GenDecl:
-			// Check if this is a type declaration
-			if n.Tok == token.TYPE {
-				for _, spec := range n.Specs {
-					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
-						if structType, ok := typeSpec.Type.(*ast.StructType); ok {
-							// Found a struct declaration
-							structInfo := StructInfo{
-								Name:        typeSpec.Name.Name,
-								PackageName: file.Name.Name,
-								FilePath:    filePath,
-								Fields:      p.extractFields(structType),
-							}
-							structs = append(structs, structInfo)
-						}
-					}
-				}
+	imports := p.collectImports(file)
+	lineDirectives := p.lineDirectiveTypes(file)
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok || (!all && !hasStructDirective(genDecl, typeSpec) && !lineDirectives[typeSpec]) {
+				continue
 			}
+			structs = append(structs, StructInfo{
+				Name:        typeSpec.Name.Name,
+				TypeParams:  typeParamNames(typeSpec),
+				PackageName: file.Name.Name,
+				FilePath:    filePath,
+				Fields:      p.extractFields(structType, interfaces),
+				Aliases:     aliases,
+				Imports:     imports,
+			})
 		}
-		return true
-	})
-	
+	}
+
 	return structs
 }
 
-// extractFields extracts field information from a struct type
-func (p *Parser) extractFields(structType *ast.StructType) []FieldInfo {
+// typeParamNames returns the names of a type's type parameters, in order
+func typeParamNames(typeSpec *ast.TypeSpec) []string {
+	if typeSpec.TypeParams == nil {
+		return nil
+	}
+	var names []string
+	for _, field := range typeSpec.TypeParams.List {
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// collectAliases returns the package-level type aliases declared in files,
+// mapping each alias name to its target type. Generic aliases are ignored.
+func (p *Parser) collectAliases(files ...*ast.File) map[string]string {
+	var aliases map[string]string
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if !typeSpec.Assign.IsValid() || typeSpec.TypeParams != nil {
+					continue
+				}
+				if aliases == nil {
+					aliases = make(map[string]string)
+				}
+				aliases[typeSpec.Name.Name] = p.typeToString(typeSpec.Type)
+			}
+		}
+	}
+	return aliases
+}
+
+// collectImports returns the imports of a file that are renamed, such as
+// import t "time", mapping each name to its import path. Blank and dot
+// imports are ignored.
+func (p *Parser) collectImports(file *ast.File) map[string]string {
+	var imports map[string]string
+	for _, spec := range file.Imports {
+		if spec.Name == nil || spec.Name.Name == "_" || spec.Name.Name == "." {
+			continue
+		}
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imports == nil {
+			imports = make(map[string]string)
+		}
+		imports[spec.Name.Name] = importPath
+	}
+	return imports
+}
+
+// standardInterfaces are the predeclared and standard library interfaces
+// commonly embedded in structs, recognized without type information
+var standardInterfaces = map[string]bool{
+	"any":                true,
+	"error":              true,
+	"context.Context":    true,
+	"fmt.Stringer":       true,
+	"http.Handler":       true,
+	"io.Closer":          true,
+	"io.ReadCloser":      true,
+	"io.ReadWriteCloser": true,
+	"io.ReadWriter":      true,
+	"io.Reader":          true,
+	"io.Writer":          true,
+	"io.WriteCloser":     true,
+	"net.Conn":           true,
+	"sort.Interface":     true,
+	"sync.Locker":        true,
+}
+
+// collectInterfaces returns the names of the interface types declared in files
+func (p *Parser) collectInterfaces(files ...*ast.File) map[string]bool {
+	interfaces := make(map[string]bool)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+					interfaces[typeSpec.Name.Name] = true
+				}
+			}
+		}
+	}
+	return interfaces
+}
+
+// extractFields extracts field information from a struct type. Fields are
+// interfaces when their type is one of interfaces or a standard interface.
+func (p *Parser) extractFields(structType *ast.StructType, interfaces map[string]bool) []FieldInfo {
 	var fields []FieldInfo
 	
 	for _, field := range structType.Fields.List {
 		// Handle multiple names for the same type (e.g., x, y int)
 		if len(field.Names) == 0 {
-			// Anonymous field (embedded struct)
+			// Anonymous field (embedded struct or interface), named after its
+			// type without the pointer or package qualifier
+			fieldType := p.typeToString(field.Type)
+			name, _, _ := strings.Cut(strings.TrimPrefix(fieldType, "*"), "[")
 			fieldInfo := FieldInfo{
-				Name:      p.typeToString(field.Type),
-				Type:      p.typeToString(field.Type),
+				Name:      name[strings.LastIndex(name, ".")+1:],
+				Type:      fieldType,
 				IsPointer: p.isPointerType(field.Type),
+				Embedded:  true,
+				Interface: interfaces[fieldType] || standardInterfaces[fieldType],
+				Doc:       p.extractDoc(field),
 			}
 			if field.Tag != nil {
 				fieldInfo.Tag = field.Tag.Value
-				fieldInfo.LogTag = p.extractLogTag(field.Tag.Value)
+				fieldInfo.LogOptions = ParseLogTag(p.extractLogTag(field.Tag.Value))
+				fieldInfo.JSONName = p.extractJSONName(field.Tag.Value)
 			}
 			fields = append(fields, fieldInfo)
 		} else {
@@ -170,10 +420,16 @@ func (p *Parser) extractFields(structType *ast.StructType) []FieldInfo {
 					Name:      name.Name,
 					Type:      p.typeToString(field.Type),
 					IsPointer: p.isPointerType(field.Type),
+					Doc:       p.extractDoc(field),
+					Fields:    p.inlineFields(field.Type, interfaces),
+				}
+				if elemType := strings.TrimPrefix(fieldInfo.Type, "*"); interfaces[elemType] || standardInterfaces[elemType] {
+					fieldInfo.Interface = true
 				}
 				if field.Tag != nil {
 					fieldInfo.Tag = field.Tag.Value
-					fieldInfo.LogTag = p.extractLogTag(field.Tag.Value)
+					fieldInfo.LogOptions = ParseLogTag(p.extractLogTag(field.Tag.Value))
+					fieldInfo.JSONName = p.extractJSONName(field.Tag.Value)
 				}
 				fields = append(fields, fieldInfo)
 			}
@@ -183,6 +439,34 @@ func (p *Parser) extractFields(structType *ast.StructType) []FieldInfo {
 	return fields
 }
 
+// inlineFields returns the fields of an inline anonymous struct type, such as
+// struct{ Host string }, or of a pointer to one
+func (p *Parser) inlineFields(expr ast.Expr, interfaces map[string]bool) []FieldInfo {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if structType, ok := expr.(*ast.StructType); ok {
+		return p.extractFields(structType, interfaces)
+	}
+	return nil
+}
+
+// extractDoc returns the text of a field's doc comment, falling back to its
+// trailing line comment when there is no doc comment
+func (p *Parser) extractDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return strings.TrimSpace(field.Doc.Text())
+	}
+	if field.Comment != nil {
+		return strings.TrimSpace(field.Comment.Text())
+	}
+	return ""
+}
+
+// UnknownType stands for type expressions typeToString cannot render, such
+// as parenthesized types or computed array lengths
+const UnknownType = "unknown"
+
 // typeToString converts an AST type expression to a string representation
 func (p *Parser) typeToString(expr ast.Expr) string {
 	switch t := expr.(type) {
@@ -199,11 +483,86 @@ func (p *Parser) typeToString(expr ast.Expr) string {
 		return "map[" + p.typeToString(t.Key) + "]" + p.typeToString(t.Value)
 	case *ast.SelectorExpr:
 		return p.typeToString(t.X) + "." + t.Sel.Name
+	case *ast.IndexExpr:
+		// Instantiated generic types, e.g. Box[int]
+		return p.typeToString(t.X) + "[" + p.typeToString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		var args []string
+		for _, index := range t.Indices {
+			args = append(args, p.typeToString(index))
+		}
+		return p.typeToString(t.X) + "[" + strings.Join(args, ", ") + "]"
 	case *ast.InterfaceType:
+		// The any alias is kept as written, an identifier, and analyzed
+		// like interface{}
 		return "interface{}"
+	case *ast.BasicLit:
+		// Array lengths such as the 16 in [16]byte
+		return t.Value
+	case *ast.FuncType:
+		return "func" + p.funcTypeToString(t)
+	case *ast.Ellipsis:
+		return "..." + p.typeToString(t.Elt)
+	case *ast.StructType:
+		// Rendered on one line, e.g. struct{Host string; Port int}
+		var fields []string
+		for _, field := range t.Fields.List {
+			fieldType := p.typeToString(field.Type)
+			if field.Tag != nil {
+				fieldType += " " + field.Tag.Value
+			}
+			if len(field.Names) == 0 {
+				fields = append(fields, fieldType)
+			}
+			for _, name := range field.Names {
+				fields = append(fields, name.Name+" "+fieldType)
+			}
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + p.typeToString(t.Value)
+		case ast.RECV:
+			return "<-chan " + p.typeToString(t.Value)
+		default:
+			return "chan " + p.typeToString(t.Value)
+		}
 	default:
-		return "unknown"
+		return UnknownType
+	}
+}
+
+// funcTypeToString renders the parameters and results of a function type
+// without their names, e.g. (string, int) (bool, error)
+func (p *Parser) funcTypeToString(t *ast.FuncType) string {
+	signature := "(" + strings.Join(p.fieldListTypes(t.Params), ", ") + ")"
+
+	results := p.fieldListTypes(t.Results)
+	switch {
+	case len(results) == 1:
+		signature += " " + results[0]
+	case len(results) > 1:
+		signature += " (" + strings.Join(results, ", ") + ")"
 	}
+	return signature
+}
+
+// fieldListTypes returns the type of each entry of a parameter or result
+// list, repeating the type of grouped names such as a, b int
+func (p *Parser) fieldListTypes(list *ast.FieldList) []string {
+	if list == nil {
+		return nil
+	}
+
+	var fieldTypes []string
+	for _, field := range list.List {
+		fieldType := p.typeToString(field.Type)
+		for range max(len(field.Names), 1) {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+	}
+	return fieldTypes
 }
 
 // isPointerType checks if a type expression represents a pointer type
@@ -212,31 +571,44 @@ func (p *Parser) isPointerType(expr ast.Expr) bool {
 	return ok
 }
 
-// extractLogTag extracts the value of the log struct tag
+// extractLogTag extracts the value of the log struct tag, following the
+// reflect.StructTag conventions for quoting and multiple keys
 func (p *Parser) extractLogTag(tagValue string) string {
-	// Remove backticks from tag value
-	if len(tagValue) >= 2 && tagValue[0] == '`' && tagValue[len(tagValue)-1] == '`' {
-		tagValue = tagValue[1 : len(tagValue)-1]
+	tagValue = strings.Trim(tagValue, "`")
+
+	value, _ := reflect.StructTag(tagValue).Lookup("log")
+	return value
+}
+
+// extractJSONName extracts the name from the json struct tag, dropping options
+// such as omitempty. Fields excluded from JSON with json:"-" have no name.
+func (p *Parser) extractJSONName(tagValue string) string {
+	tagValue = strings.Trim(tagValue, "`")
+
+	value := reflect.StructTag(tagValue).Get("json")
+	if value == "-" {
+		return ""
 	}
 	
-	// Parse the tag to find the log tag
-	// Simple parsing - look for log:"value"
-	parts := strings.Split(tagValue, " ")
-	for _, part := range parts {
-		if strings.HasPrefix(part, "log:") {
-			// Extract the value between quotes
-			colonIndex := strings.Index(part, ":")
-			if colonIndex >= 0 && colonIndex < len(part)-1 {
-				value := part[colonIndex+1:]
-				// Remove quotes
-				if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
-					return value[1 : len(value)-1]
-				}
-			}
+	name, _, _ := strings.Cut(value, ",")
+	return name
+}
+
+// FilterByName returns the structs with the given name, or an error when no
+// struct with that name was found
+func FilterByName(structs []StructInfo, name string) ([]StructInfo, error) {
+	var filtered []StructInfo
+	for _, s := range structs {
+		if s.Name == name {
+			filtered = append(filtered, s)
 		}
 	}
 	
-	return ""
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("type %s not found among structs with //go:generate oak or //oak:generate directive", name)
+	}
+	
+	return filtered, nil
 }
 
 // GetAbsolutePath returns the absolute path for a given file path
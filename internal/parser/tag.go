@@ -0,0 +1,71 @@
+package parser
+
+import "strings"
+
+// LogTagOptions represents the parsed options of a log struct tag. Options are
+// comma-separated, so log:"redact,name=pw" both redacts the field and logs it
+// under the key "pw".
+type LogTagOptions struct {
+	Skip   bool   // log:"-" excludes the field from logs
+	Log    bool   // log:"log" opts the field into logs when the allowlist is configured
+	Redact bool   // log:"redact" replaces the value with the redact message
+	Mask   bool   // log:"mask" hides all but the last few characters
+	Hash   bool   // log:"hash" replaces the value with a stable hash of it
+	Name   string // log:"name=..." overrides the attribute key
+	Func   string // log:"func=..." forces the slog function logging the field, e.g. slog.Duration
+	Bool   string // log:"bool=..." logs a bool as one of two strings, e.g. enabled/disabled
+	Raw    string // The raw log tag value
+
+	OmitZero bool // log:"omitzero" omits the field when it holds its zero value
+	Always   bool // log:"always" logs the field even when omitZero is configured
+
+	Unknown []string // Options that are not recognized, such as a misspelled redact
+}
+
+// ParseLogTag parses a log tag value into its structured options. Unknown
+// options are collected rather than ignored, so that a misspelled option such
+// as redcat can be reported instead of logging the field in clear text.
+func ParseLogTag(value string) LogTagOptions {
+	options := LogTagOptions{Raw: value}
+	if value == "" {
+		return options
+	}
+
+	for _, option := range strings.Split(value, ",") {
+		option = strings.TrimSpace(option)
+
+		if name, ok := strings.CutPrefix(option, "name="); ok {
+			options.Name = name
+			continue
+		}
+		if function, ok := strings.CutPrefix(option, "func="); ok {
+			options.Func = function
+			continue
+		}
+		if strs, ok := strings.CutPrefix(option, "bool="); ok {
+			options.Bool = strs
+			continue
+		}
+
+		switch option {
+		case "-":
+			options.Skip = true
+		case "log":
+			options.Log = true
+		case "redact":
+			options.Redact = true
+		case "mask":
+			options.Mask = true
+		case "hash":
+			options.Hash = true
+		case "omitzero":
+			options.OmitZero = true
+		case "always":
+			options.Always = true
+		default:
+			options.Unknown = append(options.Unknown, option)
+		}
+	}
+
+	return options
+}
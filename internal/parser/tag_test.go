@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLogTag(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected LogTagOptions
+	}{
+		{
+			name:     "empty tag",
+			value:    "",
+			expected: LogTagOptions{},
+		},
+		{
+			name:     "bare skip",
+			value:    "-",
+			expected: LogTagOptions{Skip: true, Raw: "-"},
+		},
+		{
+			name:     "bare redact",
+			value:    "redact",
+			expected: LogTagOptions{Redact: true, Raw: "redact"},
+		},
+		{
+			name:     "bare mask",
+			value:    "mask",
+			expected: LogTagOptions{Mask: true, Raw: "mask"},
+		},
+		{
+			name:     "name only",
+			value:    "name=guest_name",
+			expected: LogTagOptions{Name: "guest_name", Raw: "name=guest_name"},
+		},
+		{
+			name:     "redact with name",
+			value:    "redact,name=pw",
+			expected: LogTagOptions{Redact: true, Name: "pw", Raw: "redact,name=pw"},
+		},
+		{
+			name:     "name before redact",
+			value:    "name=pw,redact",
+			expected: LogTagOptions{Redact: true, Name: "pw", Raw: "name=pw,redact"},
+		},
+		{
+			name:     "mask with name and spaces",
+			value:    "mask, name=card",
+			expected: LogTagOptions{Mask: true, Name: "card", Raw: "mask, name=card"},
+		},
+		{
+			name:     "omitzero with name",
+			value:    "omitzero,name=note",
+			expected: LogTagOptions{OmitZero: true, Name: "note", Raw: "omitzero,name=note"},
+		},
+		{
+			name:     "always",
+			value:    "always",
+			expected: LogTagOptions{Always: true, Raw: "always"},
+		},
+		{
+			name:     "hash",
+			value:    "hash",
+			expected: LogTagOptions{Hash: true, Raw: "hash"},
+		},
+		{
+			name:     "log with name",
+			value:    "log,name=email",
+			expected: LogTagOptions{Log: true, Name: "email", Raw: "log,name=email"},
+		},
+		{
+			name:     "func with name",
+			value:    "func=slog.Duration,name=timeout",
+			expected: LogTagOptions{Func: "slog.Duration", Name: "timeout", Raw: "func=slog.Duration,name=timeout"},
+		},
+		{
+			name:     "bool strings",
+			value:    "bool=enabled/disabled",
+			expected: LogTagOptions{Bool: "enabled/disabled", Raw: "bool=enabled/disabled"},
+		},
+		{
+			name:     "unknown options collected",
+			value:    "redcat, future",
+			expected: LogTagOptions{Unknown: []string{"redcat", "future"}, Raw: "redcat, future"},
+		},
+		{
+			name:     "empty name",
+			value:    "name=",
+			expected: LogTagOptions{Raw: "name="},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := ParseLogTag(tc.value)
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("ParseLogTag(%q) = %+v, expected %+v", tc.value, result, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// HasTypeInfo reports whether the field's type was resolved by LoadPackages
+func (f FieldInfo) HasTypeInfo() bool {
+	return f.TypeInfo != nil
+}
+
+// ImplementsLogValuer reports whether the field's type, or a pointer to it,
+// implements slog.LogValuer. It is always false without type information.
+func (f FieldInfo) ImplementsLogValuer() bool {
+	return f.implementsMethod("LogValue", "log/slog.Value")
+}
+
+// PointerLogValuer reports whether slog.LogValuer is implemented by a pointer
+// to the field's type but not by the type itself, as for LogValue methods
+// with pointer receivers, so values must be logged through their address
+func (f FieldInfo) PointerLogValuer() bool {
+	if f.TypeInfo == nil {
+		return false
+	}
+	return !hasMethod(f.TypeInfo, "LogValue", "log/slog.Value") &&
+		hasMethod(types.NewPointer(f.TypeInfo), "LogValue", "log/slog.Value")
+}
+
+// ImplementsStringer reports whether the field's type, or a pointer to it,
+// implements fmt.Stringer. It is always false without type information.
+func (f FieldInfo) ImplementsStringer() bool {
+	return f.implementsMethod("String", "string")
+}
+
+// implementsMethod reports whether the field's method set (including that of
+// a pointer to it) has a niladic method with the given name and result type
+func (f FieldInfo) implementsMethod(name, result string) bool {
+	if f.TypeInfo == nil {
+		return false
+	}
+
+	return hasMethod(f.TypeInfo, name, result) || hasMethod(types.NewPointer(f.TypeInfo), name, result)
+}
+
+// hasMethod reports whether the method set of t has a niladic method with the
+// given name and result type
+func hasMethod(t types.Type, name, result string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, name)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	signature := fn.Type().(*types.Signature)
+	return signature.Params().Len() == 0 && signature.Results().Len() == 1 &&
+		signature.Results().At(0).Type().String() == result
+}
+
+// EnumConstant is a named constant of a field's integer type
+type EnumConstant struct {
+	Name string // Constant identifier, e.g. "Active"
+	Expr string // Expression referring to the constant from the struct's package, e.g. "models.Active"
+}
+
+// EnumConstants returns the constants declared alongside the field's defined
+// integer type, in declaration order, along with the import path needed to
+// refer to them from the struct's package (empty for the same package). Only
+// the first constant of each value is returned, so the constants can be used
+// as switch cases. It returns nothing without type information, for types
+// that are not integer-based, or for types of other packages imported under
+// a different name.
+func (f FieldInfo) EnumConstants() ([]EnumConstant, string) {
+	if f.TypeInfo == nil {
+		return nil, ""
+	}
+
+	fieldType := f.TypeInfo
+	if pointer, ok := fieldType.(*types.Pointer); ok {
+		fieldType = pointer.Elem()
+	}
+	named, ok := types.Unalias(fieldType).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil, ""
+	}
+	if basic, ok := named.Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+		return nil, ""
+	}
+
+	// A qualified field type refers to another package by its package name
+	pkg := named.Obj().Pkg()
+	qualifier, importPath := "", ""
+	if name, _, ok := strings.Cut(strings.TrimPrefix(f.Type, "*"), "."); ok {
+		if name != pkg.Name() {
+			return nil, ""
+		}
+		qualifier, importPath = name+".", pkg.Path()
+	}
+
+	var constants []*types.Const
+	for _, name := range pkg.Scope().Names() {
+		constant, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if !ok || !types.Identical(constant.Type(), named) {
+			continue
+		}
+		if qualifier != "" && !constant.Exported() {
+			continue
+		}
+		constants = append(constants, constant)
+	}
+	sort.Slice(constants, func(i, j int) bool {
+		return constants[i].Pos() < constants[j].Pos()
+	})
+
+	var enum []EnumConstant
+	seen := make(map[string]bool)
+	for _, constant := range constants {
+		value := constant.Val().ExactString()
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		enum = append(enum, EnumConstant{Name: constant.Name(), Expr: qualifier + constant.Name()})
+	}
+
+	if len(enum) == 0 {
+		return nil, ""
+	}
+	return enum, importPath
+}
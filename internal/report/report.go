@@ -0,0 +1,137 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stuckinforloop/oak/internal/generator"
+	"github.com/stuckinforloop/oak/internal/types"
+)
+
+// Report is a machine-readable summary of a generation run. Structs are
+// sorted by output file and name, and fields keep their declaration order, so
+// reports for the same input are identical.
+type Report struct {
+	Totals  Totals   `json:"totals"`
+	Structs []Struct `json:"structs"`
+}
+
+// Totals counts the structs and fields across the whole run
+type Totals struct {
+	Structs int `json:"structs"`
+	Counts
+}
+
+// Counts tallies fields by the action taken for them
+type Counts struct {
+	Fields int `json:"fields"`
+	Log    int `json:"log"`
+	Redact int `json:"redact"`
+	Mask   int `json:"mask"`
+	Hash   int `json:"hash"`
+	Skip   int `json:"skip"`
+}
+
+// Struct describes a struct a LogValue method was generated for
+type Struct struct {
+	Package string  `json:"package"`
+	Name    string  `json:"name"`
+	Output  string  `json:"output"` // Generated file relative to the working directory
+	Counts  Counts  `json:"counts"`
+	Fields  []Field `json:"fields"`
+}
+
+// Field describes how a single field is logged
+type Field struct {
+	Name   string `json:"name"` // Dotted for fields hoisted from nested structs
+	Type   string `json:"type"`
+	Action string `json:"action"`
+}
+
+// Build summarizes generation results. Results without struct analyses, such
+// as generated benchmarks, are ignored.
+func Build(results []*generator.GenerationResult) *Report {
+	report := &Report{Structs: []Struct{}}
+
+	for _, result := range results {
+		for _, analysis := range result.Structs {
+			s := Struct{
+				Package: result.PackageName,
+				Name:    analysis.Name,
+				Output:  relativePath(result.FilePath),
+				Fields:  []Field{},
+			}
+
+			for _, field := range analysis.Fields {
+				s.Fields = append(s.Fields, Field{
+					Name:   strings.TrimPrefix(field.Parent+"."+field.Field.Name, "."),
+					Type:   field.Field.Type,
+					Action: field.Action.String(),
+				})
+				s.Counts.add(field.Action)
+				report.Totals.add(field.Action)
+			}
+
+			report.Structs = append(report.Structs, s)
+		}
+	}
+	report.Totals.Structs = len(report.Structs)
+
+	sort.SliceStable(report.Structs, func(i, j int) bool {
+		a, b := report.Structs[i], report.Structs[j]
+		if a.Output != b.Output {
+			return a.Output < b.Output
+		}
+		return a.Name < b.Name
+	})
+
+	return report
+}
+
+// add counts a field with the given action
+func (c *Counts) add(action types.FieldAction) {
+	c.Fields++
+	switch action {
+	case types.ActionLog:
+		c.Log++
+	case types.ActionRedact:
+		c.Redact++
+	case types.ActionMask:
+		c.Mask++
+	case types.ActionHash:
+		c.Hash++
+	case types.ActionSkip:
+		c.Skip++
+	}
+}
+
+// relativePath returns path relative to the working directory with forward
+// slashes, so reports do not depend on the loader or the checkout location
+func relativePath(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// Write writes the report to path as indented JSON
+func (r *Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stuckinforloop/oak/internal/config"
+	"github.com/stuckinforloop/oak/internal/generator"
+	"github.com/stuckinforloop/oak/internal/parser"
+)
+
+// generate runs the generator over structs sharing a package directory
+func generate(t *testing.T, cfg *config.Config, structs ...parser.StructInfo) *generator.GenerationResult {
+	t.Helper()
+
+	result, err := generator.New(cfg).GenerateForStructs(structs)
+	if err != nil {
+		t.Fatalf("GenerateForStructs failed: %v", err)
+	}
+	return result
+}
+
+func TestBuild(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+
+	billing := generate(t, cfg, parser.StructInfo{
+		Name:        "Invoice",
+		PackageName: "billing",
+		FilePath:    "billing/invoice.go",
+		Fields: []parser.FieldInfo{
+			{Name: "Total", Type: "float64"},
+		},
+	})
+	users := generate(t, cfg,
+		parser.StructInfo{
+			Name:        "User",
+			PackageName: "users",
+			FilePath:    "users/user.go",
+			Fields: []parser.FieldInfo{
+				{Name: "ID", Type: "int"},
+				{Name: "Password", Type: "string"},
+				{Name: "Card", Type: "string", LogOptions: parser.ParseLogTag("mask")},
+				{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			},
+		},
+		parser.StructInfo{
+			Name:        "Account",
+			PackageName: "users",
+			FilePath:    "users/user.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Owner", Type: "*User"},
+			},
+		},
+	)
+
+	// Results are given out of order; the report sorts them
+	report := Build([]*generator.GenerationResult{users, billing})
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := report.Write(path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	expected := `{
+  "totals": {
+    "structs": 3,
+    "fields": 6,
+    "log": 3,
+    "redact": 1,
+    "mask": 1,
+    "hash": 0,
+    "skip": 1
+  },
+  "structs": [
+    {
+      "package": "billing",
+      "name": "Invoice",
+      "output": "billing/oak_gen.go",
+      "counts": {
+        "fields": 1,
+        "log": 1,
+        "redact": 0,
+        "mask": 0,
+        "hash": 0,
+        "skip": 0
+      },
+      "fields": [
+        {
+          "name": "Total",
+          "type": "float64",
+          "action": "log"
+        }
+      ]
+    },
+    {
+      "package": "users",
+      "name": "Account",
+      "output": "users/oak_gen.go",
+      "counts": {
+        "fields": 1,
+        "log": 1,
+        "redact": 0,
+        "mask": 0,
+        "hash": 0,
+        "skip": 0
+      },
+      "fields": [
+        {
+          "name": "Owner",
+          "type": "*User",
+          "action": "log"
+        }
+      ]
+    },
+    {
+      "package": "users",
+      "name": "User",
+      "output": "users/oak_gen.go",
+      "counts": {
+        "fields": 4,
+        "log": 1,
+        "redact": 1,
+        "mask": 1,
+        "hash": 0,
+        "skip": 1
+      },
+      "fields": [
+        {
+          "name": "ID",
+          "type": "int",
+          "action": "log"
+        },
+        {
+          "name": "Password",
+          "type": "string",
+          "action": "redact"
+        },
+        {
+          "name": "Card",
+          "type": "string",
+          "action": "mask"
+        },
+        {
+          "name": "Notes",
+          "type": "string",
+          "action": "skip"
+        }
+      ]
+    }
+  ]
+}
+`
+	if string(data) != expected {
+		t.Errorf("Report mismatch:\nexpected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	// The report decodes strictly into the schema
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var decoded Report
+	if err := decoder.Decode(&decoded); err != nil {
+		t.Errorf("Report does not match schema: %v", err)
+	}
+}
+
+func TestBuildFlattenedFields(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OutputStyle = config.OutputStyleFlattened
+
+	result := generate(t, cfg,
+		parser.StructInfo{
+			Name:        "Order",
+			PackageName: "shop",
+			FilePath:    "shop/order.go",
+			Fields: []parser.FieldInfo{
+				{Name: "Address", Type: "Address"},
+			},
+		},
+		parser.StructInfo{
+			Name:        "Address",
+			PackageName: "shop",
+			FilePath:    "shop/order.go",
+			Fields: []parser.FieldInfo{
+				{Name: "City", Type: "string"},
+			},
+		},
+	)
+
+	report := Build([]*generator.GenerationResult{result})
+
+	var order *Struct
+	for i := range report.Structs {
+		if report.Structs[i].Name == "Order" {
+			order = &report.Structs[i]
+		}
+	}
+	if order == nil {
+		t.Fatalf("Expected Order in report, got %+v", report.Structs)
+	}
+
+	if len(order.Fields) != 1 || order.Fields[0].Name != "Address.City" {
+		t.Errorf("Expected hoisted field Address.City, got %+v", order.Fields)
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	data, err := json.Marshal(Build(nil))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `{"totals":{"structs":0,"fields":0,"log":0,"redact":0,"mask":0,"hash":0,"skip":0},"structs":[]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
@@ -0,0 +1,461 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stuckinforloop/oak/internal/config"
+	"github.com/stuckinforloop/oak/internal/parser"
+)
+
+// attrDialect describes how a logging library builds key-value fields, for
+// libraries whose fields are values returned by constructors such as
+// slog.String or zap.String. Constructors are named as in log/slog.
+type attrDialect struct {
+	pkg        string // Package qualifying field constructors, e.g. "slog"
+	fieldType  string // Type of a field, e.g. "slog.Attr"
+	empty      string // Field omitted from output
+	group      string // Format of a group from a key and a list of fields
+	groupSlice string // Format of a group from a key and a slice of fields
+	nested     string // Format of a generated struct from a key expression and value
+	nestedNext string // Format of a recursive generated struct one level deeper
+	formatter  string // Format of a custom formatter call from a key, function, and value
+}
+
+// fn returns the dialect's counterpart of a slog field constructor
+func (d attrDialect) fn(slogFunc SlogFunction) string {
+	return d.pkg + strings.TrimPrefix(string(slogFunc), "slog")
+}
+
+// attrEmitter generates field expressions for libraries described by an
+// attrDialect
+type attrEmitter struct {
+	analyzer *TypeAnalyzer
+	dialect  attrDialect
+	slice    string // Slice fields are appended to by statements; empty for expressions
+}
+
+// Field returns the expression logging a field, or with a slice the
+// statements appending it. Fields hoisted through nil pointers produce the
+// dialect's empty field, which is omitted from output, or are not appended.
+func (e attrEmitter) Field(analysis FieldAnalysis, receiverName string) string {
+	statement := e.generateStatement(analysis, receiverName)
+	if statement == "" {
+		return statement
+	}
+
+	if analysis.OmitZero {
+		if isZero := zeroCheck(analysis.Field, e.analyzer.getFieldAccessor(analysis, receiverName)); isZero != "" {
+			statement = e.conditional(isZero, e.dialect.empty, statement)
+		}
+	}
+
+	if len(analysis.Guards) > 0 {
+		var nilChecks []string
+		for _, guard := range analysis.Guards {
+			nilChecks = append(nilChecks, receiverName+guard+" == nil")
+		}
+		statement = e.conditional(strings.Join(nilChecks, " || "), e.dialect.empty, statement)
+	}
+
+	if e.slice != "" {
+		return e.appendField(statement)
+	}
+	return statement
+}
+
+// conditional returns code logging then when cond holds and otherwise
+// otherwise. Without a slice this is a closure returning either field;
+// with one it is an if statement appending them, where the empty field
+// appends nothing.
+func (e attrEmitter) conditional(cond, then, otherwise string) string {
+	if e.slice == "" {
+		return fmt.Sprintf(`func() %s {
+if %s {
+return %s
+}
+return %s
+}()`, e.dialect.fieldType, cond, then, otherwise)
+	}
+
+	if then == e.dialect.empty {
+		return fmt.Sprintf("if %s {\n%s\n}", negate(cond), e.appendField(otherwise))
+	}
+	if strings.HasPrefix(otherwise, "if ") {
+		return fmt.Sprintf("if %s {\n%s\n} else %s", cond, e.appendField(then), otherwise)
+	}
+	return fmt.Sprintf("if %s {\n%s\n} else {\n%s\n}", cond, e.appendField(then), e.appendField(otherwise))
+}
+
+// appendField returns the statement appending a field expression to the
+// slice. Statements built by conditional are returned unchanged.
+func (e attrEmitter) appendField(statement string) string {
+	if strings.HasPrefix(statement, "if ") {
+		return statement
+	}
+	return fmt.Sprintf("%[1]s = append(%[1]s, %[2]s)", e.slice, statement)
+}
+
+// negate returns the negation of a condition built by the emitter: a
+// disjunction of comparisons, negations, and boolean method calls
+func negate(cond string) string {
+	terms := strings.Split(cond, " || ")
+	for i, term := range terms {
+		switch {
+		case strings.Contains(term, " == "):
+			terms[i] = strings.Replace(term, " == ", " != ", 1)
+		case strings.Contains(term, " >= "):
+			terms[i] = strings.Replace(term, " >= ", " < ", 1)
+		case strings.HasPrefix(term, "!"):
+			terms[i] = term[1:]
+		default:
+			terms[i] = "!" + term
+		}
+	}
+	return strings.Join(terms, " && ")
+}
+
+// generateStatement generates the statement for a field according to its action
+func (e attrEmitter) generateStatement(analysis FieldAnalysis, receiverName string) string {
+	key := e.analyzer.attributeKey(analysis)
+
+	switch analysis.Action {
+	case ActionSkip:
+		return "" // Field should not appear in log output
+
+	case ActionRedact:
+		return fmt.Sprintf(`%s(%q, %q)`, e.dialect.fn(analysis.SlogFunc), key, analysis.LogValue)
+
+	case ActionMask:
+		return e.generateMaskStatement(analysis, receiverName)
+
+	case ActionHash:
+		fieldAccessor := e.analyzer.getFieldAccessor(analysis, receiverName)
+		return e.nilSafe(analysis, fieldAccessor, key, fmt.Sprintf(`%s(%q, %s)`,
+			e.dialect.fn(SlogString), key, e.analyzer.hashValue(analysis.Field, fieldAccessor)))
+
+	case ActionLog:
+		return e.generateNormalLogStatement(analysis, receiverName)
+
+	default:
+		return fmt.Sprintf(`%s(%q, %s)`, e.dialect.fn(SlogAny), key, e.analyzer.getFieldAccessor(analysis, receiverName))
+	}
+}
+
+// generateNormalLogStatement generates a normal (non-redacted) log statement
+func (e attrEmitter) generateNormalLogStatement(analysis FieldAnalysis, receiverName string) string {
+	ta := e.analyzer
+	key := ta.attributeKey(analysis)
+	fieldAccessor := ta.getFieldAccessor(analysis, receiverName)
+	fn := e.dialect.fn(analysis.SlogFunc)
+
+	if analysis.Formatter != "" {
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(e.dialect.formatter, key, analysis.Formatter, ta.deref(analysis.Field, fieldAccessor)))
+	}
+
+	if analysis.Forced != "" {
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(`%s(%q, %s)`, fn, key, forcedArg(analysis, ta.deref(analysis.Field, fieldAccessor))))
+	}
+
+	if analysis.BoolText != [2]string{} {
+		return e.nilSafe(analysis, fieldAccessor, key, e.conditional(ta.deref(analysis.Field, fieldAccessor),
+			fmt.Sprintf(`%s(%q, %q)`, fn, key, analysis.BoolText[0]),
+			fmt.Sprintf(`%s(%q, %q)`, fn, key, analysis.BoolText[1])))
+	}
+
+	if analysis.Stringer {
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(`%s(%q, %s.String())`, e.dialect.fn(SlogString), key, fieldAccessor))
+	}
+
+	if len(analysis.Enum) > 0 {
+		return e.nilSafe(analysis, fieldAccessor, key,
+			e.generateEnumStatement(analysis.Enum, key, ta.deref(analysis.Field, fieldAccessor)))
+	}
+
+	// database/sql Null types log their value when valid and "null" otherwise
+	if isSQLNullType(strings.TrimPrefix(analysis.Field.Type, "*")) {
+		return e.nilSafe(analysis, fieldAccessor, key, e.conditional("!"+fieldAccessor+".Valid",
+			fmt.Sprintf(`%s(%q, "null")`, e.dialect.fn(SlogString), key),
+			fmt.Sprintf(`%s(%q, %s)`, fn, key, ta.sqlNullArg(analysis, fieldAccessor))))
+	}
+
+	switch analysis.SlogFunc {
+	case SlogInt64:
+		if analysis.Field.IsPointer {
+			// For pointer types, we need to handle nil case and convert to int64
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, int64(*%s))`, fn, key, fieldAccessor))
+		}
+		// For non-pointer integer types, convert to int64
+		if analysis.Field.Type != "int64" {
+			return fmt.Sprintf(`%s(%q, int64(%s))`, fn, key, fieldAccessor)
+		}
+		return fmt.Sprintf(`%s(%q, %s)`, fn, key, fieldAccessor)
+
+	case SlogUint64:
+		// uintptr is the only type logged as an unsigned integer
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(`%s(%q, uint64(%s))`, fn, key, ta.deref(analysis.Field, fieldAccessor)))
+
+	case SlogFloat64:
+		if analysis.Field.IsPointer {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, float64(*%s))`, fn, key, fieldAccessor))
+		}
+		// For non-pointer float types, convert to float64
+		if analysis.Field.Type != "float64" {
+			return fmt.Sprintf(`%s(%q, float64(%s))`, fn, key, fieldAccessor)
+		}
+		return fmt.Sprintf(`%s(%q, %s)`, fn, key, fieldAccessor)
+
+	case SlogString, SlogBool:
+		fieldType := strings.TrimPrefix(analysis.Field.Type, "*")
+		str := e.dialect.fn(SlogString)
+		if IsByteArrayType(fieldType) {
+			// Slicing a pointer to an array needs no explicit dereference
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, hex.EncodeToString(%s[:]))`, str, key, fieldAccessor))
+		}
+		if description, ok := opaqueDescription(fieldType); ok {
+			return e.nilSafe(analysis, fieldAccessor, key, fmt.Sprintf(`func() %s {
+if %s == nil {
+return %s(%q, "null")
+}
+return %s(%q, %q)
+}()`, e.dialect.fieldType, ta.deref(analysis.Field, fieldAccessor), str, key, str, key, description))
+		}
+		if isStringerType(fieldType) {
+			// String has a pointer receiver for net.IPNet, big.Int, and
+			// big.Float, which fields are addressable for
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, %s.String())`, str, key, fieldAccessor))
+		}
+		if fieldType == rawMessageType {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, string(%s))`, str, key, ta.deref(analysis.Field, fieldAccessor)))
+		}
+		if isByteSliceType(fieldType) {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, base64.StdEncoding.EncodeToString(%s))`, str, key, ta.deref(analysis.Field, fieldAccessor)))
+		}
+		if fieldType == "time.Time" {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, %s.Format(%s))`, str, key, fieldAccessor, ta.timeLayout()))
+		}
+		if fieldType == "time.Duration" {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, %s.String())`, str, key, fieldAccessor))
+		}
+		if fieldType == "unsafe.Pointer" {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, fmt.Sprintf("%%p", %s))`, str, key, ta.deref(analysis.Field, fieldAccessor)))
+		}
+		if analysis.Field.IsPointer {
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(`%s(%q, *%s)`, fn, key, fieldAccessor))
+		}
+		return fmt.Sprintf(`%s(%q, %s)`, fn, key, fieldAccessor)
+
+	case SlogTime, SlogDuration:
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(`%s(%q, %s)`, fn, key, ta.deref(analysis.Field, fieldAccessor)))
+
+	case SlogAny:
+		if analysis.MapValue != nil || analysis.SortKeys {
+			return e.generateMapStatement(analysis, receiverName)
+		}
+		if analysis.Nested != nil {
+			// Generated structs are logged as a group via their generated method
+			if analysis.Recursive {
+				return e.nilSafe(analysis, fieldAccessor, key,
+					e.depthGuard(key, fmt.Sprintf(e.dialect.nestedNext, fmt.Sprintf("%q", key), fieldAccessor)))
+			}
+			return e.nilSafe(analysis, fieldAccessor, key,
+				fmt.Sprintf(e.dialect.nested, fmt.Sprintf("%q", key), fieldAccessor))
+		}
+		if len(analysis.Field.Fields) > 0 {
+			return e.nilSafe(analysis, fieldAccessor, key, e.generateInlineStatement(analysis, receiverName))
+		}
+		if analysis.LogValuer {
+			// slog.Any resolves values through their LogValue method; pointers
+			// are passed as is, and values by address when the method has a
+			// pointer receiver, so the method is found
+			value := fieldAccessor
+			if !analysis.Field.IsPointer && analysis.Field.PointerLogValuer() {
+				value = "&" + fieldAccessor
+			}
+			return e.nilSafe(analysis, fieldAccessor, key, fmt.Sprintf(`%s(%q, %s)`, fn, key, value))
+		}
+		// Pointers to slices, maps, and other values log the pointed-to value
+		// rather than the pointer, which slog.Any would not dereference
+		value := ta.deref(analysis.Field, fieldAccessor)
+		if !isInterfaceField(analysis.Field) {
+			return e.nilSafe(analysis, fieldAccessor, key, fmt.Sprintf(`%s(%q, %s)`, fn, key, ta.capSlice(analysis, value)))
+		}
+
+		statement := fmt.Sprintf(`%s(%q, %s)`, fn, key, value)
+		if ta.config.LogInterfaceTypes {
+			statement = fmt.Sprintf(e.dialect.group, key, fmt.Sprintf(`%s("type", fmt.Sprintf("%%T", %s)), %s("value", %s)`,
+				e.dialect.fn(SlogString), value, fn, value))
+		}
+		// Interfaces holding a nil pointer log its type instead, since the
+		// logger may call methods such as Error or LogValue on the pointer
+		return e.nilSafe(analysis, fieldAccessor, key, e.conditional(typedNil(value),
+			fmt.Sprintf(`%s(%q, fmt.Sprintf("%%T(nil)", %s))`, e.dialect.fn(SlogString), key, value), statement))
+
+	default:
+		return fmt.Sprintf(`%s(%q, %s)`, e.dialect.fn(SlogAny), key, fieldAccessor)
+	}
+}
+
+// generateInlineStatement generates a group holding the fields of an inline
+// anonymous struct
+func (e attrEmitter) generateInlineStatement(analysis FieldAnalysis, receiverName string) string {
+	// Fields of the group are expressions, even with a slice
+	group := attrEmitter{analyzer: e.analyzer, dialect: e.dialect}
+
+	var attrs strings.Builder
+	for _, child := range analysis.Inline {
+		child.Parent = analysis.Parent + "." + analysis.Field.Name
+		fmt.Fprintf(&attrs, "\n%s,", group.Field(child, receiverName))
+	}
+	return fmt.Sprintf(e.dialect.group, e.analyzer.attributeKey(analysis), attrs.String()+"\n")
+}
+
+// generateEnumStatement generates a switch logging the name of the constant
+// matching value, falling back to the integer for values without a constant
+func (e attrEmitter) generateEnumStatement(constants []parser.EnumConstant, key, value string) string {
+	str := e.dialect.fn(SlogString)
+
+	var cases strings.Builder
+	for _, constant := range constants {
+		fmt.Fprintf(&cases, "case %s:\nreturn %s(%q, %q)\n", constant.Expr, str, key, constant.Name)
+	}
+
+	return fmt.Sprintf(`func() %s {
+switch %s {
+%s}
+return %s(%q, int64(%s))
+}()`, e.dialect.fieldType, value, cases.String(), e.dialect.fn(SlogInt64), key, value)
+}
+
+// generateMapStatement generates a group holding a field per entry of a map
+// of generated structs, or of any map when sorting keys (or a pointer to
+// one), keyed by the stringified map key. Nil maps, nil map pointers, and nil
+// generated struct pointers log "null".
+func (e attrEmitter) generateMapStatement(analysis FieldAnalysis, receiverName string) string {
+	key := e.analyzer.attributeKey(analysis)
+	fieldAccessor := e.analyzer.getFieldAccessor(analysis, receiverName)
+	keyType, valueType, _ := splitMapType(strings.TrimPrefix(analysis.Field.Type, "*"))
+	str := e.dialect.fn(SlogString)
+
+	// A pointer to a map is nil-checked before the map itself
+	mapValue := fieldAccessor
+	if analysis.Field.IsPointer {
+		mapValue = "(*" + fieldAccessor + ")"
+	}
+
+	entryKey := "k"
+	if keyType != "string" {
+		entryKey = "fmt.Sprint(k)"
+	}
+
+	nilValue := ""
+	if analysis.MapValue != nil && strings.HasPrefix(valueType, "*") {
+		nilValue = fmt.Sprintf(`if v == nil {
+attrs = append(attrs, %s(%s, "null"))
+continue
+}
+`, str, entryKey)
+	}
+
+	entry := fmt.Sprintf(`%s(%s, v)`, e.dialect.fn(SlogAny), entryKey)
+	switch {
+	case analysis.MapValue != nil && analysis.Recursive:
+		entry = fmt.Sprintf(e.dialect.nestedNext, entryKey, "v")
+	case analysis.MapValue != nil:
+		entry = fmt.Sprintf(e.dialect.nested, entryKey, "v")
+	}
+
+	statement := fmt.Sprintf(`func() %s {
+if %s == nil {
+return %s(%q, "null")
+}
+attrs := make([]%s, 0, len(%s))
+%s
+%sattrs = append(attrs, %s)
+}
+return %s
+}()`, e.dialect.fieldType, mapValue, str, key, e.dialect.fieldType, mapValue,
+		rangeMap(mapValue, keyType, analysis.SortKeys), nilValue, entry, fmt.Sprintf(e.dialect.groupSlice, key, "attrs"))
+	if analysis.Recursive {
+		statement = e.depthGuard(key, statement)
+	}
+
+	return e.nilSafe(analysis, fieldAccessor, key, statement)
+}
+
+// generateMaskStatement generates a log statement that masks all but the last
+// few characters of a string field
+func (e attrEmitter) generateMaskStatement(analysis FieldAnalysis, receiverName string) string {
+	key := e.analyzer.attributeKey(analysis)
+	fieldAccessor := e.analyzer.getFieldAccessor(analysis, receiverName)
+	str := e.dialect.fn(SlogString)
+
+	// Nil pointers log "null" within the closure, unless another nilBehavior
+	// handles them around it
+	nilBehavior := e.analyzer.config.NilBehavior
+	wrap := analysis.Field.IsPointer && nilBehavior != "" && nilBehavior != config.NilBehaviorNull
+
+	nilCheck := ""
+	if analysis.Field.IsPointer && !wrap {
+		nilCheck = fmt.Sprintf(`if %s == nil {
+return %s(%q, "null")
+}
+`, fieldAccessor, str, key)
+	}
+
+	statement := fmt.Sprintf(`func() %s {
+%sv := []rune(%s)
+if len(v) <= %d {
+return %s(%q, strings.Repeat("*", len(v)))
+}
+return %s(%q, strings.Repeat("*", len(v)-%d)+string(v[len(v)-%d:]))
+}()`, e.dialect.fieldType, nilCheck, e.analyzer.deref(analysis.Field, fieldAccessor), maskVisibleChars,
+		str, key, str, key, maskVisibleChars, maskVisibleChars)
+	if wrap {
+		return e.nilSafe(analysis, fieldAccessor, key, statement)
+	}
+	return statement
+}
+
+// depthGuard wraps a statement logging recursive structs so that, at the
+// configured depth limit, the field logs a marker instead
+func (e attrEmitter) depthGuard(key, statement string) string {
+	return e.conditional(fmt.Sprintf("depth >= %d", e.analyzer.maxDepth()),
+		fmt.Sprintf(`%s(%q, %q)`, e.dialect.fn(SlogString), key, maxDepthValue), statement)
+}
+
+// nilSafe wraps a statement for a pointer field so nil pointers log "null",
+// are omitted, or log the zero value of their type, per nilBehavior
+func (e attrEmitter) nilSafe(analysis FieldAnalysis, fieldAccessor, key, statement string) string {
+	if !analysis.Field.IsPointer {
+		return statement
+	}
+
+	then := fmt.Sprintf(`%s(%q, "null")`, e.dialect.fn(SlogString), key)
+	switch e.analyzer.config.NilBehavior {
+	case config.NilBehaviorOmit:
+		then = e.dialect.empty
+	case config.NilBehaviorZero:
+		if fn, value, ok := pointeeZero(analysis); ok && analysis.Nested != nil {
+			then = fmt.Sprintf(e.dialect.nested, fmt.Sprintf("%q", key), value)
+		} else if ok {
+			then = fmt.Sprintf(`%s(%q, %s)`, e.dialect.fn(fn), key, value)
+		}
+	}
+	return e.conditional(fieldAccessor+" == nil", then, statement)
+}
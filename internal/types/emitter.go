@@ -0,0 +1,12 @@
+package types
+
+// Emitter turns field analyses into code for a logging backend, so that the
+// analysis of how each field is logged (skipped, redacted, masked, nested) is
+// shared between backends
+type Emitter interface {
+	// Field returns the code logging a field within a generated method, or an
+	// empty string if the field is omitted. Depending on the backend this is
+	// an expression building a field (slog, zap) or a method call chained onto
+	// an event (zerolog); the backend's template composes the results.
+	Field(analysis FieldAnalysis, receiverName string) string
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/stuckinforloop/oak/internal/config"
+)
+
+// ConvertKeyCase converts a Go field name into an attribute key using the
+// given casing style. Acronyms are kept together, so UserID becomes user_id
+// and HTTPServer becomes http_server.
+func ConvertKeyCase(name, keyCase string) string {
+	switch keyCase {
+	case config.KeyCaseSnake:
+		return strings.ToLower(strings.Join(splitWords(name), "_"))
+	case config.KeyCaseKebab:
+		return strings.ToLower(strings.Join(splitWords(name), "-"))
+	case config.KeyCaseCamel:
+		words := splitWords(name)
+		for i, word := range words {
+			word = strings.ToLower(word)
+			if i > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+			words[i] = word
+		}
+		return strings.Join(words, "")
+	default:
+		return name
+	}
+}
+
+// splitWords splits an identifier into words at case changes and at
+// underscore or hyphen separators. A run of capitals is treated as one word,
+// except that its last capital starts a new word when followed by a lowercase
+// letter (HTTPServer splits into HTTP and Server). A trailing plural "s" stays
+// with its acronym (UserIDs splits into User and IDs). Digits stay attached to
+// the preceding word.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		prev := runes[i-1]
+		nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !isPluralSuffix(runes, i+1)
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// isPluralSuffix reports whether the rune at i is a lone "s" ending a word
+func isPluralSuffix(runes []rune, i int) bool {
+	if runes[i] != 's' {
+		return false
+	}
+	return i+1 == len(runes) || !unicode.IsLower(runes[i+1])
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stuckinforloop/oak/internal/config"
+)
+
+func TestConvertKeyCase(t *testing.T) {
+	testCases := []struct {
+		name     string
+		keyCase  string
+		expected string
+	}{
+		// As-is keeps the field name verbatim
+		{"UserID", config.KeyCaseAsIs, "UserID"},
+		{"GuestName", "", "GuestName"},
+
+		// Snake case
+		{"GuestName", config.KeyCaseSnake, "guest_name"},
+		{"UserID", config.KeyCaseSnake, "user_id"},
+		{"ID", config.KeyCaseSnake, "id"},
+		{"HTTPServer", config.KeyCaseSnake, "http_server"},
+		{"APIKey", config.KeyCaseSnake, "api_key"},
+		{"UserIDs", config.KeyCaseSnake, "user_ids"},
+		{"URLsByHost", config.KeyCaseSnake, "urls_by_host"},
+		{"Address2", config.KeyCaseSnake, "address2"},
+		{"already_snake", config.KeyCaseSnake, "already_snake"},
+		{"name", config.KeyCaseSnake, "name"},
+
+		// Camel case
+		{"GuestName", config.KeyCaseCamel, "guestName"},
+		{"UserID", config.KeyCaseCamel, "userId"},
+		{"ID", config.KeyCaseCamel, "id"},
+		{"HTTPServer", config.KeyCaseCamel, "httpServer"},
+		{"APIKey", config.KeyCaseCamel, "apiKey"},
+
+		// Kebab case
+		{"GuestName", config.KeyCaseKebab, "guest-name"},
+		{"UserID", config.KeyCaseKebab, "user-id"},
+		{"HTTPServer", config.KeyCaseKebab, "http-server"},
+	}
+
+	for _, tc := range testCases {
+		result := ConvertKeyCase(tc.name, tc.keyCase)
+		if result != tc.expected {
+			t.Errorf("ConvertKeyCase(%s, %s) = %s, expected %s", tc.name, tc.keyCase, result, tc.expected)
+		}
+	}
+}
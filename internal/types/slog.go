@@ -0,0 +1,60 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/stuckinforloop/oak/internal/config"
+)
+
+// slogDialect builds log/slog attributes
+var slogDialect = attrDialect{
+	pkg:        "slog",
+	fieldType:  "slog.Attr",
+	empty:      "slog.Attr{}",
+	group:      `slog.Group(%q, %s)`,
+	groupSlice: `slog.Attr{Key: %q, Value: slog.GroupValue(%s...)}`,
+	nested:     `slog.Attr{Key: %s, Value: %s.LogValue()}`,
+	nestedNext: `slog.Attr{Key: %s, Value: %s.logValue(depth + 1)}`,
+	formatter:  `%[2]s(%[1]q, %[3]s)`,
+}
+
+// SlogAttrs is the slice LogValue methods append attributes to with the
+// slice style
+const SlogAttrs = "attrs"
+
+// AppendedAttr returns the attribute appended by a statement generated with
+// the slice style, or false for statements appending conditionally or more
+// than once
+func AppendedAttr(statement string) (string, bool) {
+	prefix := SlogAttrs + " = append(" + SlogAttrs + ", "
+	attr, ok := strings.CutPrefix(statement, prefix)
+	if !ok || strings.Contains(attr, prefix) {
+		return "", false
+	}
+	return strings.TrimSuffix(attr, ")"), true
+}
+
+// NewSlogEmitter returns an Emitter producing slog.Attr expressions, or with
+// the slice style statements appending them to SlogAttrs
+func NewSlogEmitter(ta *TypeAnalyzer) Emitter {
+	if ta.config.LogValueStyle == config.LogValueStyleClosure {
+		return attrEmitter{analyzer: ta, dialect: ta.slogDialect()}
+	}
+	return attrEmitter{analyzer: ta, dialect: ta.slogDialect(), slice: SlogAttrs}
+}
+
+// GenerateLogStatement generates the slog expression for a field. Fields
+// hoisted through nil pointers produce an empty attribute, which handlers omit.
+func (ta *TypeAnalyzer) GenerateLogStatement(analysis FieldAnalysis, receiverName string) string {
+	return attrEmitter{analyzer: ta, dialect: ta.slogDialect()}.Field(analysis, receiverName)
+}
+
+// slogDialect returns the slog dialect calling generated structs through the
+// configured method name
+func (ta *TypeAnalyzer) slogDialect() attrDialect {
+	dialect := slogDialect
+	if ta.config.MethodName != "" {
+		dialect.nested = `slog.Attr{Key: %s, Value: %s.` + ta.config.MethodName + `()}`
+	}
+	return dialect
+}
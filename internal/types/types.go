@@ -2,6 +2,10 @@ package types
 
 import (
 	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/stuckinforloop/oak/internal/config"
@@ -12,11 +16,14 @@ import (
 type SlogFunction string
 
 const (
-	SlogInt64   SlogFunction = "slog.Int64"
-	SlogString  SlogFunction = "slog.String"
-	SlogBool    SlogFunction = "slog.Bool"
-	SlogFloat64 SlogFunction = "slog.Float64"
-	SlogAny     SlogFunction = "slog.Any"
+	SlogInt64    SlogFunction = "slog.Int64"
+	SlogUint64   SlogFunction = "slog.Uint64"
+	SlogString   SlogFunction = "slog.String"
+	SlogBool     SlogFunction = "slog.Bool"
+	SlogFloat64  SlogFunction = "slog.Float64"
+	SlogTime     SlogFunction = "slog.Time"
+	SlogDuration SlogFunction = "slog.Duration"
+	SlogAny      SlogFunction = "slog.Any"
 )
 
 // FieldAction represents what action to take for a field during logging
@@ -31,19 +38,80 @@ const (
 
 	// ActionSkip means the field should be skipped entirely
 	ActionSkip
+
+	// ActionMask means the field should be logged with all but its last
+	// characters masked
+	ActionMask
+
+	// ActionHash means the field should be logged as a truncated hash of its
+	// value
+	ActionHash
 )
 
+// String returns the lowercase name of the action, e.g. "redact"
+func (a FieldAction) String() string {
+	switch a {
+	case ActionLog:
+		return "log"
+	case ActionRedact:
+		return "redact"
+	case ActionSkip:
+		return "skip"
+	case ActionMask:
+		return "mask"
+	case ActionHash:
+		return "hash"
+	default:
+		return fmt.Sprintf("FieldAction(%d)", int(a))
+	}
+}
+
+// maskVisibleChars is the number of trailing characters left visible by ActionMask
+const maskVisibleChars = 4
+
 // FieldAnalysis contains the analysis result for a struct field
 type FieldAnalysis struct {
 	Field    parser.FieldInfo // Original field information
 	Action   FieldAction      // What action to take
 	SlogFunc SlogFunction     // Which slog function to use
 	LogValue string           // The value to log (for redacted fields)
+	Imports  []string         // Packages the generated statement depends on
+
+	Nested    *parser.StructInfo    // Generated struct held by the field, if any
+	KeyPrefix string                // Prefix for keys of fields hoisted from nested structs
+	Parent    string                // Accessor path of the enclosing nested field, e.g. ".Address"
+	Guards    []string              // Accessor paths of enclosing pointer fields that may be nil
+	Depth     int                   // Number of embedded structs the field is promoted through
+	OmitZero  bool                  // Whether the field is omitted when it holds its zero value
+	Formatter string                // Custom formatter call (e.g. "logfmt.IPAttr"), if configured
+	Forced    string                // slog function forced with log:"func=...", if any
+	BoolText  [2]string             // Strings logged for true and false with log:"bool=...", if any
+	MapValue  *parser.StructInfo    // Generated struct held by the field's map values, if any
+	Enum      []parser.EnumConstant // Named constants of the field's integer type, if known
+	RedactKey string                // Configured redact key matching the field name, if any
+	Recursive bool                  // Whether the nested or map value struct is logged with the nesting depth
+	LogValuer bool                  // Whether the field's type implements slog.LogValuer, known from type information
+	Stringer  bool                  // Whether the pointer field's named type implements fmt.Stringer, known from type information
+	Inline    []FieldAnalysis       // Analyses of the fields of an inline anonymous struct, logged as a group
+	SortKeys  bool                  // Whether the map field is logged as a group in sorted key order
+	CapSlice  bool                  // Whether the slice field is logged through the generated function capping its length
 }
 
+// DefaultHashFunc is the name of the generated function hashing fields tagged
+// log:"hash"
+const DefaultHashFunc = "oakHash"
+
+// DefaultCapSliceFunc is the name of the generated function capping the
+// number of elements logged for slices, when maxSliceLen is set
+const DefaultCapSliceFunc = "oakCapSlice"
+
 // TypeAnalyzer analyzes struct fields and determines appropriate slog functions
 type TypeAnalyzer struct {
-	config *config.Config
+	config       *config.Config
+	knownStructs map[string]parser.StructInfo // Structs with generated LogValue methods
+	recursive    map[string]bool              // Known structs nested in fields of their own type
+	hashFunc     string                       // Generated hash function; DefaultHashFunc when empty
+	capSliceFunc string                       // Generated slice capping function; DefaultCapSliceFunc when empty
 }
 
 // NewTypeAnalyzer creates a new TypeAnalyzer with the given configuration
@@ -53,54 +121,558 @@ func NewTypeAnalyzer(cfg *config.Config) *TypeAnalyzer {
 	}
 }
 
+// WithKnownStructs returns a copy of the analyzer that treats fields holding
+// one of the given structs as nested structs
+func (ta *TypeAnalyzer) WithKnownStructs(structs []parser.StructInfo) *TypeAnalyzer {
+	known := make(map[string]parser.StructInfo, len(structs))
+	for _, s := range structs {
+		known[s.Name] = s
+	}
+
+	return &TypeAnalyzer{
+		config:       ta.config,
+		knownStructs: known,
+		recursive:    recursiveStructs(known),
+		hashFunc:     ta.hashFunc,
+		capSliceFunc: ta.capSliceFunc,
+	}
+}
+
+// WithHashFunc returns a copy of the analyzer hashing fields tagged
+// log:"hash" through the named generated function
+func (ta *TypeAnalyzer) WithHashFunc(name string) *TypeAnalyzer {
+	analyzer := *ta
+	analyzer.hashFunc = name
+	return &analyzer
+}
+
+// HashFunc returns the name of the generated function hashing fields
+func (ta *TypeAnalyzer) HashFunc() string {
+	if ta.hashFunc != "" {
+		return ta.hashFunc
+	}
+	return DefaultHashFunc
+}
+
+// WithCapSliceFunc returns a copy of the analyzer capping slices through the
+// named generated function
+func (ta *TypeAnalyzer) WithCapSliceFunc(name string) *TypeAnalyzer {
+	analyzer := *ta
+	analyzer.capSliceFunc = name
+	return &analyzer
+}
+
+// CapSliceFunc returns the name of the generated function capping slices
+func (ta *TypeAnalyzer) CapSliceFunc() string {
+	if ta.capSliceFunc != "" {
+		return ta.capSliceFunc
+	}
+	return DefaultCapSliceFunc
+}
+
+// recursiveStructs returns the structs that hold themselves through a chain
+// of fields of known struct types (or maps of them), such as
+// type Node struct { Next *Node }
+func recursiveStructs(known map[string]parser.StructInfo) map[string]bool {
+	edges := make(map[string][]string, len(known))
+	for name, s := range known {
+		for _, field := range s.Fields {
+			fieldType := strings.TrimPrefix(resolveType(field, s).Type, "*")
+			if _, valueType, ok := splitMapType(fieldType); ok {
+				fieldType = strings.TrimPrefix(valueType, "*")
+			}
+			if _, ok := known[fieldType]; ok {
+				edges[name] = append(edges[name], fieldType)
+			}
+		}
+	}
+
+	recursive := make(map[string]bool)
+	for name := range known {
+		// Search for a path from the struct back to itself
+		visited := make(map[string]bool)
+		stack := slices.Clone(edges[name])
+		for len(stack) > 0 && !recursive[name] {
+			next := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if next == name {
+				recursive[name] = true
+			}
+			if !visited[next] {
+				visited[next] = true
+				stack = append(stack, edges[next]...)
+			}
+		}
+	}
+	return recursive
+}
+
+// IsRecursive reports whether a known struct holds itself through its fields,
+// in which case its generated method tracks the nesting depth
+func (ta *TypeAnalyzer) IsRecursive(name string) bool {
+	return ta.recursive[name]
+}
+
+// maxDepthValue is logged in place of recursive structs nested beyond the
+// depth limit
+const maxDepthValue = "[MAX DEPTH]"
+
+// maxDepth returns the configured nesting depth limit of recursive structs
+func (ta *TypeAnalyzer) maxDepth() int {
+	if ta.config.MaxDepth > 0 {
+		return ta.config.MaxDepth
+	}
+	return config.DefaultMaxDepth
+}
+
+// IsSkippedEmbeddedInterface reports whether a field embeds an interface and
+// is skipped because embedded interfaces are not configured to be logged
+func (ta *TypeAnalyzer) IsSkippedEmbeddedInterface(field parser.FieldInfo) bool {
+	return field.Embedded && field.Interface && !ta.config.LogEmbeddedInterfaces
+}
+
 // AnalyzeField analyzes a single field and returns the appropriate analysis
 func (ta *TypeAnalyzer) AnalyzeField(field parser.FieldInfo) FieldAnalysis {
 	analysis := FieldAnalysis{
 		Field: field,
 	}
+	options := field.LogOptions
 
-	// First, check if the field should be skipped
-	if field.LogTag == "-" {
+	// First, check if the field should be skipped. Functions, channels, and
+	// embedded interfaces have no meaningful value to log, so they are skipped
+	// unless configured. With the allowlist, fields are skipped unless opted in.
+	if options.Skip || (isFuncType(field.Type) && !ta.config.LogFuncFields) ||
+		(isChanType(field.Type) && !ta.config.LogChanFields) || ta.IsSkippedEmbeddedInterface(field) ||
+		(ta.config.Allowlist && !options.Log && !ta.config.IsAllowedField(field.Name)) {
 		analysis.Action = ActionSkip
 		return analysis
 	}
+	analysis.RedactKey, _ = ta.config.MatchRedactKey(field.Name)
 
-	// Check if the field should be redacted
-	if ta.shouldRedactField(field) {
+	// Check if the field should be redacted. Masking only applies to strings,
+	// so masked fields of other types are redacted instead.
+	if ta.shouldRedactField(field) || (options.Mask && !isStringType(field.Type)) {
 		analysis.Action = ActionRedact
 		analysis.SlogFunc = SlogString
-		analysis.LogValue = ta.config.RedactMessage
+		analysis.LogValue = ta.redactMessage(field)
+		return analysis
+	}
+
+	// Zero values are omitted when configured, unless the field opts out.
+	// Redacted fields are always logged so omission does not reveal emptiness.
+	analysis.OmitZero = (ta.config.OmitZero || options.OmitZero ||
+		(ta.config.OmitZeroTime && field.Type == "time.Time")) && !options.Always
+
+	// Check if the field should be masked
+	if options.Mask {
+		analysis.Action = ActionMask
+		analysis.SlogFunc = SlogString
+		analysis.Imports = []string{"strings"}
+		return analysis
+	}
+
+	// Check if the field should be hashed; values other than strings are
+	// hashed as formatted by fmt.Sprint
+	if options.Hash {
+		analysis.Action = ActionHash
+		analysis.SlogFunc = SlogString
+		analysis.Imports = []string{"crypto/sha256", "encoding/hex"}
+		if !isStringType(field.Type) {
+			analysis.Imports = append(analysis.Imports, "fmt")
+		}
 		return analysis
 	}
 
 	// Field should be logged normally
 	analysis.Action = ActionLog
+
+	// A slog function forced with log:"func=..." replaces the inferred one,
+	// and the value is converted to its parameter type. Unknown functions
+	// are reported by the generator.
+	if forced, ok := forcedFunctions[options.Func]; ok {
+		analysis.Forced = options.Func
+		analysis.SlogFunc = forced.function
+		if strings.HasPrefix(forced.valueType, "time.") && strings.TrimPrefix(field.Type, "*") != forced.valueType {
+			analysis.Imports = []string{"time"}
+		}
+		return analysis
+	}
+
+	// A custom formatter configured for the type replaces built-in handling
+	if call, importPath, ok := ta.customFormatter(field); ok {
+		analysis.Formatter = call
+		if importPath != "" {
+			analysis.Imports = []string{importPath}
+		}
+		return analysis
+	}
+
 	analysis.SlogFunc = ta.getSlogFunction(field)
 
+	// Bools tagged log:"bool=..." are logged as one of two strings. Malformed
+	// options are reported by the generator.
+	if analysis.SlogFunc == SlogBool && options.Bool != "" {
+		if trueText, falseText, err := BoolStrings(options.Bool); err == nil {
+			analysis.SlogFunc = SlogString
+			analysis.BoolText = [2]string{trueText, falseText}
+		}
+	}
+	analysis.Imports = ta.getImports(field, analysis.SlogFunc)
+
+	if nested, ok := ta.knownStructs[strings.TrimPrefix(field.Type, "*")]; ok {
+		analysis.Nested = &nested
+	}
+
+	// Inline anonymous structs are logged as a group of their fields, whose
+	// accessors are set relative to the field when emitted
+	for _, inline := range field.Fields {
+		child := ta.AnalyzeField(inline)
+		if child.Action == ActionSkip {
+			continue
+		}
+		analysis.Inline = append(analysis.Inline, child)
+		analysis.Imports = append(analysis.Imports, child.Imports...)
+	}
+
+	// Types implementing slog.LogValuer, such as structs generated by oak in
+	// other packages, are logged through their LogValue method rather than by
+	// reflection (requires the packages loader)
+	if analysis.Nested == nil && analysis.SlogFunc == SlogAny && field.ImplementsLogValuer() {
+		analysis.LogValuer = true
+	}
+
+	// Pointers to named types implementing fmt.Stringer log the result of
+	// their String method, taking precedence over enum constants (requires
+	// the packages loader)
+	if field.IsPointer && analysis.SlogFunc == SlogAny && analysis.Nested == nil && !analysis.LogValuer &&
+		len(field.Fields) == 0 && field.ImplementsStringer() {
+		analysis.Stringer = true
+	}
+
+	// Integer enums log the name of the matching constant (requires the
+	// packages loader)
+	if constants, importPath := field.EnumConstants(); len(constants) > 0 && !analysis.Stringer {
+		analysis.Enum = constants
+		if importPath != "" {
+			analysis.Imports = append(analysis.Imports, importPath)
+		}
+	}
+
+	// Maps of generated structs, and any map when sorting keys, are logged as
+	// a group with an entry per key
+	if keyType, valueType, ok := splitMapType(strings.TrimPrefix(field.Type, "*")); ok {
+		if nested, ok := ta.knownStructs[strings.TrimPrefix(valueType, "*")]; ok {
+			analysis.MapValue = &nested
+		}
+		// Keys of types from other packages cannot be named without importing
+		// their package, so those maps keep their iteration order
+		analysis.SortKeys = ta.config.SortMapKeys && analysis.SlogFunc == SlogAny && !strings.Contains(keyType, ".")
+		if analysis.SortKeys {
+			analysis.Imports = append(analysis.Imports, "sort")
+		}
+		if keyType != "string" && (analysis.MapValue != nil || analysis.SortKeys) {
+			analysis.Imports = append(analysis.Imports, "fmt")
+		}
+	}
+
+	// Slices longer than maxSliceLen are logged through a generated function
+	// logging their first elements and a marker counting the rest
+	if ta.config.MaxSliceLen > 0 && analysis.SlogFunc == SlogAny && !analysis.LogValuer &&
+		strings.HasPrefix(strings.TrimPrefix(field.Type, "*"), "[]") {
+		analysis.CapSlice = true
+		analysis.Imports = append(analysis.Imports, "fmt")
+	}
+
 	return analysis
 }
 
-// AnalyzeStruct analyzes all fields in a struct and returns field analyses
+// AnalyzeStruct analyzes all fields in a struct and returns field analyses in
+// the configured field order. With the flattened output style, the fields of
+// nested structs are hoisted in place of the field holding them, as are the
+// fields of embedded generated structs with any style.
 func (ta *TypeAnalyzer) AnalyzeStruct(structInfo parser.StructInfo) []FieldAnalysis {
 	var analyses []FieldAnalysis
 
 	for _, field := range structInfo.Fields {
-		analysis := ta.AnalyzeField(field)
-		analyses = append(analyses, analysis)
+		analysis := ta.AnalyzeField(resolveType(field, structInfo))
+		analyses = append(analyses, ta.flatten(analysis, []string{structInfo.Name})...)
+	}
+	analyses = ta.dropShadowed(analyses)
+
+	// Recursive structs pass the nesting depth on to the recursive structs
+	// they hold, which stop logging nested values at the depth limit
+	if ta.recursive[structInfo.Name] {
+		for i, analysis := range analyses {
+			nested := analysis.Nested
+			if analysis.MapValue != nil {
+				nested = analysis.MapValue
+			}
+			analyses[i].Recursive = nested != nil && ta.recursive[nested.Name]
+		}
+	}
+
+	if ta.config.FieldOrder == config.FieldOrderAlphabetical {
+		// Sort by the logged key, so hoisted fields sort under their parent's key
+		sort.SliceStable(analyses, func(i, j int) bool {
+			return strings.ToLower(ta.attributeKey(analyses[i])) < strings.ToLower(ta.attributeKey(analyses[j]))
+		})
+	}
+
+	return analyses
+}
+
+// flatten hoists the fields of a nested struct under the key of the field
+// holding it when the flattened output style is configured. Structs already
+// being flattened are logged as groups instead, so recursive types terminate.
+// The fields of an embedded struct are promoted under the keys they have in
+// the embedded struct, as encoding/json does, unless the field is named by a
+// tag; a nil embedded pointer contributes no fields.
+func (ta *TypeAnalyzer) flatten(analysis FieldAnalysis, enclosing []string) []FieldAnalysis {
+	promoted := isPromoted(analysis.Field)
+	if analysis.Nested == nil || analysis.Action != ActionLog || (!promoted && ta.config.OutputStyle != config.OutputStyleFlattened) {
+		return []FieldAnalysis{analysis}
+	}
+	if slices.Contains(enclosing, analysis.Nested.Name) {
+		return []FieldAnalysis{analysis}
+	}
+
+	accessor := analysis.Parent + "." + analysis.Field.Name
+	guards := analysis.Guards
+	if analysis.Field.IsPointer {
+		guards = append(slices.Clip(guards), accessor)
+	}
+
+	keyPrefix, depth := ta.attributeKey(analysis)+".", analysis.Depth
+	if promoted {
+		keyPrefix, depth = analysis.KeyPrefix, depth+1
+	}
+
+	var analyses []FieldAnalysis
+	for _, field := range analysis.Nested.Fields {
+		child := ta.AnalyzeField(resolveType(field, *analysis.Nested))
+		child.KeyPrefix = keyPrefix
+		child.Parent = accessor
+		child.Guards = guards
+		child.Depth = depth
+		analyses = append(analyses, ta.flatten(child, append(slices.Clip(enclosing), analysis.Nested.Name))...)
 	}
 
 	return analyses
 }
 
+// isPromoted reports whether the fields of an embedded struct or pointer
+// field are promoted into the struct embedding it, which they are unless the
+// field is given a key by a log or json tag
+func isPromoted(field parser.FieldInfo) bool {
+	return field.Embedded && field.LogOptions.Name == "" && field.JSONName == ""
+}
+
+// dropShadowed drops promoted fields whose key is also logged by a field
+// promoted through fewer embedded structs, as Go and encoding/json resolve
+// promoted fields. Promoted fields sharing a key at the same depth are
+// ambiguous and all dropped.
+func (ta *TypeAnalyzer) dropShadowed(analyses []FieldAnalysis) []FieldAnalysis {
+	shallowest := make(map[string]int)
+	count := make(map[string]int)
+	for _, analysis := range analyses {
+		key := ta.attributeKey(analysis)
+		if depth, ok := shallowest[key]; ok && depth < analysis.Depth {
+			continue
+		} else if !ok || analysis.Depth < depth {
+			shallowest[key], count[key] = analysis.Depth, 0
+		}
+		count[key]++
+	}
+
+	kept := analyses[:0]
+	for _, analysis := range analyses {
+		key := ta.attributeKey(analysis)
+		if analysis.Depth > shallowest[key] || (analysis.Depth > 0 && count[key] > 1) {
+			continue
+		}
+		kept = append(kept, analysis)
+	}
+	return kept
+}
+
+// resolveType returns the field with its type resolved through the type
+// aliases and renamed imports of the struct declaring it
+func resolveType(field parser.FieldInfo, structInfo parser.StructInfo) parser.FieldInfo {
+	return resolveImports(resolveAlias(field, structInfo.Aliases), structInfo.Imports)
+}
+
+// qualifierPattern matches the package qualifier of a type, e.g. "t." in []t.Time
+var qualifierPattern = regexp.MustCompile(`\b[A-Za-z_][A-Za-z0-9_]*\.`)
+
+// resolveImports returns the field with the package qualifiers of its type
+// rewritten to the conventional names of renamed imports, so that a field of
+// type t.Time, with time imported as t, is analyzed as a time.Time
+func resolveImports(field parser.FieldInfo, imports map[string]string) parser.FieldInfo {
+	if len(imports) == 0 {
+		return field
+	}
+	field.Type = qualifierPattern.ReplaceAllStringFunc(field.Type, func(qualifier string) string {
+		importPath, ok := imports[strings.TrimSuffix(qualifier, ".")]
+		if !ok {
+			return qualifier
+		}
+		return PackageName(importPath) + "."
+	})
+	return field
+}
+
+// resolveAlias returns the field with its type resolved through the package's
+// type aliases, so that a field of type Celsius, declared as
+// type Celsius = float64, is analyzed as a float64
+func resolveAlias(field parser.FieldInfo, aliases map[string]string) parser.FieldInfo {
+	// Each alias is followed at most once, so alias cycles terminate
+	for range aliases {
+		pointer := ""
+		if field.IsPointer {
+			pointer = "*"
+		}
+		target, ok := aliases[strings.TrimPrefix(field.Type, pointer)]
+		if !ok {
+			break
+		}
+		field.Type = pointer + target
+		field.IsPointer = strings.HasPrefix(field.Type, "*")
+	}
+	return field
+}
+
+// forcedFunctions maps the slog constructors accepted by log:"func=..." to
+// the function logging the field and the type its value is converted to;
+// slog.Any takes values of any type
+var forcedFunctions = map[string]struct {
+	function  SlogFunction
+	valueType string
+}{
+	"slog.String":   {SlogString, "string"},
+	"slog.Int":      {SlogInt64, "int64"},
+	"slog.Int64":    {SlogInt64, "int64"},
+	"slog.Uint64":   {SlogUint64, "uint64"},
+	"slog.Float64":  {SlogFloat64, "float64"},
+	"slog.Bool":     {SlogBool, "bool"},
+	"slog.Time":     {SlogTime, "time.Time"},
+	"slog.Duration": {SlogDuration, "time.Duration"},
+	"slog.Any":      {SlogAny, ""},
+}
+
+// ValidateLogFunc returns an error unless function is a slog constructor
+// that log:"func=..." accepts
+func ValidateLogFunc(function string) error {
+	if _, ok := forcedFunctions[function]; ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(forcedFunctions))
+	for name := range forcedFunctions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("invalid log function %q: must be one of %s", function, strings.Join(names, ", "))
+}
+
+// ValidateLogOptions returns an error for log tag options that would not
+// take effect: unknown options, such as a misspelled redact that would leave
+// the field logged in clear text, unknown functions forced with
+// log:"func=...", and malformed log:"bool=..." strings
+func ValidateLogOptions(options parser.LogTagOptions) error {
+	if len(options.Unknown) > 0 {
+		return fmt.Errorf("unknown log tag option %q: must be one of -, log, redact, mask, hash, omitzero, always, name=, func=, bool=", options.Unknown[0])
+	}
+	if options.Func != "" {
+		if err := ValidateLogFunc(options.Func); err != nil {
+			return err
+		}
+	}
+	if options.Bool != "" {
+		if _, _, err := BoolStrings(options.Bool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BoolStrings splits the value of a log:"bool=..." option, such as
+// enabled/disabled, into the strings logged for true and false
+func BoolStrings(option string) (trueText, falseText string, err error) {
+	trueText, falseText, ok := strings.Cut(option, "/")
+	if !ok || trueText == "" || falseText == "" || strings.Contains(falseText, "/") {
+		return "", "", fmt.Errorf("invalid bool strings %q: must be <true>/<false>, e.g. enabled/disabled", option)
+	}
+	return trueText, falseText, nil
+}
+
+// forcedArg returns the argument of a function forced with log:"func=...",
+// converting the value to the function's parameter type unless the field
+// already has that type
+func forcedArg(analysis FieldAnalysis, value string) string {
+	valueType := forcedFunctions[analysis.Forced].valueType
+	if valueType == "" || strings.TrimPrefix(analysis.Field.Type, "*") == valueType {
+		return value
+	}
+	return valueType + "(" + value + ")"
+}
+
+// customFormatter returns the call expression and import path of the custom
+// formatter configured for a field's type. Formatters without a package path
+// refer to a function in the generated package.
+func (ta *TypeAnalyzer) customFormatter(field parser.FieldInfo) (call, importPath string, ok bool) {
+	function, ok := ta.config.CustomFormatters[strings.TrimPrefix(field.Type, "*")]
+	if !ok {
+		return "", "", false
+	}
+
+	call, importPath = QualifiedFunction(function)
+	return call, importPath, true
+}
+
+// QualifiedFunction splits a fully-qualified function name such as
+// github.com/acme/logfmt.IPAttr into the expression calling it (logfmt.IPAttr)
+// and its import path. Functions without a package path refer to the
+// generated package and need no import.
+func QualifiedFunction(function string) (call, importPath string) {
+	dot := strings.LastIndex(function, ".")
+	if dot < 0 {
+		return function, ""
+	}
+
+	importPath = function[:dot]
+	return PackageName(importPath) + function[dot:], importPath
+}
+
+// majorVersionPattern matches a major version suffix element such as v2
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// PackageName returns the conventional package name for an import path: its
+// last element, skipping a major version suffix such as /v2
+func PackageName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && majorVersionPattern.MatchString(name) {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
+
 // shouldRedactField determines if a field should be redacted
 func (ta *TypeAnalyzer) shouldRedactField(field parser.FieldInfo) bool {
+	options := field.LogOptions
+
 	// Skip fields should not be redacted (they're handled separately)
-	if field.LogTag == "-" {
+	if options.Skip {
 		return false
 	}
 
 	// Check explicit log:"redact" tag
-	if field.LogTag == "redact" {
+	if options.Redact {
+		return true
+	}
+
+	// Unexported fields are redacted when configured
+	if ta.config.RedactUnexported && !token.IsExported(field.Name) {
 		return true
 	}
 
@@ -124,6 +696,12 @@ func (ta *TypeAnalyzer) getSlogFunction(field parser.FieldInfo) SlogFunction {
 		return SlogInt64
 	case "uint", "uint8", "uint16", "uint32", "uint64":
 		return SlogInt64
+	case "uintptr":
+		return SlogUint64
+
+	// Pointers without a type are logged as their hex address
+	case "unsafe.Pointer":
+		return SlogString
 
 	// String types
 	case "string":
@@ -137,98 +715,563 @@ func (ta *TypeAnalyzer) getSlogFunction(field parser.FieldInfo) SlogFunction {
 	case "float32", "float64":
 		return SlogFloat64
 
+	// Byte slices are base64-encoded into a string
+	case "[]byte", "[]uint8":
+		return SlogString
+
+	// Raw JSON is already readable and logged as its text
+	case rawMessageType:
+		return SlogString
+
+	// Network addresses log in dotted or CIDR notation via String()
+	case "net.IP", "net.IPNet":
+		return SlogString
+
+	// Arbitrary-precision numbers log in decimal via String()
+	case "big.Int", "big.Float":
+		return SlogString
+
+	// Time types
+	case "time.Time":
+		if ta.config.TimeFormat != "" {
+			return SlogString
+		}
+		return SlogTime
+	case "time.Duration":
+		if ta.config.DurationFormat == config.DurationFormatString {
+			return SlogString
+		}
+		return SlogDuration
+
 	// Complex types (structs, slices, maps, interfaces, etc.)
 	default:
+		// Fixed-size byte arrays (e.g. UUIDs) are hex-encoded into a string
+		if IsByteArrayType(fieldType) {
+			return SlogString
+		}
+		// database/sql Null types log the value they hold like the value's
+		// type; values logged through a conversion to string, such as byte
+		// slices, are logged as is
+		if _, valueType, ok := sqlNullValue(fieldType); ok {
+			fn := ta.getSlogFunction(parser.FieldInfo{Type: valueType})
+			if fn == SlogString && valueType != "string" && valueType != "time.Time" && valueType != "time.Duration" {
+				return SlogAny
+			}
+			return fn
+		}
+		// Functions and channels log a description when set
+		if _, ok := opaqueDescription(fieldType); ok {
+			return SlogString
+		}
 		return SlogAny
 	}
 }
 
-// GenerateLogStatement generates the slog statement for a field
-func (ta *TypeAnalyzer) GenerateLogStatement(analysis FieldAnalysis, receiverName string) string {
-	fieldName := analysis.Field.Name
+// getImports returns the packages a normally logged field's statement depends on
+func (ta *TypeAnalyzer) getImports(field parser.FieldInfo, slogFunc SlogFunction) []string {
+	fieldType := strings.TrimPrefix(field.Type, "*")
 
-	switch analysis.Action {
-	case ActionSkip:
-		return "" // Field should not appear in log output
+	// Only formatted times need an import for the value of database/sql
+	// Null types
+	if _, valueType, ok := sqlNullValue(fieldType); ok {
+		if valueType == "time.Time" && slogFunc == SlogString && timeLayoutConstants[ta.config.TimeFormat] {
+			return []string{"time"}
+		}
+		return nil
+	}
 
-	case ActionRedact:
-		return fmt.Sprintf(`%s("%s", "%s")`, analysis.SlogFunc, fieldName, analysis.LogValue)
+	switch {
+	case IsByteArrayType(fieldType):
+		return []string{"encoding/hex"}
+	case isByteSliceType(fieldType):
+		return []string{"encoding/base64"}
+	case isInterfaceField(field):
+		return []string{"fmt", "reflect"} // Nil pointers log their type
+	case fieldType == "unsafe.Pointer":
+		return []string{"fmt"}
+	case fieldType == "time.Time" && slogFunc == SlogString:
+		if timeLayoutConstants[ta.config.TimeFormat] {
+			return []string{"time"}
+		}
+	case fieldType == "time.Time" && field.IsPointer && ta.config.NilBehavior == config.NilBehaviorZero:
+		return []string{"time"} // Nil pointers log time.Time{}
+	}
 
-	case ActionLog:
-		return ta.generateNormalLogStatement(analysis, receiverName)
+	return nil
+}
 
-	default:
-		return fmt.Sprintf(`%s("%s", %s)`, SlogAny, fieldName, ta.getFieldAccessor(analysis.Field, receiverName))
+// redactMessage returns the configured redact message for a field, with the
+// {field} and {type} placeholders replaced by the field's name and type
+func (ta *TypeAnalyzer) redactMessage(field parser.FieldInfo) string {
+	return strings.NewReplacer("{field}", field.Name, "{type}", field.Type).Replace(ta.config.RedactMessage)
+}
+
+// GenerateRedactStatements generates the statements of a struct's Redacted
+// method, which blank its sensitive fields in a copy of the struct. Fields are
+// analyzed without flattening, so the fields of nested structs, including
+// those reached through pointers, are redacted by the nested struct's own
+// Redacted method.
+func (ta *TypeAnalyzer) GenerateRedactStatements(structInfo parser.StructInfo, receiverName string) []string {
+	var statements []string
+	for _, field := range structInfo.Fields {
+		analysis := ta.AnalyzeField(resolveType(field, structInfo))
+		if statement := ta.GenerateRedactStatement(analysis, receiverName); statement != "" {
+			statements = append(statements, statement)
+		}
 	}
+	return statements
 }
 
-// generateNormalLogStatement generates a normal (non-redacted) log statement
-func (ta *TypeAnalyzer) generateNormalLogStatement(analysis FieldAnalysis, receiverName string) string {
-	fieldName := analysis.Field.Name
-	fieldAccessor := ta.getFieldAccessor(analysis.Field, receiverName)
+// GenerateRedactStatement generates the assignment that blanks a sensitive
+// field in a copy of the struct. String fields are set to the redact message
+// and other fields are zeroed. Generated structs are replaced by their
+// redacted copies, and pointers to them by pointers to a redacted copy, so
+// the original is left untouched. Other fields that are not redacted, masked,
+// or hashed need no statement.
+func (ta *TypeAnalyzer) GenerateRedactStatement(analysis FieldAnalysis, receiverName string) string {
+	fieldAccessor := ta.getFieldAccessor(analysis, receiverName)
+	fieldType := analysis.Field.Type
 
-	switch analysis.SlogFunc {
-	case SlogInt64:
+	if analysis.Action == ActionLog && analysis.Nested != nil {
 		if analysis.Field.IsPointer {
-			// For pointer types, we need to handle nil case and convert to int64
-			return fmt.Sprintf(`func() slog.Attr {
-				if %s == nil {
-					return slog.String("%s", "null")
-				}
-				return slog.Int64("%s", int64(*%s))
-			}()`, fieldAccessor, fieldName, fieldName, fieldAccessor)
-		}
-		// For non-pointer integer types, convert to int64
-		if analysis.Field.Type != "int64" {
-			return fmt.Sprintf(`%s("%s", int64(%s))`, analysis.SlogFunc, fieldName, fieldAccessor)
+			return fmt.Sprintf(`if %s != nil {
+redacted := %s.Redacted()
+%s = &redacted
+}`, fieldAccessor, fieldAccessor, fieldAccessor)
 		}
-		return fmt.Sprintf(`%s("%s", %s)`, analysis.SlogFunc, fieldName, fieldAccessor)
+		return fmt.Sprintf(`%s = %s.Redacted()`, fieldAccessor, fieldAccessor)
+	}
 
-	case SlogFloat64:
-		if analysis.Field.IsPointer {
-			return fmt.Sprintf(`func() slog.Attr {
-				if %s == nil {
-					return slog.String("%s", "null")
-				}
-				return slog.Float64("%s", float64(*%s))
-			}()`, fieldAccessor, fieldName, fieldName, fieldAccessor)
-		}
-		// For non-pointer float types, convert to float64
-		if analysis.Field.Type != "float64" {
-			return fmt.Sprintf(`%s("%s", float64(%s))`, analysis.SlogFunc, fieldName, fieldAccessor)
+	if analysis.Action != ActionRedact && analysis.Action != ActionMask && analysis.Action != ActionHash {
+		return ""
+	}
+
+	switch {
+	case fieldType == "string":
+		return fmt.Sprintf(`%s = %q`, fieldAccessor, ta.redactMessage(analysis.Field))
+	case fieldType == "*string":
+		return fmt.Sprintf(`if %s != nil {
+redacted := %q
+%s = &redacted
+}`, fieldAccessor, ta.redactMessage(analysis.Field), fieldAccessor)
+	default:
+		return fmt.Sprintf(`%s = %s`, fieldAccessor, zeroValue(fieldType))
+	}
+}
+
+// hashValue returns the call hashing a field's value, dereferencing pointers
+func (ta *TypeAnalyzer) hashValue(field parser.FieldInfo, fieldAccessor string) string {
+	value := ta.deref(field, fieldAccessor)
+	if !isStringType(field.Type) {
+		value = fmt.Sprintf("fmt.Sprint(%s)", value)
+	}
+	return fmt.Sprintf("%s(%s)", ta.HashFunc(), value)
+}
+
+// capSlice returns the call capping a slice field's value when its length is
+// capped, or the value itself
+func (ta *TypeAnalyzer) capSlice(analysis FieldAnalysis, value string) string {
+	if !analysis.CapSlice {
+		return value
+	}
+	return fmt.Sprintf("%s(%s)", ta.CapSliceFunc(), value)
+}
+
+// UsesCapSlice reports whether any of the analyses, or of the fields of
+// inline structs they hold, caps its slice
+func UsesCapSlice(analyses []FieldAnalysis) bool {
+	for _, analysis := range analyses {
+		if analysis.CapSlice || UsesCapSlice(analysis.Inline) {
+			return true
 		}
-		return fmt.Sprintf(`%s("%s", %s)`, analysis.SlogFunc, fieldName, fieldAccessor)
+	}
+	return false
+}
 
-	case SlogString, SlogBool:
-		if analysis.Field.IsPointer {
-			return fmt.Sprintf(`func() slog.Attr {
-				if %s == nil {
-					return slog.String("%s", "null")
-				}
-				return %s("%s", *%s)
-			}()`, fieldAccessor, fieldName, analysis.SlogFunc, fieldName, fieldAccessor)
+// UsesHash reports whether any of the analyses, or of the fields of inline
+// structs they hold, hashes its field
+func UsesHash(analyses []FieldAnalysis) bool {
+	for _, analysis := range analyses {
+		if analysis.Action == ActionHash || UsesHash(analysis.Inline) {
+			return true
 		}
-		return fmt.Sprintf(`%s("%s", %s)`, analysis.SlogFunc, fieldName, fieldAccessor)
+	}
+	return false
+}
+
+// zeroValue returns a Go expression for the zero value of a type string
+func zeroValue(fieldType string) string {
+	switch {
+	case strings.HasPrefix(fieldType, "*"), strings.HasPrefix(fieldType, "[]"),
+		strings.HasPrefix(fieldType, "map["), fieldType == "interface{}", fieldType == "any",
+		fieldType == "unsafe.Pointer", fieldType == rawMessageType, fieldType == "net.IP",
+		isFuncType(fieldType), isChanType(fieldType):
+		return "nil"
+	case fieldType == "bool":
+		return "false"
+	}
+
+	switch fieldType {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64":
+		return "0"
+	}
+
+	return fmt.Sprintf("*new(%s)", fieldType)
+}
+
+// attributeKey returns the slog attribute key for a field. An explicit key
+// from log:"name=..." is used verbatim, followed by the json tag name when
+// useJSONTagAsKey is set; otherwise the configured casing is applied to the
+// field name. The configured keyPrefix is then prepended as is, except to
+// hoisted fields, which are prefixed with their parent key instead.
+func (ta *TypeAnalyzer) attributeKey(analysis FieldAnalysis) string {
+	prefix := analysis.KeyPrefix
+	if prefix == "" {
+		prefix = ta.config.KeyPrefix
+	}
+
+	if name := analysis.Field.LogOptions.Name; name != "" {
+		return prefix + name
+	}
+	if ta.config.UseJSONTagAsKey && analysis.Field.JSONName != "" {
+		return prefix + analysis.Field.JSONName
+	}
+	return prefix + ConvertKeyCase(analysis.Field.Name, ta.config.KeyCase)
+}
+
+// zeroCheck returns an expression reporting whether a field holds its zero
+// value, or an empty string for types whose zero value cannot be detected
+// without reflection (such as structs from other packages)
+func zeroCheck(field parser.FieldInfo, fieldAccessor string) string {
+	if field.IsPointer || field.Interface || isNilableType(field.Type) {
+		return fieldAccessor + " == nil"
+	}
+
+	// database/sql Null types are zero when they hold no valid value
+	if isSQLNullType(field.Type) {
+		return "!" + fieldAccessor + ".Valid"
+	}
+
+	switch field.Type {
+	case "string":
+		return fieldAccessor + ` == ""`
+	case "bool":
+		return "!" + fieldAccessor
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "byte", "rune", "time.Duration":
+		return fieldAccessor + " == 0"
+	case "time.Time":
+		return fieldAccessor + ".IsZero()"
+	case rawMessageType, "net.IP":
+		return "len(" + fieldAccessor + ") == 0"
+	}
+
+	if IsByteArrayType(field.Type) {
+		return fmt.Sprintf("%s == (%s{})", fieldAccessor, field.Type)
+	}
+
+	return ""
+}
+
+// pointeeZero returns the constructor and expression logging the zero value
+// of the type a pointer field points to, for nilBehavior zero. It reports
+// false for types that cannot be named without an import, inline structs,
+// and recursive structs, whose zero values would log their own zero values.
+// Generated structs are returned as a pointer to their zero value, whose
+// generated method is called.
+func pointeeZero(analysis FieldAnalysis) (SlogFunction, string, bool) {
+	// The zero value of database/sql Null types holds no valid value
+	if isSQLNullType(strings.TrimPrefix(analysis.Field.Type, "*")) {
+		return SlogString, `"null"`, true
+	}
 
+	// Bools logged as strings log the string for false
+	if analysis.BoolText != [2]string{} {
+		return SlogString, fmt.Sprintf("%q", analysis.BoolText[1]), true
+	}
+
+	switch analysis.SlogFunc {
+	case SlogInt64, SlogUint64, SlogFloat64, SlogDuration:
+		return analysis.SlogFunc, "0", true
+	case SlogBool:
+		return SlogBool, "false", true
+	case SlogString:
+		if strings.TrimPrefix(analysis.Field.Type, "*") == "time.Duration" {
+			return SlogString, `"0s"`, true
+		}
+		return SlogString, `""`, true
+	case SlogTime:
+		return SlogTime, "time.Time{}", true
 	case SlogAny:
-		if analysis.Field.IsPointer {
-			return fmt.Sprintf(`func() slog.Attr {
-				if %s == nil {
-					return slog.String("%s", "null")
-				}
-				return %s("%s", *%s)
-			}()`, fieldAccessor, fieldName, analysis.SlogFunc, fieldName, fieldAccessor)
+		elemType := strings.TrimPrefix(analysis.Field.Type, "*")
+		if strings.Contains(elemType, ".") || len(analysis.Field.Fields) > 0 || analysis.Recursive {
+			return "", "", false
+		}
+		if analysis.Nested != nil {
+			return SlogAny, "new(" + elemType + ")", true
 		}
-		return fmt.Sprintf(`%s("%s", %s)`, analysis.SlogFunc, fieldName, fieldAccessor)
+		return SlogAny, "*new(" + elemType + ")", true
+	}
+	return "", "", false
+}
 
+// splitMapType returns the key and value types of a map type string such as
+// map[UserID]Account
+func splitMapType(fieldType string) (keyType, valueType string, ok bool) {
+	rest, ok := strings.CutPrefix(fieldType, "map[")
+	if !ok {
+		return "", "", false
+	}
+
+	// The key type may itself contain brackets, e.g. map[[16]byte]T
+	depth := 1
+	for i, r := range rest {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return rest[:i], rest[i+1:], true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// rangeMap returns the opening of a loop over the entries k, v of a map,
+// visiting keys in sorted order when sorted is set. Strings and numbers are
+// compared directly and other keys by their fmt.Sprint formatting.
+func rangeMap(mapValue, keyType string, sorted bool) string {
+	if !sorted {
+		return fmt.Sprintf("for k, v := range %s {", mapValue)
+	}
+
+	var sortKeys string
+	switch keyType {
+	case "string":
+		sortKeys = "sort.Strings(keys)"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "byte", "rune":
+		sortKeys = "sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })"
 	default:
-		return fmt.Sprintf(`%s("%s", %s)`, SlogAny, fieldName, fieldAccessor)
+		sortKeys = "sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })"
+	}
+
+	return fmt.Sprintf(`m := %[2]s
+keys := make([]%[1]s, 0, len(m))
+for k := range m {
+keys = append(keys, k)
+}
+%[3]s
+for _, k := range keys {
+v := m[k]`, keyType, mapValue, sortKeys)
+}
+
+// isNilableType checks if a type string is a slice, map, interface, function,
+// channel, or unsafe.Pointer
+func isNilableType(fieldType string) bool {
+	return strings.HasPrefix(fieldType, "[]") || strings.HasPrefix(fieldType, "map[") ||
+		isInterfaceType(fieldType) || isFuncType(fieldType) || isChanType(fieldType) ||
+		fieldType == "unsafe.Pointer"
+}
+
+// deref returns the expression for a field's value, dereferencing pointers
+func (ta *TypeAnalyzer) deref(field parser.FieldInfo, fieldAccessor string) string {
+	if field.IsPointer {
+		return "*" + fieldAccessor
+	}
+	return fieldAccessor
+}
+
+// typedNil returns the condition under which an interface value holds a nil
+// pointer, which unlike a nil interface is not logged as null
+func typedNil(value string) string {
+	return fmt.Sprintf("reflect.ValueOf(%[1]s).Kind() == reflect.Pointer && reflect.ValueOf(%[1]s).IsNil()", value)
+}
+
+// timeLayoutConstants are the layout constants exported by the time package
+var timeLayoutConstants = map[string]bool{
+	"Layout": true, "ANSIC": true, "UnixDate": true, "RubyDate": true,
+	"RFC822": true, "RFC822Z": true, "RFC850": true, "RFC1123": true,
+	"RFC1123Z": true, "RFC3339": true, "RFC3339Nano": true, "Kitchen": true,
+	"Stamp": true, "StampMilli": true, "StampMicro": true, "StampNano": true,
+	"DateTime": true, "DateOnly": true, "TimeOnly": true,
+}
+
+// timeLayout returns the Go expression for the configured time layout, either
+// a time package constant (e.g. time.RFC3339) or a quoted custom layout
+func (ta *TypeAnalyzer) timeLayout() string {
+	if timeLayoutConstants[ta.config.TimeFormat] {
+		return "time." + ta.config.TimeFormat
+	}
+	return fmt.Sprintf("%q", ta.config.TimeFormat)
+}
+
+// isInterfaceType checks if a type string is the empty interface or error
+func isInterfaceType(fieldType string) bool {
+	return fieldType == "interface{}" || fieldType == "any" || fieldType == "error"
+}
+
+// isInterfaceField checks if a field's type, or the type it points to, is an
+// interface, including named interfaces such as io.Reader
+func isInterfaceField(field parser.FieldInfo) bool {
+	return field.Interface || isInterfaceType(strings.TrimPrefix(field.Type, "*"))
+}
+
+// isStringType checks if a type string is a string or pointer to string
+func isStringType(fieldType string) bool {
+	return strings.TrimPrefix(fieldType, "*") == "string"
+}
+
+// isFuncType checks if a type string is a function type such as func(int) error
+func isFuncType(fieldType string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(fieldType, "*"), "func(")
+}
+
+// isChanType checks if a type string is a channel type of any direction, such
+// as chan int, <-chan int, or chan<- int
+func isChanType(fieldType string) bool {
+	fieldType = strings.TrimPrefix(fieldType, "*")
+	return strings.HasPrefix(fieldType, "chan ") || strings.HasPrefix(fieldType, "chan<- ") ||
+		strings.HasPrefix(fieldType, "<-chan ")
+}
+
+// opaqueDescription returns the string logged for non-nil values of types
+// whose values cannot be meaningfully logged: "func" for functions and the
+// type (with its direction) for channels
+func opaqueDescription(fieldType string) (string, bool) {
+	switch {
+	case isFuncType(fieldType):
+		return "func", true
+	case isChanType(fieldType):
+		return fieldType, true
+	}
+	return "", false
+}
+
+// rawMessageType is encoding/json's RawMessage, a byte slice holding JSON
+const rawMessageType = "json.RawMessage"
+
+// sqlNullTypes maps the database/sql Null types to the type of the value they
+// hold, in the field named after the type (e.g. String for sql.NullString)
+var sqlNullTypes = map[string]string{
+	"sql.NullString":  "string",
+	"sql.NullInt64":   "int64",
+	"sql.NullInt32":   "int32",
+	"sql.NullInt16":   "int16",
+	"sql.NullByte":    "uint8",
+	"sql.NullFloat64": "float64",
+	"sql.NullBool":    "bool",
+	"sql.NullTime":    "time.Time",
+}
+
+// sqlNullValue returns the field holding the value of a database/sql Null
+// type and the value's type: String for sql.NullString, or V for the generic
+// sql.Null[T]
+func sqlNullValue(fieldType string) (valueField, valueType string, ok bool) {
+	if inner, ok := strings.CutPrefix(fieldType, "sql.Null["); ok && strings.HasSuffix(inner, "]") {
+		return "V", strings.TrimSuffix(inner, "]"), true
+	}
+	valueType, ok = sqlNullTypes[fieldType]
+	return strings.TrimPrefix(fieldType, "sql.Null"), valueType, ok
+}
+
+// unknownTypePattern matches parser.UnknownType as a type name within a type
+// string, e.g. in *unknown or [unknown]byte, but not in pkg.unknown
+var unknownTypePattern = regexp.MustCompile(`(^|[^\w.])` + parser.UnknownType + `\b`)
+
+// HasUnknownType reports whether a type string, or a type it is built from,
+// could not be rendered by the parser
+func HasUnknownType(fieldType string) bool {
+	return unknownTypePattern.MatchString(fieldType)
+}
+
+// UnknownTypeFields returns the names of logged fields, including fields of
+// inline structs (e.g. Config.Limit), whose type the parser could not render.
+// Such fields are logged by reflection, hiding the gap.
+func UnknownTypeFields(analyses []FieldAnalysis) []string {
+	var names []string
+	for _, analysis := range analyses {
+		if analysis.Action != ActionLog {
+			continue
+		}
+		name := strings.TrimPrefix(analysis.Parent+"."+analysis.Field.Name, ".")
+		// Inline structs are reported by the fields they hold
+		if len(analysis.Field.Fields) == 0 && HasUnknownType(analysis.Field.Type) {
+			names = append(names, name)
+		}
+		for _, inline := range UnknownTypeFields(analysis.Inline) {
+			names = append(names, name+"."+inline)
+		}
+	}
+	return names
+}
+
+// isSQLNullType checks if a type string is one of the database/sql Null types
+func isSQLNullType(fieldType string) bool {
+	_, _, ok := sqlNullValue(fieldType)
+	return ok
+}
+
+// sqlNullArg returns the expression for the value held by a database/sql
+// Null field, converted for the field's constructor
+func (ta *TypeAnalyzer) sqlNullArg(analysis FieldAnalysis, fieldAccessor string) string {
+	valueField, valueType, _ := sqlNullValue(strings.TrimPrefix(analysis.Field.Type, "*"))
+	value := fieldAccessor + "." + valueField
+
+	switch {
+	case analysis.SlogFunc == SlogInt64 && valueType != "int64":
+		return "int64(" + value + ")"
+	case analysis.SlogFunc == SlogUint64:
+		return "uint64(" + value + ")"
+	case analysis.SlogFunc == SlogFloat64 && valueType != "float64":
+		return "float64(" + value + ")"
+	case analysis.SlogFunc == SlogString && valueType == "time.Time":
+		return value + ".Format(" + ta.timeLayout() + ")"
+	case analysis.SlogFunc == SlogString && valueType == "time.Duration":
+		return value + ".String()"
+	}
+	return value
+}
+
+// isStringerType checks if a type string is logged through its String
+// method: net.IP and net.IPNet, or math/big's Int and Float
+func isStringerType(fieldType string) bool {
+	switch fieldType {
+	case "net.IP", "net.IPNet", "big.Int", "big.Float":
+		return true
+	}
+	return false
+}
+
+// isByteSliceType checks if a type string is a byte slice
+func isByteSliceType(fieldType string) bool {
+	return fieldType == "[]byte" || fieldType == "[]uint8"
+}
+
+// IsByteArrayType checks if a type string is a fixed-size byte array such as [16]byte
+func IsByteArrayType(fieldType string) bool {
+	if !strings.HasPrefix(fieldType, "[") {
+		return false
+	}
+	closing := strings.Index(fieldType, "]")
+	if closing <= 1 {
+		return false // Slices ([]byte) are not arrays
 	}
+	elem := fieldType[closing+1:]
+	return elem == "byte" || elem == "uint8"
 }
 
-// getFieldAccessor returns the Go code to access a field (e.g., "s.FieldName")
-func (ta *TypeAnalyzer) getFieldAccessor(field parser.FieldInfo, receiverName string) string {
-	return fmt.Sprintf("%s.%s", receiverName, field.Name)
+// getFieldAccessor returns the Go code to access a field (e.g., "s.FieldName"
+// or "s.Address.Street" for hoisted fields)
+func (ta *TypeAnalyzer) getFieldAccessor(analysis FieldAnalysis, receiverName string) string {
+	return fmt.Sprintf("%s%s.%s", receiverName, analysis.Parent, analysis.Field.Name)
 }
 
 // HasLoggableFields checks if a struct has any fields that should be logged
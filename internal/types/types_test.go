@@ -1,6 +1,10 @@
 package types
 
 import (
+	"fmt"
+	gotypes "go/types"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stuckinforloop/oak/internal/config"
@@ -27,6 +31,11 @@ func TestGetSlogFunction(t *testing.T) {
 		{"uint16", false, SlogInt64},
 		{"uint32", false, SlogInt64},
 		{"uint64", false, SlogInt64},
+		{"uintptr", false, SlogUint64},
+		{"*uintptr", true, SlogUint64},
+
+		// Untyped pointers are logged as their address
+		{"unsafe.Pointer", false, SlogString},
 
 		// Pointer integer types
 		{"*int", true, SlogInt64},
@@ -45,6 +54,33 @@ func TestGetSlogFunction(t *testing.T) {
 		{"float64", false, SlogFloat64},
 		{"*float64", true, SlogFloat64},
 
+		// Fixed-size byte arrays
+		{"[16]byte", false, SlogString},
+		{"[32]uint8", false, SlogString},
+		{"*[16]byte", true, SlogString},
+		{"[4]int", false, SlogAny},
+
+		// Byte slices
+		{"[]byte", false, SlogString},
+		{"*[]byte", true, SlogString},
+
+		// Time types
+		{"time.Time", false, SlogTime},
+		{"*time.Time", true, SlogTime},
+		{"time.Duration", false, SlogDuration},
+
+		// Raw JSON is logged as text
+		{"json.RawMessage", false, SlogString},
+		{"*json.RawMessage", true, SlogString},
+
+		// database/sql Null types log like the value they hold
+		{"sql.NullString", false, SlogString},
+		{"*sql.NullInt32", true, SlogInt64},
+		{"sql.NullByte", false, SlogInt64},
+		{"sql.NullTime", false, SlogTime},
+		{"sql.Null[float32]", false, SlogFloat64},
+		{"sql.Null[[]byte]", false, SlogAny},
+
 		// Complex types
 		{"[]string", false, SlogAny},
 		{"map[string]int", false, SlogAny},
@@ -79,7 +115,7 @@ func TestShouldRedactField(t *testing.T) {
 		expected bool
 	}{
 		// Explicit redact tag
-		{parser.FieldInfo{Name: "Username", LogTag: "redact"}, true},
+		{parser.FieldInfo{Name: "Username", LogOptions: parser.ParseLogTag("redact")}, true},
 
 		// Redact keys (case-insensitive)
 		{parser.FieldInfo{Name: "password"}, true},
@@ -96,14 +132,14 @@ func TestShouldRedactField(t *testing.T) {
 		{parser.FieldInfo{Name: "id"}, false},
 
 		// Skip tag takes precedence (should not be redacted)
-		{parser.FieldInfo{Name: "password", LogTag: "-"}, false},
+		{parser.FieldInfo{Name: "password", LogOptions: parser.ParseLogTag("-")}, false},
 	}
 
 	for _, tc := range testCases {
 		result := analyzer.shouldRedactField(tc.field)
 		if result != tc.expected {
 			t.Errorf("shouldRedactField(%s, tag=%s) = %v, expected %v",
-				tc.field.Name, tc.field.LogTag, result, tc.expected)
+				tc.field.Name, tc.field.LogOptions.Raw, result, tc.expected)
 		}
 	}
 }
@@ -146,9 +182,9 @@ func TestAnalyzeField(t *testing.T) {
 		{
 			name: "redacted field by tag",
 			field: parser.FieldInfo{
-				Name:   "Token",
-				Type:   "string",
-				LogTag: "redact",
+				Name:       "Token",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("redact"),
 			},
 			expected: FieldAnalysis{
 				Action:   ActionRedact,
@@ -159,14 +195,76 @@ func TestAnalyzeField(t *testing.T) {
 		{
 			name: "skipped field",
 			field: parser.FieldInfo{
-				Name:   "Notes",
-				Type:   "string",
-				LogTag: "-",
+				Name:       "Notes",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("-"),
+			},
+			expected: FieldAnalysis{
+				Action: ActionSkip,
+			},
+		},
+		{
+			name: "redacted field by tag with name",
+			field: parser.FieldInfo{
+				Name:       "Token",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("redact,name=tok"),
+			},
+			expected: FieldAnalysis{
+				Action:   ActionRedact,
+				SlogFunc: SlogString,
+				LogValue: "[HIDDEN]",
+			},
+		},
+		{
+			name: "skipped field with other options",
+			field: parser.FieldInfo{
+				Name:       "Notes",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("-,name=notes"),
 			},
 			expected: FieldAnalysis{
 				Action: ActionSkip,
 			},
 		},
+		{
+			name: "masked string field",
+			field: parser.FieldInfo{
+				Name:       "CardNumber",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("mask"),
+			},
+			expected: FieldAnalysis{
+				Action:   ActionMask,
+				SlogFunc: SlogString,
+			},
+		},
+		{
+			name: "masked non-string field is redacted",
+			field: parser.FieldInfo{
+				Name:       "PIN",
+				Type:       "int",
+				LogOptions: parser.ParseLogTag("mask"),
+			},
+			expected: FieldAnalysis{
+				Action:   ActionRedact,
+				SlogFunc: SlogString,
+				LogValue: "[HIDDEN]",
+			},
+		},
+		{
+			name: "redact takes precedence over mask",
+			field: parser.FieldInfo{
+				Name:       "CardNumber",
+				Type:       "string",
+				LogOptions: parser.ParseLogTag("mask,redact"),
+			},
+			expected: FieldAnalysis{
+				Action:   ActionRedact,
+				SlogFunc: SlogString,
+				LogValue: "[HIDDEN]",
+			},
+		},
 		{
 			name: "integer field",
 			field: parser.FieldInfo{
@@ -225,8 +323,8 @@ func TestAnalyzeStruct(t *testing.T) {
 			{Name: "ID", Type: "int"},
 			{Name: "Username", Type: "string"},
 			{Name: "Password", Type: "string"},
-			{Name: "Notes", Type: "string", LogTag: "-"},
-			{Name: "Token", Type: "string", LogTag: "redact"},
+			{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
+			{Name: "Token", Type: "string", LogOptions: parser.ParseLogTag("redact")},
 		},
 	}
 
@@ -273,8 +371,8 @@ func TestHasLoggableFields(t *testing.T) {
 		{
 			name: "all fields skipped",
 			fields: []parser.FieldInfo{
-				{Name: "Field1", Type: "string", LogTag: "-"},
-				{Name: "Field2", Type: "int", LogTag: "-"},
+				{Name: "Field1", Type: "string", LogOptions: parser.ParseLogTag("-")},
+				{Name: "Field2", Type: "int", LogOptions: parser.ParseLogTag("-")},
 			},
 			expected: false,
 		},
@@ -282,7 +380,7 @@ func TestHasLoggableFields(t *testing.T) {
 			name: "mixed fields",
 			fields: []parser.FieldInfo{
 				{Name: "ID", Type: "int"},
-				{Name: "Notes", Type: "string", LogTag: "-"},
+				{Name: "Notes", Type: "string", LogOptions: parser.ParseLogTag("-")},
 			},
 			expected: true,
 		},
@@ -355,6 +453,61 @@ func TestGenerateLogStatement(t *testing.T) {
 			},
 			expected: `slog.Int64("Age", int64(u.Age))`,
 		},
+		{
+			name: "field with explicit key name",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "GuestName", Type: "string", LogOptions: parser.ParseLogTag("name=guest_name")},
+				Action:   ActionLog,
+				SlogFunc: SlogString,
+			},
+			expected: `slog.String("guest_name", u.GuestName)`,
+		},
+		{
+			name: "redacted field with explicit key name",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "Password", LogOptions: parser.ParseLogTag("redact,name=pw")},
+				Action:   ActionRedact,
+				SlogFunc: SlogString,
+				LogValue: "[HIDDEN]",
+			},
+			expected: `slog.String("pw", "[HIDDEN]")`,
+		},
+		{
+			name: "byte array field",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "UUID", Type: "[16]byte"},
+				Action:   ActionLog,
+				SlogFunc: SlogString,
+			},
+			expected: `slog.String("UUID", hex.EncodeToString(u.UUID[:]))`,
+		},
+		{
+			name: "byte slice field",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "Payload", Type: "[]byte"},
+				Action:   ActionLog,
+				SlogFunc: SlogString,
+			},
+			expected: `slog.String("Payload", base64.StdEncoding.EncodeToString(u.Payload))`,
+		},
+		{
+			name: "time field",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "CreatedAt", Type: "time.Time"},
+				Action:   ActionLog,
+				SlogFunc: SlogTime,
+			},
+			expected: `slog.Time("CreatedAt", u.CreatedAt)`,
+		},
+		{
+			name: "duration field",
+			analysis: FieldAnalysis{
+				Field:    parser.FieldInfo{Name: "Timeout", Type: "time.Duration"},
+				Action:   ActionLog,
+				SlogFunc: SlogDuration,
+			},
+			expected: `slog.Duration("Timeout", u.Timeout)`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -366,3 +519,3062 @@ func TestGenerateLogStatement(t *testing.T) {
 		})
 	}
 }
+
+func TestIsByteArrayType(t *testing.T) {
+	testCases := []struct {
+		fieldType string
+		expected  bool
+	}{
+		{"[16]byte", true},
+		{"[32]uint8", true},
+		{"[Size]byte", true},
+		{"[]byte", false},
+		{"[16]int", false},
+		{"[4][4]byte", false},
+		{"string", false},
+	}
+
+	for _, tc := range testCases {
+		result := IsByteArrayType(tc.fieldType)
+		if result != tc.expected {
+			t.Errorf("IsByteArrayType(%s) = %v, expected %v", tc.fieldType, result, tc.expected)
+		}
+	}
+}
+
+func TestAnalyzeFieldImports(t *testing.T) {
+	testCases := []struct {
+		name       string
+		timeFormat string
+		field      parser.FieldInfo
+		expected   []string
+	}{
+		{"string field", "", parser.FieldInfo{Name: "Name", Type: "string"}, nil},
+		{"byte array field", "", parser.FieldInfo{Name: "ID", Type: "[16]byte"}, []string{"encoding/hex"}},
+		{"byte slice field", "", parser.FieldInfo{Name: "Data", Type: "[]byte"}, []string{"encoding/base64"}},
+		{"time field", "", parser.FieldInfo{Name: "At", Type: "time.Time"}, nil},
+		{"time field with layout constant", "RFC3339", parser.FieldInfo{Name: "At", Type: "time.Time"}, []string{"time"}},
+		{"time field with custom layout", "2006-01-02", parser.FieldInfo{Name: "At", Type: "time.Time"}, nil},
+		{"redacted byte slice", "", parser.FieldInfo{Name: "Key", Type: "[]byte", LogOptions: parser.ParseLogTag("redact")}, nil},
+		{"unsafe pointer field", "", parser.FieldInfo{Name: "Ptr", Type: "unsafe.Pointer"}, []string{"fmt"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.TimeFormat = tc.timeFormat
+			analyzer := NewTypeAnalyzer(cfg)
+
+			result := analyzer.AnalyzeField(tc.field)
+			if len(result.Imports) != len(tc.expected) {
+				t.Fatalf("Imports: expected %v, got %v", tc.expected, result.Imports)
+			}
+			for i, imp := range tc.expected {
+				if result.Imports[i] != imp {
+					t.Errorf("Import %d: expected %s, got %s", i, imp, result.Imports[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLogStatementTimeFormat(t *testing.T) {
+	testCases := []struct {
+		timeFormat string
+		field      parser.FieldInfo
+		expected   string
+	}{
+		{
+			timeFormat: "RFC3339",
+			field:      parser.FieldInfo{Name: "At", Type: "time.Time"},
+			expected:   `slog.String("At", u.At.Format(time.RFC3339))`,
+		},
+		{
+			timeFormat: "2006-01-02",
+			field:      parser.FieldInfo{Name: "At", Type: "time.Time"},
+			expected:   `slog.String("At", u.At.Format("2006-01-02"))`,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.DefaultConfig()
+		cfg.TimeFormat = tc.timeFormat
+		analyzer := NewTypeAnalyzer(cfg)
+
+		result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(tc.field), "u")
+		if result != tc.expected {
+			t.Errorf("GenerateLogStatement() with %s = %q, expected %q", tc.timeFormat, result, tc.expected)
+		}
+	}
+}
+
+func TestGenerateLogStatementDurationFormat(t *testing.T) {
+	testCases := []struct {
+		durationFormat string
+		field          parser.FieldInfo
+		expected       string
+		zerolog        string
+	}{
+		{
+			durationFormat: config.DurationFormatNanos,
+			field:          parser.FieldInfo{Name: "Timeout", Type: "time.Duration"},
+			expected:       `slog.Duration("Timeout", u.Timeout)`,
+			zerolog:        `Dur("Timeout", u.Timeout)`,
+		},
+		{
+			durationFormat: config.DurationFormatString,
+			field:          parser.FieldInfo{Name: "Timeout", Type: "time.Duration"},
+			expected:       `slog.String("Timeout", u.Timeout.String())`,
+			zerolog:        `Str("Timeout", u.Timeout.String())`,
+		},
+		{
+			durationFormat: config.DurationFormatString,
+			field:          parser.FieldInfo{Name: "Timeout", Type: "*time.Duration", IsPointer: true},
+			expected: `func() slog.Attr {
+if u.Timeout == nil {
+return slog.String("Timeout", "null")
+}
+return slog.String("Timeout", u.Timeout.String())
+}()`,
+		},
+		{
+			durationFormat: config.DurationFormatString,
+			field:          parser.FieldInfo{Name: "Timeout", Type: "sql.Null[time.Duration]"},
+			expected: `func() slog.Attr {
+if !u.Timeout.Valid {
+return slog.String("Timeout", "null")
+}
+return slog.String("Timeout", u.Timeout.V.String())
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.DefaultConfig()
+		cfg.DurationFormat = tc.durationFormat
+		analyzer := NewTypeAnalyzer(cfg)
+
+		analysis := analyzer.AnalyzeField(tc.field)
+		if result := analyzer.GenerateLogStatement(analysis, "u"); result != tc.expected {
+			t.Errorf("GenerateLogStatement() of %s with %s = %q, expected %q", tc.field.Type, tc.durationFormat, result, tc.expected)
+		}
+		if len(analysis.Imports) != 0 {
+			t.Errorf("Imports of %s with %s: expected none, got %v", tc.field.Type, tc.durationFormat, analysis.Imports)
+		}
+		if tc.zerolog == "" {
+			continue
+		}
+		if result := NewZerologEmitter(analyzer).Field(analysis, "u"); result != tc.zerolog {
+			t.Errorf("zerolog Field() of %s with %s = %q, expected %q", tc.field.Type, tc.durationFormat, result, tc.zerolog)
+		}
+	}
+}
+
+func TestGenerateLogStatementForcedFunc(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+		zerolog  string
+		imports  []string
+	}{
+		{
+			name:     "int64 as duration",
+			field:    parser.FieldInfo{Name: "Timeout", Type: "int64", LogOptions: parser.ParseLogTag("func=slog.Duration")},
+			expected: `slog.Duration("Timeout", time.Duration(u.Timeout))`,
+			zerolog:  `Dur("Timeout", time.Duration(u.Timeout))`,
+			imports:  []string{"time"},
+		},
+		{
+			name:     "duration needs no conversion",
+			field:    parser.FieldInfo{Name: "Timeout", Type: "time.Duration", LogOptions: parser.ParseLogTag("func=slog.Duration")},
+			expected: `slog.Duration("Timeout", u.Timeout)`,
+			zerolog:  `Dur("Timeout", u.Timeout)`,
+		},
+		{
+			name:     "named string type as string",
+			field:    parser.FieldInfo{Name: "Status", Type: "Status", LogOptions: parser.ParseLogTag("func=slog.String,name=status")},
+			expected: `slog.String("status", string(u.Status))`,
+			zerolog:  `Str("status", string(u.Status))`,
+		},
+		{
+			name:     "int as slog.Int",
+			field:    parser.FieldInfo{Name: "Count", Type: "int", LogOptions: parser.ParseLogTag("func=slog.Int")},
+			expected: `slog.Int64("Count", int64(u.Count))`,
+			zerolog:  `Int64("Count", int64(u.Count))`,
+		},
+		{
+			name:     "struct as any",
+			field:    parser.FieldInfo{Name: "Meta", Type: "Meta", LogOptions: parser.ParseLogTag("func=slog.Any")},
+			expected: `slog.Any("Meta", u.Meta)`,
+			zerolog:  `Interface("Meta", u.Meta)`,
+		},
+		{
+			name:  "pointer",
+			field: parser.FieldInfo{Name: "Retries", Type: "*uint8", IsPointer: true, LogOptions: parser.ParseLogTag("func=slog.Uint64")},
+			expected: `func() slog.Attr {
+if u.Retries == nil {
+return slog.String("Retries", "null")
+}
+return slog.Uint64("Retries", uint64(*u.Retries))
+}()`,
+		},
+		{
+			name:     "redaction takes precedence",
+			field:    parser.FieldInfo{Name: "Token", Type: "string", LogOptions: parser.ParseLogTag("redact,func=slog.Any")},
+			expected: `slog.String("Token", "[REDACTED]")`,
+			zerolog:  `Str("Token", "[REDACTED]")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "u"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+			if !reflect.DeepEqual(analysis.Imports, tc.imports) {
+				t.Errorf("Imports: expected %v, got %v", tc.imports, analysis.Imports)
+			}
+			if tc.zerolog == "" {
+				return
+			}
+			if result := NewZerologEmitter(analyzer).Field(analysis, "u"); result != tc.zerolog {
+				t.Errorf("zerolog Field() = %q, expected %q", result, tc.zerolog)
+			}
+		})
+	}
+}
+
+func TestValidateLogFunc(t *testing.T) {
+	if err := ValidateLogFunc("slog.Duration"); err != nil {
+		t.Errorf("Expected slog.Duration to be valid, got %v", err)
+	}
+
+	err := ValidateLogFunc("slog.Group")
+	if err == nil {
+		t.Fatalf("Expected error for slog.Group")
+	}
+	expected := `invalid log function "slog.Group": must be one of slog.Any, slog.Bool, slog.Duration, slog.Float64, slog.Int, slog.Int64, slog.String, slog.Time, slog.Uint64`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestGenerateLogStatementBoolStrings(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+		zerolog  string
+	}{
+		{
+			name:     "default bool",
+			field:    parser.FieldInfo{Name: "Active", Type: "bool"},
+			expected: `slog.Bool("Active", u.Active)`,
+			zerolog:  `Bool("Active", u.Active)`,
+		},
+		{
+			name:  "custom strings",
+			field: parser.FieldInfo{Name: "Active", Type: "bool", LogOptions: parser.ParseLogTag("bool=enabled/disabled")},
+			expected: `func() slog.Attr {
+if u.Active {
+return slog.String("Active", "enabled")
+}
+return slog.String("Active", "disabled")
+}()`,
+			zerolog: `Func(func(evt *zerolog.Event) {
+if u.Active {
+evt.Str("Active", "enabled")
+return
+}
+evt.Str("Active", "disabled")
+})`,
+		},
+		{
+			name:  "pointer",
+			field: parser.FieldInfo{Name: "Admin", Type: "*bool", IsPointer: true, LogOptions: parser.ParseLogTag("bool=yes/no")},
+			expected: `func() slog.Attr {
+if u.Admin == nil {
+return slog.String("Admin", "null")
+}
+return func() slog.Attr {
+if *u.Admin {
+return slog.String("Admin", "yes")
+}
+return slog.String("Admin", "no")
+}()
+}()`,
+		},
+		{
+			name:     "ignored on other types",
+			field:    parser.FieldInfo{Name: "Count", Type: "int", LogOptions: parser.ParseLogTag("bool=yes/no")},
+			expected: `slog.Int64("Count", int64(u.Count))`,
+			zerolog:  `Int64("Count", int64(u.Count))`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "u"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+			if tc.zerolog == "" {
+				return
+			}
+			if result := NewZerologEmitter(analyzer).Field(analysis, "u"); result != tc.zerolog {
+				t.Errorf("zerolog Field() = %q, expected %q", result, tc.zerolog)
+			}
+		})
+	}
+}
+
+func TestValidateLogOptions(t *testing.T) {
+	testCases := []struct {
+		tag      string
+		expected string
+	}{
+		{"redact,name=pw", ""},
+		{"redcat", `unknown log tag option "redcat": must be one of -, log, redact, mask, hash, omitzero, always, name=, func=, bool=`},
+		{"func=slog.Group", `invalid log function "slog.Group": must be one of slog.Any,`},
+		{"bool=on", `invalid bool strings "on": must be <true>/<false>, e.g. enabled/disabled`},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateLogOptions(parser.ParseLogTag(tc.tag))
+		if tc.expected == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", tc.tag, err)
+			}
+			continue
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+			t.Errorf("%s: expected error %q, got %v", tc.tag, tc.expected, err)
+		}
+	}
+}
+
+func TestBoolStrings(t *testing.T) {
+	trueText, falseText, err := BoolStrings("on/off")
+	if err != nil || trueText != "on" || falseText != "off" {
+		t.Errorf("Expected on and off, got %q, %q, %v", trueText, falseText, err)
+	}
+
+	for _, option := range []string{"on", "on/", "/off", "a/b/c"} {
+		if _, _, err := BoolStrings(option); err == nil {
+			t.Errorf("Expected error for %q", option)
+		}
+	}
+}
+
+func TestGenerateLogStatementKeyCase(t *testing.T) {
+	testCases := []struct {
+		keyCase  string
+		expected string
+	}{
+		{config.KeyCaseAsIs, `slog.Int64("UserID", int64(u.UserID))`},
+		{config.KeyCaseSnake, `slog.Int64("user_id", int64(u.UserID))`},
+		{config.KeyCaseCamel, `slog.Int64("userId", int64(u.UserID))`},
+		{config.KeyCaseKebab, `slog.Int64("user-id", int64(u.UserID))`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.keyCase, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.KeyCase = tc.keyCase
+			analyzer := NewTypeAnalyzer(cfg)
+
+			field := parser.FieldInfo{Name: "UserID", Type: "int"}
+			result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(field), "u")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateMaskStatement(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	field := parser.FieldInfo{Name: "CardNumber", Type: "string", LogOptions: parser.ParseLogTag("mask,name=card")}
+	analysis := analyzer.AnalyzeField(field)
+	result := analyzer.GenerateLogStatement(analysis, "u")
+
+	expectedElements := []string{
+		// Characters rather than bytes are masked, so multi-byte characters
+		// are kept whole
+		"v := []rune(u.CardNumber)",
+		`strings.Repeat("*", len(v)-4)+string(v[len(v)-4:])`,
+		`slog.String("card", strings.Repeat("*", len(v)))`,
+	}
+	for _, expected := range expectedElements {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Mask statement missing %q, got:\n%s", expected, result)
+		}
+	}
+
+	if len(analysis.Imports) != 1 || analysis.Imports[0] != "strings" {
+		t.Errorf("Expected mask to import strings, got %v", analysis.Imports)
+	}
+
+	pointerField := parser.FieldInfo{Name: "CardNumber", Type: "*string", IsPointer: true, LogOptions: parser.ParseLogTag("mask")}
+	result = analyzer.GenerateLogStatement(analyzer.AnalyzeField(pointerField), "u")
+	if !strings.Contains(result, "if u.CardNumber == nil") || !strings.Contains(result, "v := []rune(*u.CardNumber)") {
+		t.Errorf("Pointer mask statement should be nil-safe, got:\n%s", result)
+	}
+}
+
+func TestGenerateRedactStatement(t *testing.T) {
+	cfg := &config.Config{
+		RedactKeys:    []string{"password", "pin", "apikeys"},
+		RedactMessage: "[HIDDEN]",
+	}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			name:     "redacted string field",
+			field:    parser.FieldInfo{Name: "Password", Type: "string"},
+			expected: `u.Password = "[HIDDEN]"`,
+		},
+		{
+			name:     "masked string field",
+			field:    parser.FieldInfo{Name: "Card", Type: "string", LogOptions: parser.ParseLogTag("mask")},
+			expected: `u.Card = "[HIDDEN]"`,
+		},
+		{
+			name:     "redacted int field is zeroed",
+			field:    parser.FieldInfo{Name: "PIN", Type: "int"},
+			expected: `u.PIN = 0`,
+		},
+		{
+			name:     "redacted slice field is zeroed",
+			field:    parser.FieldInfo{Name: "APIKeys", Type: "[]string"},
+			expected: `u.APIKeys = nil`,
+		},
+		{
+			name:     "redacted struct field is zeroed",
+			field:    parser.FieldInfo{Name: "Token", Type: "oauth.Token", LogOptions: parser.ParseLogTag("redact")},
+			expected: `u.Token = *new(oauth.Token)`,
+		},
+		{
+			name:     "logged field needs no statement",
+			field:    parser.FieldInfo{Name: "Username", Type: "string"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.GenerateRedactStatement(analyzer.AnalyzeField(tc.field), "u")
+			if result != tc.expected {
+				t.Errorf("GenerateRedactStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	pointerField := parser.FieldInfo{Name: "Password", Type: "*string", IsPointer: true}
+	result := analyzer.GenerateRedactStatement(analyzer.AnalyzeField(pointerField), "u")
+	if !strings.Contains(result, "if u.Password != nil") || !strings.Contains(result, "u.Password = &redacted") {
+		t.Errorf("Pointer string should be replaced only when non-nil, got:\n%s", result)
+	}
+}
+
+func TestGenerateLogStatementNilInterfaces(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+	field := parser.FieldInfo{Name: "Payload", Type: "*any", IsPointer: true}
+
+	// A nil pointer to the interface logs null, and the interface holding a
+	// nil pointer logs its type
+	expected := `if u.Payload == nil {
+attrs = append(attrs, slog.String("Payload", "null"))
+} else if reflect.ValueOf(*u.Payload).Kind() == reflect.Pointer && reflect.ValueOf(*u.Payload).IsNil() {
+attrs = append(attrs, slog.String("Payload", fmt.Sprintf("%T(nil)", *u.Payload)))
+} else {
+attrs = append(attrs, slog.Any("Payload", *u.Payload))
+}`
+	if result := NewSlogEmitter(analyzer).Field(analyzer.AnalyzeField(field), "u"); result != expected {
+		t.Errorf("slog Field() = %q, expected %q", result, expected)
+	}
+
+	expected = `Func(func(evt *zerolog.Event) {
+if reflect.ValueOf(u.Err).Kind() == reflect.Pointer && reflect.ValueOf(u.Err).IsNil() {
+evt.Str("Err", fmt.Sprintf("%T(nil)", u.Err))
+return
+}
+evt.Interface("Err", u.Err)
+})`
+	field = parser.FieldInfo{Name: "Err", Type: "error"}
+	if result := NewZerologEmitter(analyzer).Field(analyzer.AnalyzeField(field), "u"); result != expected {
+		t.Errorf("zerolog Field() = %q, expected %q", result, expected)
+	}
+}
+
+func TestTypedNil(t *testing.T) {
+	var nilBuffer *strings.Builder
+	testCases := []struct {
+		name     string
+		value    any
+		expected bool
+	}{
+		{"nil interface", nil, false},
+		{"typed nil pointer", nilBuffer, true},
+		{"typed nil error", error((*gotypes.Error)(nil)), true},
+		{"pointer", &strings.Builder{}, false},
+		{"nil slice", []string(nil), false},
+		{"value", 42, false},
+	}
+
+	if condition := typedNil("value"); condition != "reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()" {
+		t.Fatalf("typedNil() = %q", condition)
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// The condition emitted by typedNil, evaluated for the value
+			value := tc.value
+			if result := reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil(); result != tc.expected {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateLogStatementInterfaceTypes(t *testing.T) {
+	testCases := []struct {
+		name              string
+		logInterfaceTypes bool
+		field             parser.FieldInfo
+		expected          string
+		expectedImports   int
+	}{
+		{
+			name:  "interface field without option",
+			field: parser.FieldInfo{Name: "Payload", Type: "interface{}"},
+			expected: `func() slog.Attr {
+if reflect.ValueOf(u.Payload).Kind() == reflect.Pointer && reflect.ValueOf(u.Payload).IsNil() {
+return slog.String("Payload", fmt.Sprintf("%T(nil)", u.Payload))
+}
+return slog.Any("Payload", u.Payload)
+}()`,
+			expectedImports: 2,
+		},
+		{
+			name:              "interface field with option",
+			logInterfaceTypes: true,
+			field:             parser.FieldInfo{Name: "Payload", Type: "interface{}"},
+			expected: `func() slog.Attr {
+if reflect.ValueOf(u.Payload).Kind() == reflect.Pointer && reflect.ValueOf(u.Payload).IsNil() {
+return slog.String("Payload", fmt.Sprintf("%T(nil)", u.Payload))
+}
+return slog.Group("Payload", slog.String("type", fmt.Sprintf("%T", u.Payload)), slog.Any("value", u.Payload))
+}()`,
+			expectedImports: 2,
+		},
+		{
+			name:              "error field with option",
+			logInterfaceTypes: true,
+			field:             parser.FieldInfo{Name: "Err", Type: "error"},
+			expected: `func() slog.Attr {
+if reflect.ValueOf(u.Err).Kind() == reflect.Pointer && reflect.ValueOf(u.Err).IsNil() {
+return slog.String("Err", fmt.Sprintf("%T(nil)", u.Err))
+}
+return slog.Group("Err", slog.String("type", fmt.Sprintf("%T", u.Err)), slog.Any("value", u.Err))
+}()`,
+			expectedImports: 2,
+		},
+		{
+			name:              "struct field with option",
+			logInterfaceTypes: true,
+			field:             parser.FieldInfo{Name: "Nested", Type: "Nested"},
+			expected:          `slog.Any("Nested", u.Nested)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.LogInterfaceTypes = tc.logInterfaceTypes
+			analyzer := NewTypeAnalyzer(cfg)
+
+			analysis := analyzer.AnalyzeField(tc.field)
+			result := analyzer.GenerateLogStatement(analysis, "u")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+			if len(analysis.Imports) != tc.expectedImports {
+				t.Errorf("Expected %d imports, got %v", tc.expectedImports, analysis.Imports)
+			}
+		})
+	}
+}
+
+func TestAnalyzeStructAliases(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	structInfo := parser.StructInfo{
+		Name:        "Reading",
+		PackageName: "main",
+		Fields: []parser.FieldInfo{
+			{Name: "Temp", Type: "Celsius"},
+			{Name: "Count", Type: "IntPtr"},
+			{Name: "Max", Type: "*Celsius", IsPointer: true},
+			{Name: "Alt", Type: "Temperature"},
+		},
+		Aliases: map[string]string{
+			"Celsius":     "float64",
+			"IntPtr":      "*int",
+			"Temperature": "Celsius",
+		},
+	}
+
+	expected := []string{
+		`slog.Float64("Temp", r.Temp)`,
+		`func() slog.Attr {
+if r.Count == nil {
+return slog.String("Count", "null")
+}
+return slog.Int64("Count", int64(*r.Count))
+}()`,
+		`func() slog.Attr {
+if r.Max == nil {
+return slog.String("Max", "null")
+}
+return slog.Float64("Max", float64(*r.Max))
+}()`,
+		`slog.Float64("Alt", r.Alt)`,
+	}
+
+	analyses := analyzer.AnalyzeStruct(structInfo)
+	if len(analyses) != len(expected) {
+		t.Fatalf("Expected %d field analyses, got %d", len(expected), len(analyses))
+	}
+	for i, analysis := range analyses {
+		if result := analyzer.GenerateLogStatement(analysis, "r"); result != expected[i] {
+			t.Errorf("%s: expected %q, got %q", analysis.Field.Name, expected[i], result)
+		}
+	}
+
+	// Alias cycles are not followed forever
+	cyclic := parser.StructInfo{
+		Name:    "Cyclic",
+		Fields:  []parser.FieldInfo{{Name: "A", Type: "A"}},
+		Aliases: map[string]string{"A": "B", "B": "A"},
+	}
+	if analyses := analyzer.AnalyzeStruct(cyclic); analyses[0].SlogFunc != SlogAny {
+		t.Errorf("Cyclic alias: expected %v, got %v", SlogAny, analyses[0].SlogFunc)
+	}
+}
+
+func TestAnalyzeStructAliasedImports(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	structInfo := parser.StructInfo{
+		Name:        "Event",
+		PackageName: "main",
+		Fields: []parser.FieldInfo{
+			{Name: "At", Type: "t.Time"},
+			{Name: "Took", Type: "*t.Duration", IsPointer: true},
+			{Name: "Stamp", Type: "Stamp"},
+			{Name: "Stamps", Type: "[]t.Time"},
+			{Name: "Other", Type: "time.Time"},
+		},
+		Aliases: map[string]string{"Stamp": "t.Time"},
+		Imports: map[string]string{"t": "time", "time": "example.com/clock"},
+	}
+
+	expected := []struct {
+		slogFunc  SlogFunction
+		fieldType string
+	}{
+		{SlogTime, "time.Time"},
+		{SlogDuration, "*time.Duration"},
+		{SlogTime, "time.Time"},
+		{SlogAny, "[]time.Time"},
+		// A package imported as time is not the time package
+		{SlogAny, "clock.Time"},
+	}
+
+	analyses := analyzer.AnalyzeStruct(structInfo)
+	if len(analyses) != len(expected) {
+		t.Fatalf("Expected %d field analyses, got %d", len(expected), len(analyses))
+	}
+	for i, analysis := range analyses {
+		if analysis.SlogFunc != expected[i].slogFunc {
+			t.Errorf("%s: expected %v, got %v", analysis.Field.Name, expected[i].slogFunc, analysis.SlogFunc)
+		}
+		if analysis.Field.Type != expected[i].fieldType {
+			t.Errorf("%s: expected type %s, got %s", analysis.Field.Name, expected[i].fieldType, analysis.Field.Type)
+		}
+	}
+
+	if result := analyzer.GenerateLogStatement(analyses[0], "e"); result != `slog.Time("At", e.At)` {
+		t.Errorf("At: expected %q, got %q", `slog.Time("At", e.At)`, result)
+	}
+}
+
+func TestAnalyzeStructFieldOrder(t *testing.T) {
+	structInfo := parser.StructInfo{
+		Name:        "User",
+		PackageName: "main",
+		Fields: []parser.FieldInfo{
+			{Name: "Username", Type: "string"},
+			{Name: "ID", Type: "int"},
+			{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("name=auth_pw")},
+			{Name: "active", Type: "bool"},
+		},
+	}
+
+	testCases := []struct {
+		fieldOrder string
+		expected   []string
+	}{
+		{config.FieldOrderSource, []string{"Username", "ID", "Password", "active"}},
+		// Fields sort by their logged key, ignoring case
+		{config.FieldOrderAlphabetical, []string{"active", "Password", "ID", "Username"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fieldOrder, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.FieldOrder = tc.fieldOrder
+			analyzer := NewTypeAnalyzer(cfg)
+
+			var names []string
+			for _, analysis := range analyzer.AnalyzeStruct(structInfo) {
+				names = append(names, analysis.Field.Name)
+			}
+			if !reflect.DeepEqual(names, tc.expected) {
+				t.Errorf("Field order: expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestAnalyzeStructOutputStyle(t *testing.T) {
+	inner := parser.StructInfo{
+		Name: "Inner",
+		Fields: []parser.FieldInfo{
+			{Name: "Count", Type: "int"},
+		},
+	}
+	nested := parser.StructInfo{
+		Name: "NestedExample",
+		Fields: []parser.FieldInfo{
+			{Name: "BoolValue", Type: "bool"},
+			{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+			{Name: "Inner", Type: "Inner"},
+		},
+	}
+	example := parser.StructInfo{
+		Name: "Example",
+		Fields: []parser.FieldInfo{
+			{Name: "ID", Type: "int"},
+			{Name: "Nested", Type: "NestedExample"},
+		},
+	}
+	known := []parser.StructInfo{inner, nested, example}
+
+	testCases := []struct {
+		style    string
+		expected []string
+	}{
+		{
+			style: config.OutputStyleGrouped,
+			expected: []string{
+				`slog.Int64("ID", int64(e.ID))`,
+				`slog.Attr{Key: "Nested", Value: e.Nested.LogValue()}`,
+			},
+		},
+		{
+			style: config.OutputStyleFlattened,
+			expected: []string{
+				`slog.Int64("ID", int64(e.ID))`,
+				`slog.Bool("Nested.BoolValue", e.Nested.BoolValue)`,
+				`slog.String("Nested.Secret", "[REDACTED]")`,
+				`slog.Int64("Nested.Inner.Count", int64(e.Nested.Inner.Count))`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputStyle = tc.style
+			analyzer := NewTypeAnalyzer(cfg).WithKnownStructs(known)
+
+			var statements []string
+			for _, analysis := range analyzer.AnalyzeStruct(example) {
+				statements = append(statements, analyzer.GenerateLogStatement(analysis, "e"))
+			}
+
+			if len(statements) != len(tc.expected) {
+				t.Fatalf("Expected %d statements, got %d: %v", len(tc.expected), len(statements), statements)
+			}
+			for i, expected := range tc.expected {
+				if statements[i] != expected {
+					t.Errorf("Statement %d: expected %q, got %q", i, expected, statements[i])
+				}
+			}
+		})
+	}
+
+	// Without the struct being known, the field falls back to slog.Any
+	analysis := NewTypeAnalyzer(config.DefaultConfig()).AnalyzeField(example.Fields[1])
+	if analysis.Nested != nil {
+		t.Errorf("Unknown struct should not be treated as nested")
+	}
+}
+
+func TestAnalyzeStructRedactedNestedStruct(t *testing.T) {
+	address := parser.StructInfo{
+		Name: "Address",
+		Fields: []parser.FieldInfo{
+			{Name: "Street", Type: "string"},
+			{Name: "City", Type: "string"},
+		},
+	}
+	user := parser.StructInfo{
+		Name: "User",
+		Fields: []parser.FieldInfo{
+			{Name: "Home", Type: "Address", LogOptions: parser.ParseLogTag("redact")},
+			{Name: "Work", Type: "*Address", IsPointer: true, LogOptions: parser.ParseLogTag("redact")},
+			{Name: "Billing", Type: "Address"},
+		},
+	}
+
+	// Redacted fields log a single string in place of the nested group, or
+	// of the hoisted fields, whatever the output style
+	for _, style := range []string{config.OutputStyleGrouped, config.OutputStyleFlattened} {
+		t.Run(style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputStyle = style
+			cfg.RedactKeys = []string{"billing"}
+			analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{address, user})
+
+			analyses := analyzer.AnalyzeStruct(user)
+			if len(analyses) != 3 {
+				t.Fatalf("Expected 3 analyses, got %d", len(analyses))
+			}
+
+			expected := []string{
+				`slog.String("Home", "[REDACTED]")`,
+				`slog.String("Work", "[REDACTED]")`,
+				`slog.String("Billing", "[REDACTED]")`,
+			}
+			for i, analysis := range analyses {
+				if analysis.Action != ActionRedact || analysis.Nested != nil {
+					t.Errorf("%s: expected redacted field without nested struct, got %s with %v", analysis.Field.Name, analysis.Action, analysis.Nested)
+				}
+				if statement := analyzer.GenerateLogStatement(analysis, "u"); statement != expected[i] {
+					t.Errorf("%s: expected %q, got %q", analysis.Field.Name, expected[i], statement)
+				}
+			}
+
+			// Redacted copies reset the nested struct to its zero value
+			if statement := analyzer.GenerateRedactStatement(analyses[0], "u"); statement != "u.Home = *new(Address)" {
+				t.Errorf("GenerateRedactStatement() = %q, expected %q", statement, "u.Home = *new(Address)")
+			}
+
+			emitter := NewZerologEmitter(analyzer)
+			if link := emitter.Field(analyses[1], "u"); link != `Str("Work", "[REDACTED]")` {
+				t.Errorf("Field() = %q, expected %q", link, `Str("Work", "[REDACTED]")`)
+			}
+		})
+	}
+}
+
+func TestGenerateRedactStatementsNestedStruct(t *testing.T) {
+	creds := parser.StructInfo{
+		Name:   "Creds",
+		Fields: []parser.FieldInfo{{Name: "Password", Type: "string"}},
+	}
+	account := parser.StructInfo{
+		Name: "Account",
+		Fields: []parser.FieldInfo{
+			{Name: "Token", Type: "string"},
+			{Name: "Creds", Type: "Creds"},
+			{Name: "PCreds", Type: "*Creds", IsPointer: true},
+		},
+	}
+
+	// Nested structs are replaced by their redacted copies whatever the
+	// output style, so fields hoisted through pointers are not dropped
+	for _, style := range []string{config.OutputStyleGrouped, config.OutputStyleFlattened} {
+		t.Run(style, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputStyle = style
+			cfg.RedactKeys = []string{"password", "token"}
+			analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{creds, account})
+
+			expected := []string{
+				`a.Token = "[REDACTED]"`,
+				`a.Creds = a.Creds.Redacted()`,
+				`if a.PCreds != nil {
+redacted := a.PCreds.Redacted()
+a.PCreds = &redacted
+}`,
+			}
+			if statements := analyzer.GenerateRedactStatements(account, "a"); !reflect.DeepEqual(statements, expected) {
+				t.Errorf("GenerateRedactStatements() = %q, expected %q", statements, expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeStructFlattenedKeyCase(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OutputStyle = config.OutputStyleFlattened
+	cfg.KeyCase = config.KeyCaseSnake
+
+	address := parser.StructInfo{
+		Name:   "Address",
+		Fields: []parser.FieldInfo{{Name: "ZipCode", Type: "string"}},
+	}
+	user := parser.StructInfo{
+		Name:   "User",
+		Fields: []parser.FieldInfo{{Name: "HomeAddress", Type: "Address", LogOptions: parser.ParseLogTag("name=home")}},
+	}
+	analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{address, user})
+
+	analyses := analyzer.AnalyzeStruct(user)
+	if len(analyses) != 1 {
+		t.Fatalf("Expected 1 hoisted field, got %d", len(analyses))
+	}
+
+	expected := `slog.String("home.zip_code", u.HomeAddress.ZipCode)`
+	if result := analyzer.GenerateLogStatement(analyses[0], "u"); result != expected {
+		t.Errorf("GenerateLogStatement() = %q, expected %q", result, expected)
+	}
+}
+
+func TestAnalyzeStructEmbeddedPointer(t *testing.T) {
+	address := parser.StructInfo{
+		Name:   "Address",
+		Fields: []parser.FieldInfo{{Name: "City", Type: "string"}},
+	}
+	user := parser.StructInfo{
+		Name: "User",
+		Fields: []parser.FieldInfo{
+			{Name: "Name", Type: "string"},
+			{Name: "Address", Type: "*Address", IsPointer: true, Embedded: true},
+		},
+	}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{address, user})
+
+	// The fields of the embedded pointer are promoted, and omitted when it is nil
+	analyses := analyzer.AnalyzeStruct(user)
+	if len(analyses) != 2 {
+		t.Fatalf("Expected 2 fields, got %d", len(analyses))
+	}
+	expected := `func() slog.Attr {
+if u.Address == nil {
+return slog.Attr{}
+}
+return slog.String("City", u.Address.City)
+}()`
+	if result := analyzer.GenerateLogStatement(analyses[1], "u"); result != expected {
+		t.Errorf("GenerateLogStatement() = %q, expected %q", result, expected)
+	}
+	expected = `Func(func(evt *zerolog.Event) {
+if u.Address == nil {
+return
+}
+evt.Str("City", u.Address.City)
+})`
+	if result := NewZerologEmitter(analyzer).Field(analyses[1], "u"); result != expected {
+		t.Errorf("zerolog Field() = %q, expected %q", result, expected)
+	}
+
+	// The fields of an embedded value are promoted without a nil check
+	user.Fields[1] = parser.FieldInfo{Name: "Address", Type: "Address", Embedded: true}
+	analyses = analyzer.AnalyzeStruct(user)
+	if len(analyses) != 2 {
+		t.Fatalf("Expected 2 fields, got %d", len(analyses))
+	}
+	if result := analyzer.GenerateLogStatement(analyses[1], "u"); result != `slog.String("City", u.Address.City)` {
+		t.Errorf("GenerateLogStatement() of embedded value = %q", result)
+	}
+
+	// An embedded pointer given a key by its tag is logged as a group
+	user.Fields[1] = parser.FieldInfo{Name: "Address", Type: "*Address", IsPointer: true, Embedded: true, LogOptions: parser.ParseLogTag("name=address")}
+	analyses = analyzer.AnalyzeStruct(user)
+	if len(analyses) != 2 || analyses[1].Nested == nil {
+		t.Errorf("Expected a nested group, got %+v", analyses)
+	}
+}
+
+func TestAnalyzeStructEmbeddedShadowing(t *testing.T) {
+	node := parser.StructInfo{
+		Name: "Node",
+		Fields: []parser.FieldInfo{
+			{Name: "Val", Type: "int"},
+			{Name: "ID", Type: "string"},
+			{Name: "Leaf", Type: "*Leaf", IsPointer: true, Embedded: true},
+		},
+	}
+	leaf := parser.StructInfo{
+		Name:   "Leaf",
+		Fields: []parser.FieldInfo{{Name: "ID", Type: "string"}, {Name: "Kind", Type: "string"}},
+	}
+	edge := parser.StructInfo{
+		Name:   "Edge",
+		Fields: []parser.FieldInfo{{Name: "Kind", Type: "string"}},
+	}
+	tree := parser.StructInfo{
+		Name: "Tree",
+		Fields: []parser.FieldInfo{
+			{Name: "Val", Type: "string"},
+			{Name: "Node", Type: "*Node", IsPointer: true, Embedded: true},
+			{Name: "Edge", Type: "Edge", Embedded: true},
+		},
+	}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{node, leaf, edge, tree})
+
+	// Tree.Val shadows Node.Val, Node.ID shadows Leaf.ID, and Edge.Kind
+	// shadows Leaf.Kind, which is promoted through more embedded structs
+	var keys []string
+	for _, analysis := range analyzer.AnalyzeStruct(tree) {
+		keys = append(keys, analyzer.attributeKey(analysis)+" "+analysis.Parent)
+	}
+	expected := []string{"Val ", "ID .Node", "Kind .Edge"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	// Promoted fields sharing a key at the same depth are ambiguous
+	tree.Fields[0] = parser.FieldInfo{Name: "Name", Type: "string"}
+	edge.Fields[0] = parser.FieldInfo{Name: "Val", Type: "int"}
+	analyzer = NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{node, leaf, edge, tree})
+	keys = nil
+	for _, analysis := range analyzer.AnalyzeStruct(tree) {
+		keys = append(keys, analyzer.attributeKey(analysis)+" "+analysis.Parent)
+	}
+	expected = []string{"Name ", "ID .Node", "Kind .Node.Leaf"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+}
+
+func TestAnalyzeFieldPointerToStruct(t *testing.T) {
+	nested := parser.StructInfo{
+		Name: "NestedExample",
+		Fields: []parser.FieldInfo{
+			{Name: "BoolValue", Type: "bool"},
+			{Name: "Next", Type: "*NestedExample", IsPointer: true},
+		},
+	}
+	example := parser.StructInfo{
+		Name:   "Example",
+		Fields: []parser.FieldInfo{{Name: "Nested", Type: "*NestedExample", IsPointer: true}},
+	}
+	known := []parser.StructInfo{nested, example}
+
+	t.Run("grouped", func(t *testing.T) {
+		analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs(known)
+
+		analysis := analyzer.AnalyzeField(example.Fields[0])
+		if analysis.Nested == nil || analysis.Nested.Name != "NestedExample" {
+			t.Fatalf("Expected *NestedExample to be treated as a nested struct")
+		}
+
+		result := analyzer.GenerateLogStatement(analysis, "e")
+		expectedElements := []string{
+			// nil pointers log "null" rather than dereferencing
+			"if e.Nested == nil {",
+			`return slog.String("Nested", "null")`,
+			// non-nil pointers log the nested attributes
+			`return slog.Attr{Key: "Nested", Value: e.Nested.LogValue()}`,
+		}
+		for _, expected := range expectedElements {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Statement missing %q, got:\n%s", expected, result)
+			}
+		}
+	})
+
+	t.Run("flattened", func(t *testing.T) {
+		cfg := config.DefaultConfig()
+		cfg.OutputStyle = config.OutputStyleFlattened
+		analyzer := NewTypeAnalyzer(cfg).WithKnownStructs(known)
+
+		analyses := analyzer.AnalyzeStruct(example)
+		if len(analyses) != 2 {
+			t.Fatalf("Expected 2 hoisted fields, got %d", len(analyses))
+		}
+
+		// Hoisted fields are omitted when the pointer is nil
+		result := analyzer.GenerateLogStatement(analyses[0], "e")
+		expectedElements := []string{
+			"if e.Nested == nil {",
+			"return slog.Attr{}",
+			`return slog.Bool("Nested.BoolValue", e.Nested.BoolValue)`,
+		}
+		for _, expected := range expectedElements {
+			if !strings.Contains(result, expected) {
+				t.Errorf("Statement missing %q, got:\n%s", expected, result)
+			}
+		}
+
+		// The recursive field is grouped rather than flattened again
+		result = analyzer.GenerateLogStatement(analyses[1], "e")
+		if !strings.Contains(result, `slog.Attr{Key: "Nested.Next", Value: e.Nested.Next.LogValue()}`) {
+			t.Errorf("Recursive field should be logged as a group, got:\n%s", result)
+		}
+	})
+}
+
+func TestGenerateLogStatementOmitZero(t *testing.T) {
+	testCases := []struct {
+		name         string
+		omitZero     bool
+		omitZeroTime bool
+		field        parser.FieldInfo
+		zeroCheck    string // Expected zero check, or empty if the field is always logged
+	}{
+		{
+			name:      "string",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "Name", Type: "string"},
+			zeroCheck: `if u.Name == "" {`,
+		},
+		{
+			name:      "int",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "Count", Type: "int"},
+			zeroCheck: "if u.Count == 0 {",
+		},
+		{
+			name:      "bool",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "Enabled", Type: "bool"},
+			zeroCheck: "if !u.Enabled {",
+		},
+		{
+			name:      "pointer",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "Ratio", Type: "*float64", IsPointer: true},
+			zeroCheck: "if u.Ratio == nil {",
+		},
+		{
+			name:      "slice",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "Tags", Type: "[]string"},
+			zeroCheck: "if u.Tags == nil {",
+		},
+		{
+			name:      "time",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "CreatedAt", Type: "time.Time"},
+			zeroCheck: "if u.CreatedAt.IsZero() {",
+		},
+		{
+			name:      "byte array",
+			omitZero:  true,
+			field:     parser.FieldInfo{Name: "ID", Type: "[16]byte"},
+			zeroCheck: "if u.ID == ([16]byte{}) {",
+		},
+		{
+			name:     "struct from another package is always logged",
+			omitZero: true,
+			field:    parser.FieldInfo{Name: "Address", Type: "geo.Address"},
+		},
+		{
+			name:     "always tag overrides config",
+			omitZero: true,
+			field:    parser.FieldInfo{Name: "Enabled", Type: "bool", LogOptions: parser.ParseLogTag("always")},
+		},
+		{
+			name:     "redacted fields are always logged",
+			omitZero: true,
+			field:    parser.FieldInfo{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+		},
+		{
+			name:  "disabled by default",
+			field: parser.FieldInfo{Name: "Name", Type: "string"},
+		},
+		{
+			name:         "zero time only",
+			omitZeroTime: true,
+			field:        parser.FieldInfo{Name: "CreatedAt", Type: "time.Time"},
+			zeroCheck:    "if u.CreatedAt.IsZero() {",
+		},
+		{
+			name:         "zero time only leaves other types",
+			omitZeroTime: true,
+			field:        parser.FieldInfo{Name: "Name", Type: "string"},
+		},
+		{
+			name:         "zero time only leaves nil pointers to times",
+			omitZeroTime: true,
+			field:        parser.FieldInfo{Name: "DeletedAt", Type: "*time.Time", IsPointer: true},
+		},
+		{
+			name:         "always tag overrides zero time",
+			omitZeroTime: true,
+			field:        parser.FieldInfo{Name: "CreatedAt", Type: "time.Time", LogOptions: parser.ParseLogTag("always")},
+		},
+		{
+			name:      "omitzero tag without config",
+			field:     parser.FieldInfo{Name: "Name", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+			zeroCheck: `if u.Name == "" {`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OmitZero = tc.omitZero
+			cfg.OmitZeroTime = tc.omitZeroTime
+			analyzer := NewTypeAnalyzer(cfg)
+
+			analysis := analyzer.AnalyzeField(tc.field)
+			result := analyzer.GenerateLogStatement(analysis, "u")
+
+			if tc.zeroCheck == "" {
+				if strings.Contains(result, "return slog.Attr{}") {
+					t.Errorf("Field should always be logged, got:\n%s", result)
+				}
+				return
+			}
+
+			// Zero values produce an empty attribute, which handlers omit;
+			// non-zero values fall through to the normal statement
+			analysis.OmitZero = false
+			logged := "return " + analyzer.GenerateLogStatement(analysis, "u")
+			for _, expected := range []string{tc.zeroCheck, "return slog.Attr{}", logged} {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Statement missing %q, got:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLogStatementCustomFormatter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.CustomFormatters = map[string]string{
+		"net.IP":       "github.com/acme/logfmt.IPAttr",
+		"money.Amount": "github.com/acme/money/v2.Attr",
+		"Status":       "statusAttr",
+	}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		name            string
+		field           parser.FieldInfo
+		expected        string
+		expectedImports []string
+	}{
+		{
+			name:            "qualified function",
+			field:           parser.FieldInfo{Name: "Addr", Type: "net.IP"},
+			expected:        `logfmt.IPAttr("Addr", s.Addr)`,
+			expectedImports: []string{"github.com/acme/logfmt"},
+		},
+		{
+			name:            "major version suffix",
+			field:           parser.FieldInfo{Name: "Total", Type: "money.Amount"},
+			expected:        `money.Attr("Total", s.Total)`,
+			expectedImports: []string{"github.com/acme/money/v2"},
+		},
+		{
+			name:     "function in the same package",
+			field:    parser.FieldInfo{Name: "State", Type: "Status"},
+			expected: `statusAttr("State", s.State)`,
+		},
+		{
+			name:     "redaction takes precedence",
+			field:    parser.FieldInfo{Name: "Addr", Type: "net.IP", LogOptions: parser.ParseLogTag("redact")},
+			expected: `slog.String("Addr", "[REDACTED]")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			result := analyzer.GenerateLogStatement(analysis, "s")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+
+			if len(analysis.Imports) != len(tc.expectedImports) {
+				t.Fatalf("Expected imports %v, got %v", tc.expectedImports, analysis.Imports)
+			}
+			for i, imp := range tc.expectedImports {
+				if analysis.Imports[i] != imp {
+					t.Errorf("Import %d: expected %s, got %s", i, imp, analysis.Imports[i])
+				}
+			}
+		})
+	}
+
+	// Pointers to the type are dereferenced behind a nil check
+	pointerField := parser.FieldInfo{Name: "Addr", Type: "*net.IP", IsPointer: true}
+	result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(pointerField), "s")
+	if !strings.Contains(result, "if s.Addr == nil {") || !strings.Contains(result, `return logfmt.IPAttr("Addr", *s.Addr)`) {
+		t.Errorf("Pointer field should be nil-safe, got:\n%s", result)
+	}
+}
+
+func TestGenerateLogStatementJSONTagKey(t *testing.T) {
+	testCases := []struct {
+		name            string
+		useJSONTagAsKey bool
+		field           parser.FieldInfo
+		expected        string
+	}{
+		{
+			name:            "json tag name",
+			useJSONTagAsKey: true,
+			field:           parser.FieldInfo{Name: "GuestName", Type: "string", JSONName: "guest_name"},
+			expected:        `slog.String("guest_name", r.GuestName)`,
+		},
+		{
+			name:            "log name overrides json tag",
+			useJSONTagAsKey: true,
+			field:           parser.FieldInfo{Name: "GuestName", Type: "string", JSONName: "guest_name", LogOptions: parser.ParseLogTag("name=guest")},
+			expected:        `slog.String("guest", r.GuestName)`,
+		},
+		{
+			name:            "no json tag falls back to field name",
+			useJSONTagAsKey: true,
+			field:           parser.FieldInfo{Name: "GuestName", Type: "string"},
+			expected:        `slog.String("GuestName", r.GuestName)`,
+		},
+		{
+			name:     "json tag ignored unless enabled",
+			field:    parser.FieldInfo{Name: "GuestName", Type: "string", JSONName: "guest_name"},
+			expected: `slog.String("GuestName", r.GuestName)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.UseJSONTagAsKey = tc.useJSONTagAsKey
+			analyzer := NewTypeAnalyzer(cfg)
+
+			result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(tc.field), "r")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateLogStatementKeyPrefix(t *testing.T) {
+	testCases := []struct {
+		name     string
+		keyCase  string
+		jsonKeys bool
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			name:     "field name",
+			field:    parser.FieldInfo{Name: "GuestName", Type: "string"},
+			expected: `slog.String("booking.GuestName", r.GuestName)`,
+		},
+		{
+			name:     "cased field name",
+			keyCase:  config.KeyCaseSnake,
+			field:    parser.FieldInfo{Name: "GuestName", Type: "string"},
+			expected: `slog.String("booking.guest_name", r.GuestName)`,
+		},
+		{
+			name:     "json tag name",
+			jsonKeys: true,
+			field:    parser.FieldInfo{Name: "GuestName", Type: "string", JSONName: "guestName"},
+			expected: `slog.String("booking.guestName", r.GuestName)`,
+		},
+		{
+			name:     "log name",
+			keyCase:  config.KeyCaseSnake,
+			jsonKeys: true,
+			field:    parser.FieldInfo{Name: "GuestName", Type: "string", JSONName: "guestName", LogOptions: parser.ParseLogTag("name=Guest")},
+			expected: `slog.String("booking.Guest", r.GuestName)`,
+		},
+		{
+			name:     "redacted field",
+			field:    parser.FieldInfo{Name: "Password", Type: "string"},
+			expected: `slog.String("booking.Password", "[REDACTED]")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.KeyPrefix = "booking."
+			cfg.RedactKeys = []string{"password"}
+			cfg.UseJSONTagAsKey = tc.jsonKeys
+			if tc.keyCase != "" {
+				cfg.KeyCase = tc.keyCase
+			}
+			analyzer := NewTypeAnalyzer(cfg)
+
+			result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(tc.field), "r")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// Hoisted fields carry the prefix once, through their parent key
+	address := parser.StructInfo{Name: "Address", Fields: []parser.FieldInfo{{Name: "City", Type: "string"}}}
+	guest := parser.StructInfo{Name: "Guest", Fields: []parser.FieldInfo{{Name: "Home", Type: "Address"}}}
+	cfg := config.DefaultConfig()
+	cfg.KeyPrefix = "booking."
+	cfg.OutputStyle = config.OutputStyleFlattened
+	analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{address, guest})
+
+	analyses := analyzer.AnalyzeStruct(guest)
+	if len(analyses) != 1 {
+		t.Fatalf("Expected 1 analysis, got %d", len(analyses))
+	}
+	expected := `slog.String("booking.Home.City", r.Home.City)`
+	if result := analyzer.GenerateLogStatement(analyses[0], "r"); result != expected {
+		t.Errorf("GenerateLogStatement() = %q, expected %q", result, expected)
+	}
+}
+
+func TestGenerateLogStatementMapOfStructs(t *testing.T) {
+	order := parser.StructInfo{Name: "Order", Fields: []parser.FieldInfo{{Name: "ID", Type: "int"}}}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{order})
+
+	testCases := []struct {
+		name            string
+		field           parser.FieldInfo
+		expectedImports []string
+		expected        []string
+		unexpected      []string
+	}{
+		{
+			name:  "string keys",
+			field: parser.FieldInfo{Name: "Orders", Type: "map[string]Order"},
+			expected: []string{
+				"if c.Orders == nil {",
+				`return slog.String("Orders", "null")`,
+				"for k, v := range c.Orders {",
+				"attrs = append(attrs, slog.Attr{Key: k, Value: v.LogValue()})",
+				`return slog.Attr{Key: "Orders", Value: slog.GroupValue(attrs...)}`,
+			},
+			unexpected: []string{"v == nil"},
+		},
+		{
+			name:            "named keys and pointer values",
+			field:           parser.FieldInfo{Name: "ByUser", Type: "map[UserID]*Order"},
+			expectedImports: []string{"fmt"},
+			expected: []string{
+				"if v == nil {",
+				`attrs = append(attrs, slog.String(fmt.Sprint(k), "null"))`,
+				"slog.Attr{Key: fmt.Sprint(k), Value: v.LogValue()}",
+			},
+		},
+		{
+			name:       "values not generated",
+			field:      parser.FieldInfo{Name: "Tags", Type: "map[string]string"},
+			expected:   []string{`slog.Any("Tags", c.Tags)`},
+			unexpected: []string{"range"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if !reflect.DeepEqual(analysis.Imports, tc.expectedImports) {
+				t.Errorf("Imports: expected %v, got %v", tc.expectedImports, analysis.Imports)
+			}
+
+			result := analyzer.GenerateLogStatement(analysis, "c")
+			for _, expected := range tc.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Statement missing %q, got:\n%s", expected, result)
+				}
+			}
+			for _, unexpected := range tc.unexpected {
+				if strings.Contains(result, unexpected) {
+					t.Errorf("Statement should not contain %q, got:\n%s", unexpected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitMapType(t *testing.T) {
+	testCases := []struct {
+		fieldType string
+		key       string
+		value     string
+		ok        bool
+	}{
+		{"map[string]Order", "string", "Order", true},
+		{"map[UserID]*Account", "UserID", "*Account", true},
+		{"map[[16]byte]map[string]int", "[16]byte", "map[string]int", true},
+		{"[]string", "", "", false},
+		{"map[string", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		key, value, ok := splitMapType(tc.fieldType)
+		if key != tc.key || value != tc.value || ok != tc.ok {
+			t.Errorf("splitMapType(%q): expected (%q, %q, %v), got (%q, %q, %v)",
+				tc.fieldType, tc.key, tc.value, tc.ok, key, value, ok)
+		}
+	}
+}
+
+func TestGenerateLogStatementEnum(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+	constants := []parser.EnumConstant{
+		{Name: "Active", Expr: "status.Active"},
+		{Name: "Inactive", Expr: "status.Inactive"},
+	}
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected []string
+	}{
+		{
+			name:  "value",
+			field: parser.FieldInfo{Name: "State", Type: "status.State"},
+			expected: []string{
+				"switch u.State {",
+				"case status.Active:",
+				`return slog.String("State", "Active")`,
+				"case status.Inactive:",
+				`return slog.String("State", "Inactive")`,
+				// Values without a constant fall back to the integer
+				`return slog.Int64("State", int64(u.State))`,
+			},
+		},
+		{
+			name:  "pointer",
+			field: parser.FieldInfo{Name: "State", Type: "*status.State", IsPointer: true},
+			expected: []string{
+				"if u.State == nil {",
+				`return slog.String("State", "null")`,
+				"switch *u.State {",
+				`return slog.Int64("State", int64(*u.State))`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			analysis.Enum = constants
+
+			result := analyzer.GenerateLogStatement(analysis, "u")
+			for _, expected := range tc.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Statement missing %q, got:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzeFieldRedactUnexported(t *testing.T) {
+	testCases := []struct {
+		name             string
+		redactUnexported bool
+		field            parser.FieldInfo
+		expected         FieldAction
+		expectedValue    string
+	}{
+		{"unexported string", true, parser.FieldInfo{Name: "apiToken", Type: "string"}, ActionRedact, "[REDACTED]"},
+		{"unexported int", true, parser.FieldInfo{Name: "retries", Type: "int"}, ActionRedact, "[REDACTED]"},
+		{"unexported pointer", true, parser.FieldInfo{Name: "conn", Type: "*Conn", IsPointer: true}, ActionRedact, "[REDACTED]"},
+		{"unexported embedded", true, parser.FieldInfo{Name: "session", Type: "session", Embedded: true}, ActionRedact, "[REDACTED]"},
+		{"exported", true, parser.FieldInfo{Name: "Name", Type: "string"}, ActionLog, ""},
+		{"unexported skipped", true, parser.FieldInfo{Name: "cache", Type: "string", LogOptions: parser.ParseLogTag("-")}, ActionSkip, ""},
+		{"unexported without option", false, parser.FieldInfo{Name: "apiToken", Type: "string"}, ActionLog, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.RedactKeys = nil
+			cfg.RedactUnexported = tc.redactUnexported
+			analyzer := NewTypeAnalyzer(cfg)
+
+			analysis := analyzer.AnalyzeField(tc.field)
+			if analysis.Action != tc.expected {
+				t.Errorf("%s: expected %v, got %v", tc.field.Name, tc.expected, analysis.Action)
+			}
+			if analysis.LogValue != tc.expectedValue {
+				t.Errorf("%s: expected value %q, got %q", tc.field.Name, tc.expectedValue, analysis.LogValue)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFieldRedactKey(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		field    parser.FieldInfo
+		expected string
+	}{
+		{parser.FieldInfo{Name: "Password", Type: "string"}, "password"},
+		// Explicitly tagged fields still record the key their name matches
+		{parser.FieldInfo{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("mask")}, "password"},
+		{parser.FieldInfo{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("redact")}, ""},
+		{parser.FieldInfo{Name: "Name", Type: "string"}, ""},
+	}
+
+	for _, tc := range testCases {
+		analysis := analyzer.AnalyzeField(tc.field)
+		if analysis.RedactKey != tc.expected {
+			t.Errorf("Field %s (%q): expected redact key %q, got %q", tc.field.Name, tc.field.LogOptions.Raw, tc.expected, analysis.RedactKey)
+		}
+	}
+}
+
+func TestGenerateLogStatementPointerToSliceOrMap(t *testing.T) {
+	order := parser.StructInfo{Name: "Order", Fields: []parser.FieldInfo{{Name: "ID", Type: "int"}}}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{order})
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected []string
+	}{
+		{
+			name:  "pointer to slice",
+			field: parser.FieldInfo{Name: "Tags", Type: "*[]string", IsPointer: true},
+			expected: []string{
+				"if b.Tags == nil {",
+				`return slog.String("Tags", "null")`,
+				`return slog.Any("Tags", *b.Tags)`,
+			},
+		},
+		{
+			name:  "pointer to map",
+			field: parser.FieldInfo{Name: "Counts", Type: "*map[string]int", IsPointer: true},
+			expected: []string{
+				"if b.Counts == nil {",
+				`return slog.String("Counts", "null")`,
+				`return slog.Any("Counts", *b.Counts)`,
+			},
+		},
+		{
+			name:  "pointer to map of generated structs",
+			field: parser.FieldInfo{Name: "Orders", Type: "*map[string]Order", IsPointer: true},
+			expected: []string{
+				"if b.Orders == nil {",
+				"if (*b.Orders) == nil {",
+				"for k, v := range (*b.Orders) {",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if analysis.SlogFunc != SlogAny {
+				t.Errorf("Expected %s, got %s", SlogAny, analysis.SlogFunc)
+			}
+
+			result := analyzer.GenerateLogStatement(analysis, "b")
+			for _, expected := range tc.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("Statement missing %q, got:\n%s", expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestZapEmitter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	analyzer := NewTypeAnalyzer(cfg)
+	emitter := NewZapEmitter(analyzer)
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{"int", parser.FieldInfo{Name: "Age", Type: "int"}, `zap.Int64("Age", int64(u.Age))`},
+		{"int64", parser.FieldInfo{Name: "ID", Type: "int64"}, `zap.Int64("ID", u.ID)`},
+		{"uint8", parser.FieldInfo{Name: "Flags", Type: "uint8"}, `zap.Int64("Flags", int64(u.Flags))`},
+		{"string", parser.FieldInfo{Name: "Name", Type: "string"}, `zap.String("Name", u.Name)`},
+		{"bool", parser.FieldInfo{Name: "Active", Type: "bool"}, `zap.Bool("Active", u.Active)`},
+		{"float32", parser.FieldInfo{Name: "Score", Type: "float32"}, `zap.Float64("Score", float64(u.Score))`},
+		{"float64", parser.FieldInfo{Name: "Ratio", Type: "float64"}, `zap.Float64("Ratio", u.Ratio)`},
+		{"time", parser.FieldInfo{Name: "At", Type: "time.Time"}, `zap.Time("At", u.At)`},
+		{"duration", parser.FieldInfo{Name: "TTL", Type: "time.Duration"}, `zap.Duration("TTL", u.TTL)`},
+		{"byte slice", parser.FieldInfo{Name: "Raw", Type: "[]byte"}, `zap.String("Raw", base64.StdEncoding.EncodeToString(u.Raw))`},
+		{"uintptr", parser.FieldInfo{Name: "Addr", Type: "uintptr"}, `zap.Uint64("Addr", uint64(u.Addr))`},
+		{"unsafe pointer", parser.FieldInfo{Name: "Ptr", Type: "unsafe.Pointer"}, `zap.String("Ptr", fmt.Sprintf("%p", u.Ptr))`},
+		{"slice", parser.FieldInfo{Name: "Tags", Type: "[]string"}, `zap.Any("Tags", u.Tags)`},
+		{"redacted", parser.FieldInfo{Name: "Password", Type: "string"}, `zap.String("Password", "[REDACTED]")`},
+		{"skipped", parser.FieldInfo{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("-")}, ""},
+		{
+			"pointer",
+			parser.FieldInfo{Name: "Nick", Type: "*string", IsPointer: true},
+			`func() zap.Field {
+if u.Nick == nil {
+return zap.String("Nick", "null")
+}
+return zap.String("Nick", *u.Nick)
+}()`,
+		},
+		{
+			"omitted zero",
+			parser.FieldInfo{Name: "Note", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+			`func() zap.Field {
+if u.Note == "" {
+return zap.Skip()
+}
+return zap.String("Note", u.Note)
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := emitter.Field(analyzer.AnalyzeField(tc.field), "u")
+			if result != tc.expected {
+				t.Errorf("Field() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestZapEmitterNestedStruct(t *testing.T) {
+	address := parser.StructInfo{Name: "Address", Fields: []parser.FieldInfo{{Name: "City", Type: "string"}}}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{address})
+	emitter := NewZapEmitter(analyzer)
+
+	field := parser.FieldInfo{Name: "Home", Type: "Address"}
+	result := emitter.Field(analyzer.AnalyzeField(field), "u")
+	expected := `zap.Dict("Home", u.Home.ZapFields()...)`
+	if result != expected {
+		t.Errorf("Field() = %q, expected %q", result, expected)
+	}
+}
+
+func TestZerologEmitter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	analyzer := NewTypeAnalyzer(cfg)
+	emitter := NewZerologEmitter(analyzer)
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{"int", parser.FieldInfo{Name: "Age", Type: "int"}, `Int64("Age", int64(u.Age))`},
+		{"int64", parser.FieldInfo{Name: "ID", Type: "int64"}, `Int64("ID", u.ID)`},
+		{"string", parser.FieldInfo{Name: "Name", Type: "string"}, `Str("Name", u.Name)`},
+		{"bool", parser.FieldInfo{Name: "Active", Type: "bool"}, `Bool("Active", u.Active)`},
+		{"float32", parser.FieldInfo{Name: "Score", Type: "float32"}, `Float64("Score", float64(u.Score))`},
+		{"time", parser.FieldInfo{Name: "At", Type: "time.Time"}, `Time("At", u.At)`},
+		{"duration", parser.FieldInfo{Name: "TTL", Type: "time.Duration"}, `Dur("TTL", u.TTL)`},
+		{"byte array", parser.FieldInfo{Name: "UUID", Type: "[16]byte"}, `Str("UUID", hex.EncodeToString(u.UUID[:]))`},
+		{"uintptr", parser.FieldInfo{Name: "Addr", Type: "uintptr"}, `Uint64("Addr", uint64(u.Addr))`},
+		{"raw message", parser.FieldInfo{Name: "Body", Type: "json.RawMessage"}, `Str("Body", string(u.Body))`},
+		{"unsafe pointer", parser.FieldInfo{Name: "Ptr", Type: "unsafe.Pointer"}, `Str("Ptr", fmt.Sprintf("%p", u.Ptr))`},
+		{"slice", parser.FieldInfo{Name: "Tags", Type: "[]string"}, `Interface("Tags", u.Tags)`},
+		{"redacted", parser.FieldInfo{Name: "Password", Type: "string"}, `Str("Password", "[REDACTED]")`},
+		{"redacted pointer", parser.FieldInfo{Name: "Password", Type: "*string", IsPointer: true}, `Str("Password", "[REDACTED]")`},
+		{"skipped", parser.FieldInfo{Name: "Secret", Type: "string", LogOptions: parser.ParseLogTag("-")}, ""},
+		{
+			"pointer",
+			parser.FieldInfo{Name: "Age", Type: "*int", IsPointer: true},
+			`Func(func(evt *zerolog.Event) {
+if u.Age == nil {
+evt.Str("Age", "null")
+return
+}
+evt.Int64("Age", int64(*u.Age))
+})`,
+		},
+		{
+			"omitted zero",
+			parser.FieldInfo{Name: "Note", Type: "string", LogOptions: parser.ParseLogTag("omitzero")},
+			`Func(func(evt *zerolog.Event) {
+if u.Note == "" {
+return
+}
+evt.Str("Note", u.Note)
+})`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := emitter.Field(analyzer.AnalyzeField(tc.field), "u")
+			if result != tc.expected {
+				t.Errorf("Field() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestZerologEmitterNestedStruct(t *testing.T) {
+	address := parser.StructInfo{Name: "Address", Fields: []parser.FieldInfo{{Name: "City", Type: "string"}}}
+	analyzer := NewTypeAnalyzer(config.DefaultConfig()).WithKnownStructs([]parser.StructInfo{address})
+	emitter := NewZerologEmitter(analyzer)
+
+	testCases := []struct {
+		field    parser.FieldInfo
+		expected string
+	}{
+		{parser.FieldInfo{Name: "Home", Type: "Address"}, `Object("Home", &u.Home)`},
+		{parser.FieldInfo{Name: "Work", Type: "*Address", IsPointer: true}, `evt.Object("Work", u.Work)`},
+	}
+
+	for _, tc := range testCases {
+		result := emitter.Field(analyzer.AnalyzeField(tc.field), "u")
+		if !strings.Contains(result, tc.expected) {
+			t.Errorf("Field(%s) = %q, expected it to contain %q", tc.field.Name, result, tc.expected)
+		}
+	}
+}
+
+func TestGenerateLogStatementPointerLikeTypes(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{"uintptr", parser.FieldInfo{Name: "Addr", Type: "uintptr"}, `slog.Uint64("Addr", uint64(u.Addr))`},
+		{
+			"pointer to uintptr",
+			parser.FieldInfo{Name: "Addr", Type: "*uintptr", IsPointer: true},
+			`func() slog.Attr {
+if u.Addr == nil {
+return slog.String("Addr", "null")
+}
+return slog.Uint64("Addr", uint64(*u.Addr))
+}()`,
+		},
+		{"unsafe pointer", parser.FieldInfo{Name: "Ptr", Type: "unsafe.Pointer"}, `slog.String("Ptr", fmt.Sprintf("%p", u.Ptr))`},
+		{
+			"omitted nil unsafe pointer",
+			parser.FieldInfo{Name: "Ptr", Type: "unsafe.Pointer", LogOptions: parser.ParseLogTag("omitzero")},
+			`func() slog.Attr {
+if u.Ptr == nil {
+return slog.Attr{}
+}
+return slog.String("Ptr", fmt.Sprintf("%p", u.Ptr))
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := analyzer.GenerateLogStatement(analyzer.AnalyzeField(tc.field), "u")
+			if result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// Redacted copies zero the fields without importing unsafe
+	for fieldType, expected := range map[string]string{"uintptr": "u.P = 0", "unsafe.Pointer": "u.P = nil"} {
+		analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "P", Type: fieldType, LogOptions: parser.ParseLogTag("redact")})
+		if result := analyzer.GenerateRedactStatement(analysis, "u"); result != expected {
+			t.Errorf("GenerateRedactStatement(%s) = %q, expected %q", fieldType, result, expected)
+		}
+	}
+}
+
+func TestAnalyzeStructRecursive(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxDepth = 3
+	node := parser.StructInfo{
+		Name: "Node",
+		Fields: []parser.FieldInfo{
+			{Name: "Name", Type: "string"},
+			{Name: "Next", Type: "*Node", IsPointer: true},
+			{Name: "Owner", Type: "Owner"},
+		},
+	}
+	// Owner is recursive through Node, while Leaf is not
+	owner := parser.StructInfo{
+		Name: "Owner",
+		Fields: []parser.FieldInfo{
+			{Name: "Nodes", Type: "map[string]Node"},
+			{Name: "Leaf", Type: "Leaf"},
+		},
+	}
+	leaf := parser.StructInfo{Name: "Leaf", Fields: []parser.FieldInfo{{Name: "ID", Type: "int"}}}
+	analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{node, owner, leaf})
+
+	for name, expected := range map[string]bool{"Node": true, "Owner": true, "Leaf": false} {
+		if result := analyzer.IsRecursive(name); result != expected {
+			t.Errorf("IsRecursive(%s): expected %v, got %v", name, expected, result)
+		}
+	}
+
+	analyses := analyzer.AnalyzeStruct(node)
+	expected := []string{
+		`slog.String("Name", n.Name)`,
+		`func() slog.Attr {
+if n.Next == nil {
+return slog.String("Next", "null")
+}
+return func() slog.Attr {
+if depth >= 3 {
+return slog.String("Next", "[MAX DEPTH]")
+}
+return slog.Attr{Key: "Next", Value: n.Next.logValue(depth + 1)}
+}()
+}()`,
+		`func() slog.Attr {
+if depth >= 3 {
+return slog.String("Owner", "[MAX DEPTH]")
+}
+return slog.Attr{Key: "Owner", Value: n.Owner.logValue(depth + 1)}
+}()`,
+	}
+	for i, analysis := range analyses {
+		if result := analyzer.GenerateLogStatement(analysis, "n"); result != expected[i] {
+			t.Errorf("Field %s: expected %q, got %q", analysis.Field.Name, expected[i], result)
+		}
+	}
+
+	// Non-recursive structs held by recursive ones are logged as usual
+	analyses = analyzer.AnalyzeStruct(owner)
+	if !analyses[0].Recursive || analyses[1].Recursive {
+		t.Errorf("Recursive: expected [true false], got [%v %v]", analyses[0].Recursive, analyses[1].Recursive)
+	}
+	if result := analyzer.GenerateLogStatement(analyses[1], "o"); result != `slog.Attr{Key: "Leaf", Value: o.Leaf.LogValue()}` {
+		t.Errorf("Leaf: unexpected statement %q", result)
+	}
+}
+
+func TestGenerateLogStatementRawMessage(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{"raw message", parser.FieldInfo{Name: "Payload", Type: "json.RawMessage"}, `slog.String("Payload", string(e.Payload))`},
+		{
+			"pointer to raw message",
+			parser.FieldInfo{Name: "Payload", Type: "*json.RawMessage", IsPointer: true},
+			`func() slog.Attr {
+if e.Payload == nil {
+return slog.String("Payload", "null")
+}
+return slog.String("Payload", string(*e.Payload))
+}()`,
+		},
+		{
+			"omitted empty raw message",
+			parser.FieldInfo{Name: "Payload", Type: "json.RawMessage", LogOptions: parser.ParseLogTag("omitzero")},
+			`func() slog.Attr {
+if len(e.Payload) == 0 {
+return slog.Attr{}
+}
+return slog.String("Payload", string(e.Payload))
+}()`,
+		},
+		// Plain byte slices are still base64-encoded
+		{"byte slice", parser.FieldInfo{Name: "Data", Type: "[]byte"}, `slog.String("Data", base64.StdEncoding.EncodeToString(e.Data))`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "e"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// Raw messages need no encoding import, unlike byte slices
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Payload", Type: "json.RawMessage"})
+	if analysis.SlogFunc != SlogString || len(analysis.Imports) != 0 {
+		t.Errorf("Expected slog.String without imports, got %s with %v", analysis.SlogFunc, analysis.Imports)
+	}
+
+	// Redacted copies reset raw messages to nil
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "Payload", Type: "json.RawMessage", LogOptions: parser.ParseLogTag("redact")})
+	if result := analyzer.GenerateRedactStatement(analysis, "e"); result != "e.Payload = nil" {
+		t.Errorf("GenerateRedactStatement() = %q, expected %q", result, "e.Payload = nil")
+	}
+}
+
+func TestGenerateLogStatementSQLNull(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			"null string",
+			parser.FieldInfo{Name: "Nickname", Type: "sql.NullString"},
+			`func() slog.Attr {
+if !u.Nickname.Valid {
+return slog.String("Nickname", "null")
+}
+return slog.String("Nickname", u.Nickname.String)
+}()`,
+		},
+		{
+			"null int32",
+			parser.FieldInfo{Name: "Age", Type: "sql.NullInt32"},
+			`func() slog.Attr {
+if !u.Age.Valid {
+return slog.String("Age", "null")
+}
+return slog.Int64("Age", int64(u.Age.Int32))
+}()`,
+		},
+		{
+			"generic null",
+			parser.FieldInfo{Name: "Score", Type: "sql.Null[float64]"},
+			`func() slog.Attr {
+if !u.Score.Valid {
+return slog.String("Score", "null")
+}
+return slog.Float64("Score", u.Score.V)
+}()`,
+		},
+		{
+			"pointer to null string",
+			parser.FieldInfo{Name: "Nickname", Type: "*sql.NullString", IsPointer: true},
+			`func() slog.Attr {
+if u.Nickname == nil {
+return slog.String("Nickname", "null")
+}
+return func() slog.Attr {
+if !u.Nickname.Valid {
+return slog.String("Nickname", "null")
+}
+return slog.String("Nickname", u.Nickname.String)
+}()
+}()`,
+		},
+		{
+			"omitted invalid null string",
+			parser.FieldInfo{Name: "Nickname", Type: "sql.NullString", LogOptions: parser.ParseLogTag("omitzero")},
+			`func() slog.Attr {
+if !u.Nickname.Valid {
+return slog.Attr{}
+}
+return func() slog.Attr {
+if !u.Nickname.Valid {
+return slog.String("Nickname", "null")
+}
+return slog.String("Nickname", u.Nickname.String)
+}()
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "u"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// Formatted times need the time package only for layout constants
+	cfg := config.DefaultConfig()
+	cfg.TimeFormat = "RFC3339"
+	analyzer = NewTypeAnalyzer(cfg)
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "DeletedAt", Type: "sql.NullTime"})
+	if !reflect.DeepEqual(analysis.Imports, []string{"time"}) {
+		t.Errorf("Imports: expected [time], got %v", analysis.Imports)
+	}
+	expected := `slog.String("DeletedAt", u.DeletedAt.Time.Format(time.RFC3339))`
+	if result := analyzer.GenerateLogStatement(analysis, "u"); !strings.Contains(result, expected) {
+		t.Errorf("GenerateLogStatement() = %q, expected it to contain %q", result, expected)
+	}
+
+	// Nil pointers log "null" under nilBehavior zero, the zero value being invalid
+	cfg = config.DefaultConfig()
+	cfg.NilBehavior = config.NilBehaviorZero
+	analyzer = NewTypeAnalyzer(cfg)
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "Nickname", Type: "*sql.NullString", IsPointer: true})
+	if result := analyzer.GenerateLogStatement(analysis, "u"); !strings.Contains(result, `if u.Nickname == nil {
+return slog.String("Nickname", "null")`) {
+		t.Errorf("GenerateLogStatement() = %q, expected nil pointers to log null", result)
+	}
+
+	analyzer = NewTypeAnalyzer(config.DefaultConfig())
+	emitter := NewZerologEmitter(analyzer)
+	expected = `Func(func(evt *zerolog.Event) {
+if !u.Nickname.Valid {
+evt.Str("Nickname", "null")
+return
+}
+evt.Str("Nickname", u.Nickname.String)
+})`
+	if result := emitter.Field(analyzer.AnalyzeField(parser.FieldInfo{Name: "Nickname", Type: "sql.NullString"}), "u"); result != expected {
+		t.Errorf("Field() = %q, expected %q", result, expected)
+	}
+}
+
+func TestHasUnknownType(t *testing.T) {
+	testCases := []struct {
+		fieldType string
+		expected  bool
+	}{
+		{"unknown", true},
+		{"*unknown", true},
+		{"[]unknown", true},
+		{"[unknown]byte", true},
+		{"map[string]unknown", true},
+		{"struct{Limit unknown}", true},
+		{"string", false},
+		{"unknownThing", false},
+		{"pkg.unknown", false},
+	}
+
+	for _, tc := range testCases {
+		if result := HasUnknownType(tc.fieldType); result != tc.expected {
+			t.Errorf("HasUnknownType(%q) = %v, expected %v", tc.fieldType, result, tc.expected)
+		}
+	}
+}
+
+func TestUnknownTypeFields(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"secret"}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	limits := parser.StructInfo{
+		Name: "Limits",
+		Fields: []parser.FieldInfo{
+			{Name: "Max", Type: "unknown"},
+			{Name: "Min", Type: "int"},
+			{Name: "Config", Type: "struct{Burst unknown}", Fields: []parser.FieldInfo{{Name: "Burst", Type: "unknown"}}},
+			{Name: "Skipped", Type: "unknown", LogOptions: parser.ParseLogTag("-")},
+			{Name: "Secret", Type: "unknown"},
+		},
+	}
+
+	// Skipped and redacted fields are not logged by reflection
+	names := UnknownTypeFields(analyzer.AnalyzeStruct(limits))
+	if !reflect.DeepEqual(names, []string{"Max", "Config.Burst"}) {
+		t.Errorf("UnknownTypeFields() = %v, expected [Max Config.Burst]", names)
+	}
+}
+
+func TestAnalyzeFieldFuncTypes(t *testing.T) {
+	field := parser.FieldInfo{Name: "OnSave", Type: "func(int) error"}
+
+	// Functions are skipped by default
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+	if analysis := analyzer.AnalyzeField(field); analysis.Action != ActionSkip {
+		t.Errorf("Action: expected %v, got %v", ActionSkip, analysis.Action)
+	}
+	if analyzer.HasLoggableFields(parser.StructInfo{Name: "Hooks", Fields: []parser.FieldInfo{field}}) {
+		t.Errorf("Expected a struct of only functions to have no loggable fields")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.LogFuncFields = true
+	analyzer = NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			"func",
+			field,
+			`func() slog.Attr {
+if h.OnSave == nil {
+return slog.String("OnSave", "null")
+}
+return slog.String("OnSave", "func")
+}()`,
+		},
+		{
+			"redacted func",
+			parser.FieldInfo{Name: "OnSave", Type: "func()", LogOptions: parser.ParseLogTag("redact")},
+			`slog.String("OnSave", "[REDACTED]")`,
+		},
+		{"skipped func", parser.FieldInfo{Name: "OnSave", Type: "func()", LogOptions: parser.ParseLogTag("-")}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "h"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// Redacted copies reset functions to nil
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "OnSave", Type: "func()", LogOptions: parser.ParseLogTag("redact")})
+	if result := analyzer.GenerateRedactStatement(analysis, "h"); result != "h.OnSave = nil" {
+		t.Errorf("GenerateRedactStatement() = %q, expected %q", result, "h.OnSave = nil")
+	}
+}
+
+func TestAnalyzeFieldChanTypes(t *testing.T) {
+	// Channels are skipped by default
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+	for _, fieldType := range []string{"chan int", "<-chan int", "chan<- int"} {
+		analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Events", Type: fieldType})
+		if analysis.Action != ActionSkip {
+			t.Errorf("%s: expected %v, got %v", fieldType, ActionSkip, analysis.Action)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.LogChanFields = true
+	analyzer = NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		fieldType string
+		expected  string
+	}{
+		{"chan int", "chan int"},
+		{"<-chan int", "<-chan int"},
+		{"chan<- string", "chan<- string"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fieldType, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Events", Type: tc.fieldType})
+			expected := fmt.Sprintf(`func() slog.Attr {
+if w.Events == nil {
+return slog.String("Events", "null")
+}
+return slog.String("Events", %q)
+}()`, tc.expected)
+			if result := analyzer.GenerateLogStatement(analysis, "w"); result != expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFieldRedactMessagePlaceholders(t *testing.T) {
+	password := parser.FieldInfo{Name: "Password", Type: "*string", IsPointer: true}
+
+	testCases := []struct {
+		message  string
+		expected string
+	}{
+		{"[REDACTED]", "[REDACTED]"},
+		{"[REDACTED:{field}]", "[REDACTED:Password]"},
+		{"[REDACTED:{type}]", "[REDACTED:*string]"},
+		{"{field} ({type}) hidden, {field}", "Password (*string) hidden, Password"},
+		{"[REDACTED:{name}]", "[REDACTED:{name}]"},
+	}
+
+	for _, tc := range testCases {
+		analyzer := NewTypeAnalyzer(&config.Config{RedactKeys: []string{"password"}, RedactMessage: tc.message})
+
+		analysis := analyzer.AnalyzeField(password)
+		if analysis.LogValue != tc.expected {
+			t.Errorf("%s: expected log value %q, got %q", tc.message, tc.expected, analysis.LogValue)
+		}
+
+		statement := analyzer.GenerateRedactStatement(analysis, "u")
+		if !strings.Contains(statement, fmt.Sprintf("redacted := %q", tc.expected)) {
+			t.Errorf("%s: expected Redacted to use %q, got:\n%s", tc.message, tc.expected, statement)
+		}
+	}
+}
+
+func TestAnalyzeFieldEmbeddedInterfaces(t *testing.T) {
+	reader := parser.FieldInfo{Name: "Reader", Type: "io.Reader", Embedded: true, Interface: true}
+	base := parser.FieldInfo{Name: "Base", Type: "Base", Embedded: true}
+	store := parser.FieldInfo{Name: "Store", Type: "Store"}
+
+	testCases := []struct {
+		name     string
+		log      bool
+		field    parser.FieldInfo
+		expected FieldAction
+	}{
+		{"embedded interface skipped by default", false, reader, ActionSkip},
+		{"embedded interface logged when configured", true, reader, ActionLog},
+		{"embedded struct logged", false, base, ActionLog},
+		{"named interface field logged", false, store, ActionLog},
+	}
+
+	for _, tc := range testCases {
+		analyzer := NewTypeAnalyzer(&config.Config{LogEmbeddedInterfaces: tc.log})
+		analysis := analyzer.AnalyzeField(tc.field)
+		if analysis.Action != tc.expected {
+			t.Errorf("%s: expected action %v, got %v", tc.name, tc.expected, analysis.Action)
+		}
+	}
+
+	analyzer := NewTypeAnalyzer(&config.Config{LogEmbeddedInterfaces: true})
+	statement := analyzer.GenerateLogStatement(analyzer.AnalyzeField(reader), "s")
+	if !strings.Contains(statement, `return slog.Any("Reader", s.Reader)`) {
+		t.Errorf("Expected embedded interface logged through its field name, got %s", statement)
+	}
+	if !strings.Contains(statement, `fmt.Sprintf("%T(nil)", s.Reader)`) {
+		t.Errorf("Expected embedded interface holding a nil pointer logged as its type, got %s", statement)
+	}
+}
+
+func TestGenerateLogStatementNamedInterface(t *testing.T) {
+	reader := parser.FieldInfo{Name: "R", Type: "io.Reader", Interface: true}
+	writer := parser.FieldInfo{Name: "W", Type: "*Writer", IsPointer: true, Interface: true}
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+		zerolog  string
+	}{
+		{
+			name:     "named interface",
+			field:    reader,
+			expected: `slog.Group("R", slog.String("type", fmt.Sprintf("%T", s.R)), slog.Any("value", s.R))`,
+			zerolog:  `Dict("R", zerolog.Dict().Str("type", fmt.Sprintf("%T", s.R)).Interface("value", s.R))`,
+		},
+		{
+			name:     "pointer to named interface",
+			field:    writer,
+			expected: `slog.Group("W", slog.String("type", fmt.Sprintf("%T", *s.W)), slog.Any("value", *s.W))`,
+			zerolog:  `Dict("W", zerolog.Dict().Str("type", fmt.Sprintf("%T", *s.W)).Interface("value", *s.W))`,
+		},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.DefaultConfig()
+		cfg.LogInterfaceTypes = true
+		analyzer := NewTypeAnalyzer(cfg)
+
+		analysis := analyzer.AnalyzeField(tc.field)
+		if statement := analyzer.GenerateLogStatement(analysis, "s"); !strings.Contains(statement, tc.expected) {
+			t.Errorf("%s: expected statement containing %s, got %s", tc.name, tc.expected, statement)
+		}
+		if statement := NewZerologEmitter(analyzer).Field(analysis, "s"); !strings.Contains(statement, tc.zerolog) {
+			t.Errorf("%s: expected zerolog statement containing %s, got %s", tc.name, tc.zerolog, statement)
+		}
+		if !reflect.DeepEqual(analysis.Imports, []string{"fmt", "reflect"}) {
+			t.Errorf("%s: expected imports [fmt reflect], got %v", tc.name, analysis.Imports)
+		}
+	}
+}
+
+func TestGenerateLogStatementHash(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		action   FieldAction
+		imports  []string
+		expected string
+	}{
+		{
+			"string",
+			parser.FieldInfo{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("hash")},
+			ActionHash,
+			[]string{"crypto/sha256", "encoding/hex"},
+			`slog.String("Email", oakHash(u.Email))`,
+		},
+		{
+			"integer",
+			parser.FieldInfo{Name: "ID", Type: "int64", LogOptions: parser.ParseLogTag("hash,name=user")},
+			ActionHash,
+			[]string{"crypto/sha256", "encoding/hex", "fmt"},
+			`slog.String("user", oakHash(fmt.Sprint(u.ID)))`,
+		},
+		{
+			"pointer",
+			parser.FieldInfo{Name: "Phone", Type: "*string", IsPointer: true, LogOptions: parser.ParseLogTag("hash")},
+			ActionHash,
+			[]string{"crypto/sha256", "encoding/hex"},
+			`func() slog.Attr {
+if u.Phone == nil {
+return slog.String("Phone", "null")
+}
+return slog.String("Phone", oakHash(*u.Phone))
+}()`,
+		},
+		{
+			"redact key takes precedence",
+			parser.FieldInfo{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("hash")},
+			ActionRedact,
+			nil,
+			`slog.String("Password", "[REDACTED]")`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if analysis.Action != tc.action {
+				t.Errorf("Expected action %v, got %v", tc.action, analysis.Action)
+			}
+			if !reflect.DeepEqual(analysis.Imports, tc.imports) {
+				t.Errorf("Expected imports %v, got %v", tc.imports, analysis.Imports)
+			}
+			if result := analyzer.GenerateLogStatement(analysis, "u"); result != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, result)
+			}
+		})
+	}
+
+	// Analyzers for in-package test files hash through their own function
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("hash")})
+	if result := analyzer.WithHashFunc("oakHashTest").GenerateLogStatement(analysis, "u"); result != `slog.String("Email", oakHashTest(u.Email))` {
+		t.Errorf("Expected hash through oakHashTest, got %s", result)
+	}
+}
+
+func TestAnalyzeStructMultiNameFields(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	// Username, Password string `log:"redact"` declares two fields
+	structInfo := parser.StructInfo{
+		Name: "Credentials",
+		Fields: []parser.FieldInfo{
+			{Name: "Username", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+			{Name: "Password", Type: "string", LogOptions: parser.ParseLogTag("redact")},
+		},
+	}
+
+	analyses := analyzer.AnalyzeStruct(structInfo)
+	if len(analyses) != 2 {
+		t.Fatalf("Expected 2 analyses, got %d", len(analyses))
+	}
+	for _, analysis := range analyses {
+		if analysis.Action != ActionRedact {
+			t.Errorf("%s: expected action %v, got %v", analysis.Field.Name, ActionRedact, analysis.Action)
+		}
+		expected := fmt.Sprintf(`slog.String(%q, "[REDACTED]")`, analysis.Field.Name)
+		if result := analyzer.GenerateLogStatement(analysis, "c"); result != expected {
+			t.Errorf("%s: expected %s, got %s", analysis.Field.Name, expected, result)
+		}
+	}
+}
+
+func TestAnalyzeFieldAllowlist(t *testing.T) {
+	cfg := &config.Config{
+		Allowlist:  true,
+		AllowKeys:  []string{"id", "*status"},
+		RedactKeys: []string{"token"},
+	}
+
+	testCases := []struct {
+		field    parser.FieldInfo
+		expected FieldAction
+	}{
+		{parser.FieldInfo{Name: "Email", Type: "string"}, ActionSkip},
+		{parser.FieldInfo{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("log")}, ActionLog},
+		{parser.FieldInfo{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("log,name=email")}, ActionLog},
+		{parser.FieldInfo{Name: "Email", Type: "string", LogOptions: parser.ParseLogTag("redact")}, ActionSkip},
+		{parser.FieldInfo{Name: "ID", Type: "int"}, ActionLog},
+		{parser.FieldInfo{Name: "OrderStatus", Type: "string"}, ActionLog},
+		{parser.FieldInfo{Name: "ID", Type: "int", LogOptions: parser.ParseLogTag("-")}, ActionSkip},
+		// Opted-in fields are still redacted
+		{parser.FieldInfo{Name: "Token", Type: "string", LogOptions: parser.ParseLogTag("log")}, ActionRedact},
+		{parser.FieldInfo{Name: "Card", Type: "string", LogOptions: parser.ParseLogTag("log,mask")}, ActionMask},
+	}
+
+	analyzer := NewTypeAnalyzer(cfg)
+	for _, tc := range testCases {
+		analysis := analyzer.AnalyzeField(tc.field)
+		if analysis.Action != tc.expected {
+			t.Errorf("%s (%s): expected action %v, got %v", tc.field.Name, tc.field.LogOptions.Raw, tc.expected, analysis.Action)
+		}
+	}
+
+	// Without the allowlist, fields are logged by default
+	analyzer = NewTypeAnalyzer(&config.Config{AllowKeys: []string{"id"}})
+	if analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Email", Type: "string"}); analysis.Action != ActionLog {
+		t.Errorf("Email: expected action %v, got %v", ActionLog, analysis.Action)
+	}
+}
+
+func TestGenerateLogStatementInlineStruct(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RedactKeys = []string{"password"}
+	analyzer := NewTypeAnalyzer(cfg)
+
+	inline := []parser.FieldInfo{
+		{Name: "Host", Type: "string"},
+		{Name: "Password", Type: "string"},
+		{Name: "Debug", Type: "bool", LogOptions: parser.ParseLogTag("-")},
+	}
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			"inline struct",
+			parser.FieldInfo{Name: "Config", Type: "struct{Host string; Password string; Debug bool}", Fields: inline},
+			// gofmt removes the space left after the key
+			`slog.Group("Config", ` + `
+slog.String("Host", s.Config.Host),
+slog.String("Password", "[REDACTED]"),
+)`,
+		},
+		{
+			"pointer to inline struct",
+			parser.FieldInfo{Name: "Config", Type: "*struct{Host string; Password string; Debug bool}", IsPointer: true, Fields: inline},
+			`func() slog.Attr {
+if s.Config == nil {
+return slog.String("Config", "null")
+}
+return slog.Group("Config", ` + `
+slog.String("Host", s.Config.Host),
+slog.String("Password", "[REDACTED]"),
+)
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "s"); result != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerateLogStatementBigNumbers(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			"pointer to big.Int",
+			parser.FieldInfo{Name: "Amount", Type: "*big.Int", IsPointer: true},
+			`func() slog.Attr {
+if t.Amount == nil {
+return slog.String("Amount", "null")
+}
+return slog.String("Amount", t.Amount.String())
+}()`,
+		},
+		{
+			"pointer to big.Float",
+			parser.FieldInfo{Name: "Rate", Type: "*big.Float", IsPointer: true},
+			`func() slog.Attr {
+if t.Rate == nil {
+return slog.String("Rate", "null")
+}
+return slog.String("Rate", t.Rate.String())
+}()`,
+		},
+		{"big.Int", parser.FieldInfo{Name: "Fee", Type: "big.Int"}, `slog.String("Fee", t.Fee.String())`},
+		{"big.Float", parser.FieldInfo{Name: "Price", Type: "big.Float"}, `slog.String("Price", t.Price.String())`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if len(analysis.Imports) != 0 {
+				t.Errorf("Expected no imports, got %v", analysis.Imports)
+			}
+			if result := analyzer.GenerateLogStatement(analysis, "t"); result != tc.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.expected, result)
+			}
+		})
+	}
+
+	zerolog := NewZerologEmitter(analyzer)
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Fee", Type: "big.Int"})
+	if result := zerolog.Field(analysis, "t"); result != `Str("Fee", t.Fee.String())` {
+		t.Errorf("zerolog Field() = %q, expected %q", result, `Str("Fee", t.Fee.String())`)
+	}
+}
+
+func TestGenerateLogStatementSortMapKeys(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SortMapKeys = true
+	analyzer := NewTypeAnalyzer(cfg)
+
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Counts", Type: "map[string]int"})
+	if !analysis.SortKeys {
+		t.Fatal("Expected SortKeys for map[string]int")
+	}
+	if len(analysis.Imports) != 1 || analysis.Imports[0] != "sort" {
+		t.Errorf("Expected imports [sort], got %v", analysis.Imports)
+	}
+
+	expected := `func() slog.Attr {
+if c.Counts == nil {
+return slog.String("Counts", "null")
+}
+attrs := make([]slog.Attr, 0, len(c.Counts))
+m := c.Counts
+keys := make([]string, 0, len(m))
+for k := range m {
+keys = append(keys, k)
+}
+sort.Strings(keys)
+for _, k := range keys {
+v := m[k]
+attrs = append(attrs, slog.Any(k, v))
+}
+return slog.Attr{Key: "Counts", Value: slog.GroupValue(attrs...)}
+}()`
+	if result := analyzer.GenerateLogStatement(analysis, "c"); result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	// Keys from other packages cannot be named, so those maps are not sorted
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "ByID", Type: "map[uuid.UUID]int"})
+	if analysis.SortKeys {
+		t.Error("Expected no SortKeys for map[uuid.UUID]int")
+	}
+
+	// Without the option, maps log as a single value
+	analysis = NewTypeAnalyzer(config.DefaultConfig()).AnalyzeField(parser.FieldInfo{Name: "Counts", Type: "map[string]int"})
+	if analysis.SortKeys {
+		t.Error("Expected no SortKeys without sortMapKeys")
+	}
+}
+
+func TestGenerateLogStatementNilBehavior(t *testing.T) {
+	testCases := []struct {
+		behavior string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			config.NilBehaviorNull,
+			parser.FieldInfo{Name: "Count", Type: "*int", IsPointer: true},
+			`func() slog.Attr {
+if n.Count == nil {
+return slog.String("Count", "null")
+}
+return slog.Int64("Count", int64(*n.Count))
+}()`,
+		},
+		{
+			config.NilBehaviorOmit,
+			parser.FieldInfo{Name: "Count", Type: "*int", IsPointer: true},
+			`func() slog.Attr {
+if n.Count == nil {
+return slog.Attr{}
+}
+return slog.Int64("Count", int64(*n.Count))
+}()`,
+		},
+		{
+			config.NilBehaviorZero,
+			parser.FieldInfo{Name: "Count", Type: "*int", IsPointer: true},
+			`func() slog.Attr {
+if n.Count == nil {
+return slog.Int64("Count", 0)
+}
+return slog.Int64("Count", int64(*n.Count))
+}()`,
+		},
+		{
+			config.NilBehaviorZero,
+			parser.FieldInfo{Name: "Label", Type: "*string", IsPointer: true},
+			`func() slog.Attr {
+if n.Label == nil {
+return slog.String("Label", "")
+}
+return slog.String("Label", *n.Label)
+}()`,
+		},
+		{
+			config.NilBehaviorZero,
+			parser.FieldInfo{Name: "Tags", Type: "*[]string", IsPointer: true},
+			`func() slog.Attr {
+if n.Tags == nil {
+return slog.Any("Tags", *new([]string))
+}
+return slog.Any("Tags", *n.Tags)
+}()`,
+		},
+		// Types of other packages cannot be named, so they log "null"
+		{
+			config.NilBehaviorZero,
+			parser.FieldInfo{Name: "ID", Type: "*uuid.UUID", IsPointer: true},
+			`func() slog.Attr {
+if n.ID == nil {
+return slog.String("ID", "null")
+}
+return slog.Any("ID", *n.ID)
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		analyzer := NewTypeAnalyzer(&config.Config{NilBehavior: tc.behavior})
+		analysis := analyzer.AnalyzeField(tc.field)
+		if result := analyzer.GenerateLogStatement(analysis, "n"); result != tc.expected {
+			t.Errorf("%s %s: expected:\n%s\ngot:\n%s", tc.behavior, tc.field.Type, tc.expected, result)
+		}
+	}
+
+	// Zero times are spelled with the time package
+	analyzer := NewTypeAnalyzer(&config.Config{NilBehavior: config.NilBehaviorZero})
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "At", Type: "*time.Time", IsPointer: true})
+	if len(analysis.Imports) != 1 || analysis.Imports[0] != "time" {
+		t.Errorf("Expected imports [time], got %v", analysis.Imports)
+	}
+
+	// Nil generated structs log the zero value through their method
+	analyzer = analyzer.WithKnownStructs([]parser.StructInfo{{Name: "Item"}})
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "Item", Type: "*Item", IsPointer: true})
+	expected := `func() slog.Attr {
+if n.Item == nil {
+return slog.Attr{Key: "Item", Value: new(Item).LogValue()}
+}
+return slog.Attr{Key: "Item", Value: n.Item.LogValue()}
+}()`
+	if result := analyzer.GenerateLogStatement(analysis, "n"); result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	// With zerolog, omitted nil pointers return before logging
+	analyzer = NewTypeAnalyzer(&config.Config{NilBehavior: config.NilBehaviorOmit})
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "Count", Type: "*int", IsPointer: true})
+	expected = `Func(func(evt *zerolog.Event) {
+if n.Count == nil {
+return
+}
+evt.Int64("Count", int64(*n.Count))
+})`
+	if result := NewZerologEmitter(analyzer).Field(analysis, "n"); result != expected {
+		t.Errorf("zerolog Field() = %q, expected %q", result, expected)
+	}
+}
+
+func TestAnalyzeFieldAnyMatchesEmptyInterface(t *testing.T) {
+	configs := map[string]*config.Config{
+		"default":           config.DefaultConfig(),
+		"logInterfaceTypes": {LogInterfaceTypes: true},
+		"omitZero":          {OmitZero: true},
+		"nilBehavior zero":  {NilBehavior: config.NilBehaviorZero},
+		"generateRedacted":  {GenerateRedacted: true, RedactMessage: "[REDACTED]"},
+	}
+	prefixes := []string{"", "*", "[]", "map[string]", "*map[string]"}
+
+	for name, cfg := range configs {
+		analyzer := NewTypeAnalyzer(cfg)
+		zerolog := NewZerologEmitter(analyzer)
+
+		for _, prefix := range prefixes {
+			for _, tag := range []string{"", "redact"} {
+				field := func(fieldType string) parser.FieldInfo {
+					return parser.FieldInfo{Name: "Value", Type: prefix + fieldType, IsPointer: prefix != "" && prefix[0] == '*', LogOptions: parser.ParseLogTag(tag)}
+				}
+				anyAnalysis := analyzer.AnalyzeField(field("any"))
+				ifaceAnalysis := analyzer.AnalyzeField(field("interface{}"))
+
+				if anyAnalysis.SlogFunc != ifaceAnalysis.SlogFunc || anyAnalysis.Action != ifaceAnalysis.Action {
+					t.Errorf("%s %sany %q: expected %s %s, got %s %s", name, prefix, tag,
+						ifaceAnalysis.Action, ifaceAnalysis.SlogFunc, anyAnalysis.Action, anyAnalysis.SlogFunc)
+				}
+				if !reflect.DeepEqual(anyAnalysis.Imports, ifaceAnalysis.Imports) {
+					t.Errorf("%s %sany %q: expected imports %v, got %v", name, prefix, tag, ifaceAnalysis.Imports, anyAnalysis.Imports)
+				}
+
+				// Statements only differ in how the type is spelled
+				normalize := func(statement string) string {
+					return strings.ReplaceAll(statement, "interface{}", "any")
+				}
+				statements := map[string][2]string{
+					"slog":    {analyzer.GenerateLogStatement(anyAnalysis, "s"), analyzer.GenerateLogStatement(ifaceAnalysis, "s")},
+					"zerolog": {zerolog.Field(anyAnalysis, "s"), zerolog.Field(ifaceAnalysis, "s")},
+					"redact":  {analyzer.GenerateRedactStatement(anyAnalysis, "s"), analyzer.GenerateRedactStatement(ifaceAnalysis, "s")},
+				}
+				for kind, pair := range statements {
+					if pair[0] != normalize(pair[1]) {
+						t.Errorf("%s %sany %q %s: expected:\n%s\ngot:\n%s", name, prefix, tag, kind, normalize(pair[1]), pair[0])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateLogStatementNetworkTypes(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{"ip", parser.FieldInfo{Name: "Addr", Type: "net.IP"}, `slog.String("Addr", c.Addr.String())`},
+		{
+			"pointer to ip",
+			parser.FieldInfo{Name: "Peer", Type: "*net.IP", IsPointer: true},
+			`func() slog.Attr {
+if c.Peer == nil {
+return slog.String("Peer", "null")
+}
+return slog.String("Peer", c.Peer.String())
+}()`,
+		},
+		{"ip network", parser.FieldInfo{Name: "Subnet", Type: "net.IPNet"}, `slog.String("Subnet", c.Subnet.String())`},
+		{
+			"pointer to ip network",
+			parser.FieldInfo{Name: "Route", Type: "*net.IPNet", IsPointer: true},
+			`func() slog.Attr {
+if c.Route == nil {
+return slog.String("Route", "null")
+}
+return slog.String("Route", c.Route.String())
+}()`,
+		},
+		{
+			"omitted empty ip",
+			parser.FieldInfo{Name: "Addr", Type: "net.IP", LogOptions: parser.ParseLogTag("omitzero")},
+			`func() slog.Attr {
+if len(c.Addr) == 0 {
+return slog.Attr{}
+}
+return slog.String("Addr", c.Addr.String())
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if result := analyzer.GenerateLogStatement(analysis, "c"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+
+	// zerolog logs the same strings
+	zerolog := NewZerologEmitter(analyzer)
+	analysis := analyzer.AnalyzeField(parser.FieldInfo{Name: "Subnet", Type: "net.IPNet"})
+	if result := zerolog.Field(analysis, "c"); result != `Str("Subnet", c.Subnet.String())` {
+		t.Errorf("zerolog Field() = %q, expected %q", result, `Str("Subnet", c.Subnet.String())`)
+	}
+
+	// Redacted copies reset IPs to nil
+	analysis = analyzer.AnalyzeField(parser.FieldInfo{Name: "Addr", Type: "net.IP", LogOptions: parser.ParseLogTag("redact")})
+	if result := analyzer.GenerateRedactStatement(analysis, "c"); result != "c.Addr = nil" {
+		t.Errorf("GenerateRedactStatement() = %q, expected %q", result, "c.Addr = nil")
+	}
+}
+
+// logValuerType returns a named type of another package with a LogValue
+// method, on a pointer receiver if pointer is set
+func logValuerType(pointer bool) gotypes.Type {
+	slogPkg := gotypes.NewPackage("log/slog", "slog")
+	value := gotypes.NewNamed(gotypes.NewTypeName(0, slogPkg, "Value", nil), gotypes.NewStruct(nil, nil), nil)
+
+	bookingPkg := gotypes.NewPackage("example.com/shop/booking", "booking")
+	reservation := gotypes.NewNamed(gotypes.NewTypeName(0, bookingPkg, "Reservation", nil), gotypes.NewStruct(nil, nil), nil)
+
+	var receiver gotypes.Type = reservation
+	if pointer {
+		receiver = gotypes.NewPointer(reservation)
+	}
+	signature := gotypes.NewSignatureType(gotypes.NewVar(0, bookingPkg, "r", receiver), nil, nil, nil,
+		gotypes.NewTuple(gotypes.NewVar(0, slogPkg, "", value)), false)
+	reservation.AddMethod(gotypes.NewFunc(0, bookingPkg, "LogValue", signature))
+
+	return reservation
+}
+
+// stringerType returns a named integer type with a String method, on a
+// pointer receiver if pointer is set
+func stringerType(pointer bool) *gotypes.Named {
+	pkg := gotypes.NewPackage("example.com/shop/orders", "orders")
+	status := gotypes.NewNamed(gotypes.NewTypeName(0, pkg, "StatusCode", nil), gotypes.Typ[gotypes.Int], nil)
+
+	var receiver gotypes.Type = status
+	if pointer {
+		receiver = gotypes.NewPointer(status)
+	}
+	signature := gotypes.NewSignatureType(gotypes.NewVar(0, pkg, "s", receiver), nil, nil, nil,
+		gotypes.NewTuple(gotypes.NewVar(0, nil, "", gotypes.Typ[gotypes.String])), false)
+	status.AddMethod(gotypes.NewFunc(0, pkg, "String", signature))
+
+	return status
+}
+
+func TestAnalyzeFieldPointerStringer(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		stringer bool
+	}{
+		{"pointer to value receiver", parser.FieldInfo{Name: "Status", Type: "*StatusCode", IsPointer: true,
+			TypeInfo: gotypes.NewPointer(stringerType(false))}, true},
+		{"pointer to pointer receiver", parser.FieldInfo{Name: "Status", Type: "*StatusCode", IsPointer: true,
+			TypeInfo: gotypes.NewPointer(stringerType(true))}, true},
+		{"value", parser.FieldInfo{Name: "Status", Type: "StatusCode", TypeInfo: stringerType(false)}, false},
+		{"pointer without type information", parser.FieldInfo{Name: "Status", Type: "*StatusCode", IsPointer: true}, false},
+		{"pointer to duration", parser.FieldInfo{Name: "Timeout", Type: "*time.Duration", IsPointer: true}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if analysis.Stringer != tc.stringer {
+				t.Errorf("Stringer: expected %v, got %v", tc.stringer, analysis.Stringer)
+			}
+		})
+	}
+
+	field := parser.FieldInfo{Name: "Status", Type: "*StatusCode", IsPointer: true, TypeInfo: gotypes.NewPointer(stringerType(false))}
+	analysis := analyzer.AnalyzeField(field)
+
+	expected := `func() slog.Attr {
+if o.Status == nil {
+return slog.String("Status", "null")
+}
+return slog.String("Status", o.Status.String())
+}()`
+	if result := analyzer.GenerateLogStatement(analysis, "o"); result != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, result)
+	}
+
+	zerolog := NewZerologEmitter(analyzer)
+	expected = `Func(func(evt *zerolog.Event) {
+if o.Status == nil {
+evt.Str("Status", "null")
+return
+}
+evt.Str("Status", o.Status.String())
+})`
+	if result := zerolog.Field(analysis, "o"); result != expected {
+		t.Errorf("zerolog Field() = %q, expected %q", result, expected)
+	}
+}
+
+func TestGenerateLogStatementLogValuer(t *testing.T) {
+	analyzer := NewTypeAnalyzer(config.DefaultConfig())
+
+	testCases := []struct {
+		name     string
+		field    parser.FieldInfo
+		expected string
+	}{
+		{
+			"value receiver",
+			parser.FieldInfo{Name: "Booking", Type: "booking.Reservation", TypeInfo: logValuerType(false)},
+			`slog.Any("Booking", o.Booking)`,
+		},
+		{
+			"pointer receiver logged by address",
+			parser.FieldInfo{Name: "Booking", Type: "booking.Reservation", TypeInfo: logValuerType(true)},
+			`slog.Any("Booking", &o.Booking)`,
+		},
+		{
+			"pointer field logged as is",
+			parser.FieldInfo{Name: "Booking", Type: "*booking.Reservation", IsPointer: true,
+				TypeInfo: gotypes.NewPointer(logValuerType(true))},
+			`func() slog.Attr {
+if o.Booking == nil {
+return slog.String("Booking", "null")
+}
+return slog.Any("Booking", o.Booking)
+}()`,
+		},
+		// Without type information pointers are dereferenced as before
+		{
+			"pointer field without type information",
+			parser.FieldInfo{Name: "Booking", Type: "*booking.Reservation", IsPointer: true},
+			`func() slog.Attr {
+if o.Booking == nil {
+return slog.String("Booking", "null")
+}
+return slog.Any("Booking", *o.Booking)
+}()`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			analysis := analyzer.AnalyzeField(tc.field)
+			if analysis.LogValuer != tc.field.HasTypeInfo() {
+				t.Errorf("Expected LogValuer %v, got %v", tc.field.HasTypeInfo(), analysis.LogValuer)
+			}
+			if result := analyzer.GenerateLogStatement(analysis, "o"); result != tc.expected {
+				t.Errorf("GenerateLogStatement() = %q, expected %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestEmittersUnindented(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.NilBehavior = config.NilBehaviorZero
+	cfg.OmitZero = true
+	cfg.LogFuncFields = true
+	cfg.LogInterfaceTypes = true
+	cfg.SortMapKeys = true
+	cfg.MaxDepth = 3
+	analyzer := NewTypeAnalyzer(cfg).WithKnownStructs([]parser.StructInfo{
+		{Name: "Address", Fields: []parser.FieldInfo{{Name: "City", Type: "string"}}},
+		{Name: "Node", Fields: []parser.FieldInfo{{Name: "Next", Type: "*Node", IsPointer: true}}},
+	})
+
+	fields := []parser.FieldInfo{
+		{Name: "Token", Type: "*string", IsPointer: true, LogOptions: parser.ParseLogTag("mask")},
+		{Name: "Email", Type: "*string", IsPointer: true, LogOptions: parser.ParseLogTag("hash")},
+		{Name: "Active", Type: "*bool", IsPointer: true, LogOptions: parser.ParseLogTag("bool=yes/no")},
+		{Name: "Status", Type: "sql.NullString"},
+		{Name: "Callback", Type: "func()"},
+		{Name: "Addresses", Type: "map[string]*Address"},
+		{Name: "Counts", Type: "map[string]int"},
+		{Name: "Err", Type: "error"},
+		{Name: "Next", Type: "*Node", IsPointer: true},
+		{Name: "Limits", Type: "struct{Max *int}", Fields: []parser.FieldInfo{
+			{Name: "Max", Type: "*int", IsPointer: true},
+		}},
+	}
+
+	// Generated code is indented by gofmt, so the raw code has none of its own
+	emitters := map[string]Emitter{
+		"slog":    NewSlogEmitter(analyzer),
+		"closure": attrEmitter{analyzer: analyzer, dialect: analyzer.slogDialect()},
+		"zap":     NewZapEmitter(analyzer),
+		"zerolog": NewZerologEmitter(analyzer),
+	}
+	for name, emitter := range emitters {
+		for _, field := range fields {
+			code := emitter.Field(analyzer.AnalyzeField(field), "u")
+			for _, line := range strings.Split(code, "\n") {
+				if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ") {
+					t.Errorf("%s %s: expected no indented lines, got:\n%s", name, field.Name, code)
+					break
+				}
+			}
+		}
+	}
+}
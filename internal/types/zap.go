@@ -0,0 +1,19 @@
+package types
+
+// zapDialect builds go.uber.org/zap fields. Generated structs are logged
+// through their ZapFields method, or zapFields for recursive structs.
+var zapDialect = attrDialect{
+	pkg:        "zap",
+	fieldType:  "zap.Field",
+	empty:      "zap.Skip()",
+	group:      `zap.Dict(%q, %s)`,
+	groupSlice: `zap.Dict(%q, %s...)`,
+	nested:     `zap.Dict(%s, %s.ZapFields()...)`,
+	nestedNext: `zap.Dict(%s, %s.zapFields(depth + 1)...)`,
+	formatter:  `zap.Any(%[1]q, %[2]s(%[1]q, %[3]s).Value.Any())`,
+}
+
+// NewZapEmitter returns an Emitter producing zap.Field expressions
+func NewZapEmitter(ta *TypeAnalyzer) Emitter {
+	return attrEmitter{analyzer: ta, dialect: zapDialect}
+}
@@ -0,0 +1,346 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stuckinforloop/oak/internal/config"
+	"github.com/stuckinforloop/oak/internal/parser"
+)
+
+// ZerologEvent is the name of the *zerolog.Event parameter of generated
+// MarshalZerologObject methods, chosen not to collide with receiver names
+const ZerologEvent = "evt"
+
+// zerologFuncs maps slog field constructors to zerolog event methods
+var zerologFuncs = map[SlogFunction]string{
+	SlogInt64:    "Int64",
+	SlogUint64:   "Uint64",
+	SlogString:   "Str",
+	SlogBool:     "Bool",
+	SlogFloat64:  "Float64",
+	SlogTime:     "Time",
+	SlogDuration: "Dur",
+	SlogAny:      "Interface",
+}
+
+// zerologEmitter generates links of a zerolog event chain, such as
+// Int64("Age", int64(u.Age)), logging each field. Fields needing control
+// flow (nil checks, enums, omitted zero values) are logged through Func.
+type zerologEmitter struct {
+	analyzer *TypeAnalyzer
+}
+
+// NewZerologEmitter returns an Emitter producing zerolog event method calls
+func NewZerologEmitter(ta *TypeAnalyzer) Emitter {
+	return zerologEmitter{analyzer: ta}
+}
+
+// Field returns the event method call logging a field. Fields hoisted through
+// nil pointers are omitted at runtime.
+func (e zerologEmitter) Field(analysis FieldAnalysis, receiverName string) string {
+	link := e.generateLink(analysis, receiverName)
+	if link == "" {
+		return link
+	}
+
+	if analysis.OmitZero {
+		if isZero := zeroCheck(analysis.Field, e.analyzer.getFieldAccessor(analysis, receiverName)); isZero != "" {
+			link = e.guard(isZero, link)
+		}
+	}
+
+	if len(analysis.Guards) == 0 {
+		return link
+	}
+
+	var nilChecks []string
+	for _, guard := range analysis.Guards {
+		nilChecks = append(nilChecks, receiverName+guard+" == nil")
+	}
+	return e.guard(strings.Join(nilChecks, " || "), link)
+}
+
+// generateLink generates the event method call for a field according to its action
+func (e zerologEmitter) generateLink(analysis FieldAnalysis, receiverName string) string {
+	ta := e.analyzer
+	key := ta.attributeKey(analysis)
+	fieldAccessor := ta.getFieldAccessor(analysis, receiverName)
+
+	switch analysis.Action {
+	case ActionSkip:
+		return "" // Field should not appear in log output
+
+	case ActionRedact:
+		return fmt.Sprintf(`Str(%q, %q)`, key, analysis.LogValue)
+
+	case ActionMask:
+		return e.nilSafe(analysis, fieldAccessor, key, fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+v := []rune(%[2]s)
+if len(v) <= %[3]d {
+%[1]s.Str(%[4]q, strings.Repeat("*", len(v)))
+return
+}
+%[1]s.Str(%[4]q, strings.Repeat("*", len(v)-%[3]d)+string(v[len(v)-%[3]d:]))
+})`, ZerologEvent, ta.deref(analysis.Field, fieldAccessor), maskVisibleChars, key))
+
+	case ActionHash:
+		return e.nilSafe(analysis, fieldAccessor, key,
+			fmt.Sprintf(`Str(%q, %s)`, key, ta.hashValue(analysis.Field, fieldAccessor)))
+
+	case ActionLog:
+		return e.generateNormalLink(analysis, receiverName)
+
+	default:
+		return fmt.Sprintf(`Interface(%q, %s)`, key, fieldAccessor)
+	}
+}
+
+// generateNormalLink generates the event method call for a normally logged field
+func (e zerologEmitter) generateNormalLink(analysis FieldAnalysis, receiverName string) string {
+	ta := e.analyzer
+	key := ta.attributeKey(analysis)
+	fieldAccessor := ta.getFieldAccessor(analysis, receiverName)
+	value := ta.deref(analysis.Field, fieldAccessor)
+	fieldType := strings.TrimPrefix(analysis.Field.Type, "*")
+
+	var link string
+	switch {
+	case analysis.Formatter != "":
+		// Custom formatters return a slog.Attr, whose value is logged
+		link = fmt.Sprintf(`Interface(%q, %s(%q, %s).Value.Any())`, key, analysis.Formatter, key, value)
+
+	case analysis.Forced != "":
+		link = fmt.Sprintf(`%s(%q, %s)`, zerologFuncs[analysis.SlogFunc], key, forcedArg(analysis, value))
+
+	case analysis.BoolText != [2]string{}:
+		link = fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if %[2]s {
+%[1]s.Str(%[3]q, %[4]q)
+return
+}
+%[1]s.Str(%[3]q, %[5]q)
+})`, ZerologEvent, value, key, analysis.BoolText[0], analysis.BoolText[1])
+
+	case analysis.Stringer:
+		link = fmt.Sprintf(`Str(%q, %s.String())`, key, fieldAccessor)
+
+	case len(analysis.Enum) > 0:
+		link = e.generateEnumLink(analysis.Enum, key, value)
+
+	case isSQLNullType(fieldType):
+		// database/sql Null types log their value when valid and "null" otherwise
+		link = fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if !%[2]s.Valid {
+%[1]s.Str(%[3]q, "null")
+return
+}
+%[1]s.%[4]s(%[3]q, %[5]s)
+})`, ZerologEvent, fieldAccessor, key, zerologFuncs[analysis.SlogFunc], ta.sqlNullArg(analysis, fieldAccessor))
+
+	case analysis.MapValue != nil || analysis.SortKeys:
+		link = e.generateMapLink(analysis, fieldAccessor, key)
+
+	case analysis.Nested != nil && analysis.Recursive:
+		// Recursive structs are marshaled one level deeper into a dictionary
+		link = e.depthGuard(key, fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+dict := zerolog.Dict()
+%[2]s.marshalZerologObject(dict, depth+1)
+%[1]s.Dict(%[3]q, dict)
+})`, ZerologEvent, fieldAccessor, key))
+
+	case analysis.Nested != nil:
+		// Generated structs implement zerolog.LogObjectMarshaler
+		if analysis.Field.IsPointer {
+			link = fmt.Sprintf(`Object(%q, %s)`, key, fieldAccessor)
+		} else {
+			link = fmt.Sprintf(`Object(%q, &%s)`, key, fieldAccessor)
+		}
+
+	case len(analysis.Field.Fields) > 0:
+		// Inline anonymous structs are logged as a dictionary of their fields
+		var links strings.Builder
+		for _, child := range analysis.Inline {
+			child.Parent = analysis.Parent + "." + analysis.Field.Name
+			fmt.Fprintf(&links, ".\n%s", e.Field(child, receiverName))
+		}
+		link = fmt.Sprintf(`Dict(%q, zerolog.Dict()%s)`, key, links.String())
+
+	case analysis.SlogFunc == SlogInt64 && analysis.Field.Type != "int64":
+		link = fmt.Sprintf(`Int64(%q, int64(%s))`, key, value)
+
+	case analysis.SlogFunc == SlogUint64:
+		link = fmt.Sprintf(`Uint64(%q, uint64(%s))`, key, value)
+
+	case analysis.SlogFunc == SlogFloat64 && analysis.Field.Type != "float64":
+		link = fmt.Sprintf(`Float64(%q, float64(%s))`, key, value)
+
+	case IsByteArrayType(fieldType):
+		// Slicing a pointer to an array needs no explicit dereference
+		link = fmt.Sprintf(`Str(%q, hex.EncodeToString(%s[:]))`, key, fieldAccessor)
+
+	case isFuncType(fieldType) || isChanType(fieldType):
+		description, _ := opaqueDescription(fieldType)
+		link = fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if %[2]s == nil {
+%[1]s.Str(%[3]q, "null")
+return
+}
+%[1]s.Str(%[3]q, %[4]q)
+})`, ZerologEvent, value, key, description)
+
+	case isStringerType(fieldType):
+		link = fmt.Sprintf(`Str(%q, %s.String())`, key, fieldAccessor)
+
+	case fieldType == rawMessageType:
+		link = fmt.Sprintf(`Str(%q, string(%s))`, key, value)
+
+	case isByteSliceType(fieldType):
+		link = fmt.Sprintf(`Str(%q, base64.StdEncoding.EncodeToString(%s))`, key, value)
+
+	case fieldType == "unsafe.Pointer":
+		link = fmt.Sprintf(`Str(%q, fmt.Sprintf("%%p", %s))`, key, value)
+
+	case fieldType == "time.Time" && analysis.SlogFunc == SlogString:
+		link = fmt.Sprintf(`Str(%q, %s.Format(%s))`, key, fieldAccessor, ta.timeLayout())
+
+	case fieldType == "time.Duration" && analysis.SlogFunc == SlogString:
+		link = fmt.Sprintf(`Str(%q, %s.String())`, key, fieldAccessor)
+
+	case analysis.SlogFunc == SlogAny && ta.config.LogInterfaceTypes && isInterfaceField(analysis.Field):
+		link = fmt.Sprintf(`Dict(%q, zerolog.Dict().Str("type", fmt.Sprintf("%%T", %s)).Interface("value", %s))`, key, value, value)
+
+	default:
+		link = fmt.Sprintf(`%s(%q, %s)`, zerologFuncs[analysis.SlogFunc], key, ta.capSlice(analysis, value))
+	}
+
+	// Interfaces holding a nil pointer log its type instead, since
+	// marshaling may call methods such as Error on the pointer
+	if analysis.Formatter == "" && isInterfaceField(analysis.Field) {
+		link = fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if %[2]s {
+%[1]s.Str(%[3]q, fmt.Sprintf("%%T(nil)", %[4]s))
+return
+}
+%[1]s.%[5]s
+})`, ZerologEvent, typedNil(value), key, value, link)
+	}
+
+	return e.nilSafe(analysis, fieldAccessor, key, link)
+}
+
+// generateEnumLink generates a switch logging the name of the constant
+// matching value, falling back to the integer for values without a constant
+func (e zerologEmitter) generateEnumLink(constants []parser.EnumConstant, key, value string) string {
+	var cases strings.Builder
+	for _, constant := range constants {
+		fmt.Fprintf(&cases, "case %s:\n%s.Str(%q, %q)\n", constant.Expr, ZerologEvent, key, constant.Name)
+	}
+
+	return fmt.Sprintf(`Func(func(%s *zerolog.Event) {
+switch %s {
+%sdefault:
+%s.Int64(%q, int64(%s))
+}
+})`, ZerologEvent, value, cases.String(), ZerologEvent, key, value)
+}
+
+// generateMapLink generates a dictionary holding an object per entry of a
+// map of generated structs, or a value per entry of any map when sorting keys
+// (or a pointer to one), keyed by the stringified map key. Nil maps, nil map
+// pointers, and nil generated struct pointers log "null".
+func (e zerologEmitter) generateMapLink(analysis FieldAnalysis, fieldAccessor, key string) string {
+	keyType, valueType, _ := splitMapType(strings.TrimPrefix(analysis.Field.Type, "*"))
+
+	mapValue := fieldAccessor
+	if analysis.Field.IsPointer {
+		mapValue = "(*" + fieldAccessor + ")"
+	}
+
+	entryKey := "k"
+	if keyType != "string" {
+		entryKey = "fmt.Sprint(k)"
+	}
+
+	entry := fmt.Sprintf(`dict.Object(%s, &v)`, entryKey)
+	switch {
+	case analysis.MapValue == nil:
+		entry = fmt.Sprintf(`dict.Interface(%s, v)`, entryKey)
+	case analysis.Recursive:
+		entry = fmt.Sprintf(`entry := zerolog.Dict()
+v.marshalZerologObject(entry, depth+1)
+dict.Dict(%s, entry)`, entryKey)
+	}
+	if analysis.MapValue != nil && strings.HasPrefix(valueType, "*") {
+		entry = fmt.Sprintf(`if v == nil {
+dict.Str(%s, "null")
+continue
+}
+%s`, entryKey, strings.Replace(entry, "&v", "v", 1))
+	}
+
+	link := fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if %[2]s == nil {
+%[1]s.Str(%[3]q, "null")
+return
+}
+dict := zerolog.Dict()
+%[5]s
+%[4]s
+}
+%[1]s.Dict(%[3]q, dict)
+})`, ZerologEvent, mapValue, key, entry, rangeMap(mapValue, keyType, analysis.SortKeys))
+	if analysis.Recursive {
+		link = e.depthGuard(key, link)
+	}
+	return link
+}
+
+// guard wraps a link so the field is only logged when cond is false
+func (e zerologEmitter) guard(cond, link string) string {
+	return fmt.Sprintf(`Func(func(%s *zerolog.Event) {
+if %s {
+return
+}
+%s.%s
+})`, ZerologEvent, cond, ZerologEvent, link)
+}
+
+// depthGuard wraps a link logging recursive structs so that, at the
+// configured depth limit, the field logs a marker instead
+func (e zerologEmitter) depthGuard(key, link string) string {
+	return fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if depth >= %[2]d {
+%[1]s.Str(%[3]q, %[4]q)
+return
+}
+%[1]s.%[5]s
+})`, ZerologEvent, e.analyzer.maxDepth(), key, maxDepthValue, link)
+}
+
+// nilSafe wraps a link for a pointer field so nil pointers log "null", are
+// omitted, or log the zero value of their type, per nilBehavior
+func (e zerologEmitter) nilSafe(analysis FieldAnalysis, fieldAccessor, key, link string) string {
+	if !analysis.Field.IsPointer {
+		return link
+	}
+
+	then := fmt.Sprintf(`Str(%q, "null")`, key)
+	switch e.analyzer.config.NilBehavior {
+	case config.NilBehaviorOmit:
+		return e.guard(fieldAccessor+" == nil", link)
+	case config.NilBehaviorZero:
+		if fn, value, ok := pointeeZero(analysis); ok && analysis.Nested != nil {
+			then = fmt.Sprintf(`Object(%q, %s)`, key, value)
+		} else if ok {
+			then = fmt.Sprintf(`%s(%q, %s)`, zerologFuncs[fn], key, value)
+		}
+	}
+	return fmt.Sprintf(`Func(func(%[1]s *zerolog.Event) {
+if %[2]s == nil {
+%[1]s.%[3]s
+return
+}
+%[1]s.%[4]s
+})`, ZerologEvent, fieldAccessor, then, link)
+}
@@ -0,0 +1,20 @@
+package version
+
+import "runtime/debug"
+
+// Version is set at build time via -ldflags "-X .../internal/version.Version=..."
+var Version string
+
+// Get returns the oak version, falling back to the module version recorded in
+// the build info when no version was set at build time
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+
+	if buildInfo, exists := debug.ReadBuildInfo(); exists && buildInfo.Main.Version != "" {
+		return buildInfo.Main.Version
+	}
+
+	return "unknown"
+}
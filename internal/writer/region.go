@@ -0,0 +1,178 @@
+package writer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"github.com/stuckinforloop/oak/internal/types"
+)
+
+// Sentinel comments delimiting the code Oak generates into a source file with
+// inlineOutput
+const (
+	RegionBegin = "// oak:begin"
+	RegionEnd   = "// oak:end"
+)
+
+// regionNote follows the begin sentinel, warning against editing the region
+const regionNote = "// Code between oak:begin and oak:end is generated by oak; DO NOT EDIT."
+
+// Splice returns source with the code between the oak:begin and oak:end
+// sentinel comments replaced by content, or with a region holding content
+// appended when the file has none. Of imports, those content refers to are
+// added, and those only the previous region referred to are removed; packages
+// the file already imports under another name are referred to by that name.
+// The result is formatted.
+func Splice(source []byte, content string, imports []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	original, err := parser.ParseFile(fset, "", source, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source: %w", err)
+	}
+
+	// Imports in use before splicing, which the new region may no longer need
+	var used []string
+	for _, spec := range original.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if astutil.UsesImport(original, path) {
+			used = append(used, path)
+		}
+	}
+
+	region := RegionBegin + "\n" + regionNote + "\n\n" + strings.TrimSpace(content) + "\n" + RegionEnd + "\n"
+
+	before, after, found, err := findRegion(string(source))
+	if err != nil {
+		return nil, err
+	}
+	var spliced string
+	if found {
+		spliced = before + region + after
+	} else {
+		before = strings.TrimRight(string(source), "\n") + "\n\n"
+		spliced = before + region
+	}
+
+	fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", spliced, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spliced source: %w", err)
+	}
+
+	start := file.FileStart + token.Pos(len(before))
+	end := start + token.Pos(len(region))
+	aliases := importAliases(file)
+	for _, path := range imports {
+		name := types.PackageName(path)
+		if !refersTo(file, name) {
+			continue
+		}
+		// A region declaring the alias itself, such as a receiver named like
+		// it, cannot refer to the package by it
+		if alias, ok := aliases[path]; ok && !declares(file, alias, start, end) {
+			rename(file, name, alias)
+			continue
+		}
+		astutil.AddImport(fset, file, path)
+	}
+	for _, path := range used {
+		if !astutil.UsesImport(file, path) {
+			astutil.DeleteImport(fset, file, path)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to format spliced source: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// refersTo reports whether a file refers to name without declaring it, as it
+// refers to the name of an imported package
+func refersTo(file *ast.File, name string) bool {
+	for _, ident := range file.Unresolved {
+		if ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// importAliases maps the paths of the packages a file imports under an alias
+// to the alias
+func importAliases(file *ast.File) map[string]string {
+	aliases := make(map[string]string)
+	for _, spec := range file.Imports {
+		if spec.Name == nil || spec.Name.Name == "_" || spec.Name.Name == "." {
+			continue
+		}
+		path, _ := strconv.Unquote(spec.Path.Value)
+		aliases[path] = spec.Name.Name
+	}
+	return aliases
+}
+
+// declares reports whether an identifier named name between start and end
+// refers to a declaration of the file rather than to an imported package
+func declares(file *ast.File, name string, start, end token.Pos) bool {
+	found := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name && ident.Obj != nil &&
+			ident.Pos() >= start && ident.Pos() < end {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// rename renames the references of a file to the package name to alias
+func rename(file *ast.File, name, alias string) {
+	for _, ident := range file.Unresolved {
+		if ident.Name == name {
+			ident.Name = alias
+		}
+	}
+}
+
+// findRegion splits source around its oak:begin/oak:end region, reporting
+// whether there is one. Sentinels must each be on a line of their own, and a
+// file holds at most one region.
+func findRegion(source string) (before, after string, found bool, err error) {
+	lines := strings.SplitAfter(source, "\n")
+	begin, end := -1, -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case RegionBegin:
+			if begin >= 0 {
+				return "", "", false, fmt.Errorf("line %d: duplicate %s", i+1, RegionBegin)
+			}
+			begin = i
+		case RegionEnd:
+			if begin < 0 {
+				return "", "", false, fmt.Errorf("line %d: %s without %s", i+1, RegionEnd, RegionBegin)
+			}
+			if end >= 0 {
+				return "", "", false, fmt.Errorf("line %d: duplicate %s", i+1, RegionEnd)
+			}
+			end = i
+		}
+	}
+
+	switch {
+	case begin < 0:
+		return "", "", false, nil
+	case end < 0:
+		return "", "", false, fmt.Errorf("line %d: %s without %s", begin+1, RegionBegin, RegionEnd)
+	}
+	return strings.Join(lines[:begin], ""), strings.Join(lines[end+1:], ""), true, nil
+}
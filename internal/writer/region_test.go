@@ -0,0 +1,268 @@
+package writer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuckinforloop/oak/internal/generator"
+)
+
+const regionUser = `package models
+
+// User is logged
+type User struct {
+	ID   int
+	Name string
+}
+`
+
+const regionLogValue = `// LogValue implements slog.LogValuer for User
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int64("ID", int64(u.ID)))
+}
+`
+
+func TestSpliceInsert(t *testing.T) {
+	got, err := Splice([]byte(regionUser), regionLogValue, []string{"fmt", "log/slog"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+
+	expected := `package models
+
+import "log/slog"
+
+// User is logged
+type User struct {
+	ID   int
+	Name string
+}
+
+// oak:begin
+// Code between oak:begin and oak:end is generated by oak; DO NOT EDIT.
+
+// LogValue implements slog.LogValuer for User
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int64("ID", int64(u.ID)))
+}
+
+// oak:end
+`
+	if string(got) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	// Splicing the same content again leaves the file unchanged
+	again, err := Splice(got, regionLogValue, []string{"fmt", "log/slog"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("Expected splicing to be idempotent, got:\n%s", again)
+	}
+}
+
+func TestSpliceReplace(t *testing.T) {
+	source := `package models
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// oak:begin
+// Code between oak:begin and oak:end is generated by oak; DO NOT EDIT.
+
+// LogValue implements slog.LogValuer for User
+func (u User) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprint(u.ID))
+}
+
+// oak:end
+
+// User is logged
+type User struct {
+	ID   int
+	Name string
+}
+
+func (u User) String() string { return strings.ToUpper(u.Name) }
+`
+
+	got, err := Splice([]byte(source), regionLogValue, []string{"log/slog"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+
+	// fmt was only used by the previous region
+	expected := `package models
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// oak:begin
+// Code between oak:begin and oak:end is generated by oak; DO NOT EDIT.
+
+// LogValue implements slog.LogValuer for User
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int64("ID", int64(u.ID)))
+}
+
+// oak:end
+
+// User is logged
+type User struct {
+	ID   int
+	Name string
+}
+
+func (u User) String() string { return strings.ToUpper(u.Name) }
+`
+	if string(got) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+}
+
+func TestSpliceAliasedImport(t *testing.T) {
+	source := `package models
+
+import t "time"
+
+// Event is logged
+type Event struct {
+	At t.Time
+}
+`
+	content := `// LogValue implements slog.LogValuer for Event
+func (e Event) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("At", e.At.Format(time.RFC3339)))
+}
+`
+
+	got, err := Splice([]byte(source), content, []string{"log/slog", "time"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+
+	// The region refers to time by its existing alias rather than importing it again
+	expected := `package models
+
+import (
+	"log/slog"
+	t "time"
+)
+
+// Event is logged
+type Event struct {
+	At t.Time
+}
+
+// oak:begin
+// Code between oak:begin and oak:end is generated by oak; DO NOT EDIT.
+
+// LogValue implements slog.LogValuer for Event
+func (e Event) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("At", e.At.Format(t.RFC3339)))
+}
+
+// oak:end
+`
+	if string(got) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, got)
+	}
+
+	again, err := Splice(got, content, []string{"log/slog", "time"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("Expected splicing to be idempotent, got:\n%s", again)
+	}
+
+	// A receiver named like the alias shadows it, so time is imported by name
+	shadowed := strings.ReplaceAll(content, "(e Event)", "(t Event)")
+	shadowed = strings.ReplaceAll(shadowed, "e.At", "t.At")
+	got, err = Splice([]byte(source), shadowed, []string{"log/slog", "time"})
+	if err != nil {
+		t.Fatalf("Splice failed: %v", err)
+	}
+	if !strings.Contains(string(got), "t.At.Format(time.RFC3339)") || !strings.Contains(string(got), "\t\"time\"\n") {
+		t.Errorf("Expected time imported by name for a shadowed alias, got:\n%s", got)
+	}
+}
+
+func TestSpliceMalformedRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		region   string
+		expected string
+	}{
+		{"begin without end", "// oak:begin\n", "line 9: // oak:begin without // oak:end"},
+		{"end without begin", "// oak:end\n", "line 9: // oak:end without // oak:begin"},
+		{"duplicate begin", "// oak:begin\n// oak:begin\n// oak:end\n", "line 10: duplicate // oak:begin"},
+		{"duplicate end", "// oak:begin\n// oak:end\n// oak:end\n", "line 11: duplicate // oak:end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := regionUser + "\n" + tt.region
+			_, err := Splice([]byte(source), regionLogValue, nil)
+			if err == nil {
+				t.Fatalf("Expected error for %s", tt.name)
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestWriteResultInline(t *testing.T) {
+	writer := New()
+	filePath := filepath.Join(t.TempDir(), "user.go")
+	if err := os.WriteFile(filePath, []byte(regionUser), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	result := &generator.GenerationResult{
+		PackageName: "models",
+		FilePath:    filePath,
+		Content:     regionLogValue,
+		Inline:      true,
+		Imports:     []string{"log/slog"},
+	}
+
+	// The diff shows the region spliced into the source file
+	patch, err := writer.DiffResult(result)
+	if err != nil {
+		t.Fatalf("DiffResult failed: %v", err)
+	}
+	if !strings.Contains(patch, "+// oak:begin") {
+		t.Errorf("Expected diff to add the region, got:\n%s", patch)
+	}
+
+	if err := writer.WriteResult(result); err != nil {
+		t.Fatalf("WriteResult failed: %v", err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read source file: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "package models\n\nimport \"log/slog\"\n\n// User is logged\n") {
+		t.Errorf("Expected source file to be kept, got:\n%s", content)
+	}
+	if !strings.Contains(string(content), RegionBegin+"\n") || !strings.HasSuffix(string(content), RegionEnd+"\n") {
+		t.Errorf("Expected region to be appended, got:\n%s", content)
+	}
+
+	// A missing source file is not created
+	result.FilePath = filepath.Join(t.TempDir(), "missing.go")
+	if err := writer.WriteResult(result); err == nil {
+		t.Errorf("Expected error for missing source file")
+	}
+}
@@ -1,16 +1,35 @@
 package writer
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/stuckinforloop/oak/internal/diff"
 	"github.com/stuckinforloop/oak/internal/generator"
 )
 
+// generatedHeader matches the header Oak writes at the top of generated
+// files, including the versionless header written by older releases
+var generatedHeader = regexp.MustCompile(`^// Code generated by oak.* DO NOT EDIT\.$`)
+
 // Writer handles writing generated code to files
 type Writer struct {
-	// Add any configuration if needed in the future
+	// OutputDir, when set, mirrors generated files under this directory at
+	// their path relative to Root. A relative OutputDir is relative to Root.
+	OutputDir string
+
+	// Root is the directory mirrored under OutputDir, by default the working
+	// directory
+	Root string
+
+	// Force writes files even when their content is already identical
+	Force bool
+
+	rewritten []string // Files written because their content changed
 }
 
 // New creates a new Writer instance
@@ -18,33 +37,136 @@ func New() *Writer {
 	return &Writer{}
 }
 
-// WriteResult writes a GenerationResult to the filesystem
+// WriteResult writes a GenerationResult to the filesystem. Files whose
+// content is already identical are left untouched, so their modification time
+// does not change, unless Force is set. Inline results are spliced into their
+// source file.
 func (w *Writer) WriteResult(result *generator.GenerationResult) error {
 	if result == nil {
 		return fmt.Errorf("generation result is nil")
 	}
 
+	filePath, err := w.OutputPath(result.FilePath)
+	if err != nil {
+		return err
+	}
+
+	content, err := fileContent(result, filePath)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(filePath)
+	if err == nil && !w.Force && bytes.Equal(existing, []byte(content)) {
+		fmt.Printf("Unchanged: %s\n", filePath)
+		return nil
+	}
+
 	// Ensure the directory exists
-	dir := filepath.Dir(result.FilePath)
+	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
 	// Check if file already exists and warn about overwriting
-	if _, err := os.Stat(result.FilePath); err == nil {
+	if err == nil {
 		// File exists, we'll overwrite it (this is expected behavior for generated files)
-		fmt.Printf("Overwriting existing file: %s\n", result.FilePath)
+		fmt.Printf("Overwriting existing file: %s\n", filePath)
 	}
 
 	// Write the generated content to the file
-	if err := os.WriteFile(result.FilePath, []byte(result.Content), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", result.FilePath, err)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
 	}
 
-	fmt.Printf("Generated: %s\n", result.FilePath)
+	w.rewritten = append(w.rewritten, filePath)
+	fmt.Printf("Generated: %s\n", filePath)
 	return nil
 }
 
+// OutputPath returns the path a generated file is written to: the path itself,
+// or with OutputDir set, its path relative to Root joined under OutputDir.
+// Files outside Root cannot be mirrored.
+func (w *Writer) OutputPath(filePath string) (string, error) {
+	if w.OutputDir == "" {
+		return filePath, nil
+	}
+
+	root, err := filepath.Abs(w.Root)
+	if err != nil {
+		return "", fmt.Errorf("invalid root directory %s: %w", w.Root, err)
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid output path %s: %w", filePath, err)
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("cannot mirror %s under %s: file is outside %s", filePath, w.OutputDir, root)
+	}
+
+	outputDir := w.OutputDir
+	if !filepath.IsAbs(outputDir) {
+		outputDir = filepath.Join(root, outputDir)
+	}
+	return filepath.Join(outputDir, rel), nil
+}
+
+// Rewritten returns the files written so far whose content changed, in the
+// order they were written
+func (w *Writer) Rewritten() []string {
+	return w.rewritten
+}
+
+// DiffResult returns a unified diff between the file on disk and the
+// generated content, without writing. A missing file is diffed as empty, and
+// an empty string means the file is up to date.
+func (w *Writer) DiffResult(result *generator.GenerationResult) (string, error) {
+	if result == nil {
+		return "", fmt.Errorf("generation result is nil")
+	}
+
+	filePath, err := w.OutputPath(result.FilePath)
+	if err != nil {
+		return "", err
+	}
+
+	oldName := filePath
+	existing, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		oldName = "/dev/null"
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	content, err := fileContent(result, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return diff.Unified(oldName, filePath, string(existing), content), nil
+}
+
+// fileContent returns the content of the file a result is written to: the
+// generated content, or for inline results the source file with the
+// generated region spliced in
+func fileContent(result *generator.GenerationResult, filePath string) (string, error) {
+	if !result.Inline {
+		return result.Content, nil
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source file %s: %w", filePath, err)
+	}
+	spliced, err := Splice(source, result.Content, result.Imports)
+	if err != nil {
+		return "", fmt.Errorf("failed to splice generated code into %s: %w", filePath, err)
+	}
+	return string(spliced), nil
+}
+
 // WriteResults writes multiple GenerationResults to the filesystem
 func (w *Writer) WriteResults(results []*generator.GenerationResult) error {
 	if len(results) == 0 {
@@ -146,9 +268,8 @@ func IsGeneratedFile(filePath string) (bool, error) {
 		return false, err
 	}
 
-	// Check for Oak's generation marker
-	contentStr := string(content)
-	marker := "// Code generated by oak. DO NOT EDIT."
+	// Check for Oak's generation marker on the first line
+	firstLine, _, _ := strings.Cut(string(content), "\n")
 
-	return len(contentStr) >= len(marker) && contentStr[:len(marker)] == marker, nil
+	return generatedHeader.MatchString(strings.TrimSuffix(firstLine, "\r")), nil
 }
@@ -3,8 +3,10 @@ package writer
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stuckinforloop/oak/internal/generator"
 )
@@ -40,6 +42,89 @@ func TestWriteResult(t *testing.T) {
 	}
 }
 
+func TestWriteResultSkipsIdenticalContent(t *testing.T) {
+	writer := New()
+	tempDir := t.TempDir()
+
+	result := &generator.GenerationResult{
+		PackageName: "test",
+		FilePath:    filepath.Join(tempDir, "oak_gen.go"),
+		Content:     "package test\n\nvar a = 1\n",
+	}
+	if err := os.WriteFile(result.FilePath, []byte(result.Content), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(result.FilePath, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	// An identical write leaves the file untouched
+	if err := writer.WriteResult(result); err != nil {
+		t.Fatalf("WriteResult failed: %v", err)
+	}
+	info, err := os.Stat(result.FilePath)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("Expected modification time %v to be kept, got %v", past, info.ModTime())
+	}
+	if len(writer.Rewritten()) != 0 {
+		t.Errorf("Expected no rewritten files, got %v", writer.Rewritten())
+	}
+
+	// A changed write proceeds
+	result.Content = "package test\n\nvar a = 2\n"
+	if err := writer.WriteResult(result); err != nil {
+		t.Fatalf("WriteResult failed: %v", err)
+	}
+	content, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != result.Content {
+		t.Errorf("File content mismatch.\nExpected: %s\nGot: %s", result.Content, string(content))
+	}
+	if !slices.Equal(writer.Rewritten(), []string{result.FilePath}) {
+		t.Errorf("Expected rewritten files [%s], got %v", result.FilePath, writer.Rewritten())
+	}
+}
+
+func TestWriteResultForce(t *testing.T) {
+	writer := New()
+	writer.Force = true
+	tempDir := t.TempDir()
+
+	result := &generator.GenerationResult{
+		PackageName: "test",
+		FilePath:    filepath.Join(tempDir, "oak_gen.go"),
+		Content:     "package test\n\nvar a = 1\n",
+	}
+	if err := os.WriteFile(result.FilePath, []byte(result.Content), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(result.FilePath, past, past); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	// Forced writes rewrite identical content
+	if err := writer.WriteResult(result); err != nil {
+		t.Fatalf("WriteResult failed: %v", err)
+	}
+	info, err := os.Stat(result.FilePath)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.ModTime().Equal(past) {
+		t.Errorf("Expected modification time to change from %v", past)
+	}
+	if !slices.Equal(writer.Rewritten(), []string{result.FilePath}) {
+		t.Errorf("Expected rewritten files [%s], got %v", result.FilePath, writer.Rewritten())
+	}
+}
+
 func TestWriteResultNil(t *testing.T) {
 	writer := New()
 
@@ -54,6 +139,43 @@ func TestWriteResultNil(t *testing.T) {
 	}
 }
 
+func TestDiffResult(t *testing.T) {
+	writer := New()
+	tempDir := t.TempDir()
+
+	result := &generator.GenerationResult{
+		PackageName: "test",
+		FilePath:    filepath.Join(tempDir, "oak_gen.go"),
+		Content:     "package test\n\nvar a = 2\n",
+	}
+
+	// A missing file is diffed against /dev/null
+	patch, err := writer.DiffResult(result)
+	if err != nil {
+		t.Fatalf("DiffResult failed: %v", err)
+	}
+	if !strings.HasPrefix(patch, "--- /dev/null\n+++ "+result.FilePath+"\n@@ -0,0 +1,3 @@\n") {
+		t.Errorf("Unexpected diff for missing file:\n%s", patch)
+	}
+
+	if err := os.WriteFile(result.FilePath, []byte("package test\n\nvar a = 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	patch, err = writer.DiffResult(result)
+	if err != nil {
+		t.Fatalf("DiffResult failed: %v", err)
+	}
+	if !strings.HasSuffix(patch, "-var a = 1\n+var a = 2\n") {
+		t.Errorf("Unexpected diff for changed file:\n%s", patch)
+	}
+
+	// Nothing is written, and an up-to-date file has an empty diff
+	result.Content = "package test\n\nvar a = 1\n"
+	if patch, err = writer.DiffResult(result); err != nil || patch != "" {
+		t.Errorf("Expected empty diff for up-to-date file, got %q (%v)", patch, err)
+	}
+}
+
 func TestWriteResults(t *testing.T) {
 	writer := New()
 	tempDir := t.TempDir()
@@ -229,6 +351,22 @@ func TestIsGeneratedFile(t *testing.T) {
 		t.Errorf("Expected generated file to be detected as generated")
 	}
 
+	// Test file with a versioned header
+	versionedFile := filepath.Join(tempDir, "versioned.go")
+	versionedContent := "// Code generated by oak v1.2.0; DO NOT EDIT.\n\npackage test\n"
+	err = os.WriteFile(versionedFile, []byte(versionedContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	isGenerated, err = IsGeneratedFile(versionedFile)
+	if err != nil {
+		t.Errorf("IsGeneratedFile failed: %v", err)
+	}
+	if !isGenerated {
+		t.Errorf("Expected file with versioned header to be detected as generated")
+	}
+
 	// Test normal file
 	isGenerated, err = IsGeneratedFile(normalFile)
 	if err != nil {
@@ -248,3 +386,78 @@ func TestIsGeneratedFile(t *testing.T) {
 		t.Errorf("Expected non-existent file to not be detected as generated")
 	}
 }
+
+func TestOutputPath(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "internal", "booking", "oak_gen.go")
+	outside := filepath.Join(filepath.Dir(root), "other", "oak_gen.go")
+	absoluteDir := filepath.Join(t.TempDir(), "generated")
+
+	testCases := []struct {
+		name      string
+		outputDir string
+		filePath  string
+		expected  string
+		expectErr bool
+	}{
+		{"no output dir", "", source, source, false},
+		{"relative output dir", "gen", source, filepath.Join(root, "gen", "internal", "booking", "oak_gen.go"), false},
+		{"absolute output dir", absoluteDir, source, filepath.Join(absoluteDir, "internal", "booking", "oak_gen.go"), false},
+		{"file at root", "gen", filepath.Join(root, "oak_gen.go"), filepath.Join(root, "gen", "oak_gen.go"), false},
+		{"file outside root", "gen", outside, "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writer := New()
+			writer.OutputDir = tc.outputDir
+			writer.Root = root
+
+			result, err := writer.OutputPath(tc.filePath)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got %s", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OutputPath failed: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("OutputPath: expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteResultOutputDir(t *testing.T) {
+	root := t.TempDir()
+	writer := New()
+	writer.OutputDir = "gen"
+	writer.Root = root
+
+	result := &generator.GenerationResult{
+		PackageName: "booking",
+		FilePath:    filepath.Join(root, "internal", "booking", "oak_gen.go"),
+		Content:     "package booking\n",
+	}
+	if err := writer.WriteResult(result); err != nil {
+		t.Fatalf("WriteResult failed: %v", err)
+	}
+
+	// The file is written in the mirrored directory only
+	mirrored := filepath.Join(root, "gen", "internal", "booking", "oak_gen.go")
+	content, err := os.ReadFile(mirrored)
+	if err != nil {
+		t.Fatalf("Failed to read mirrored file: %v", err)
+	}
+	if string(content) != result.Content {
+		t.Errorf("File content mismatch.\nExpected: %s\nGot: %s", result.Content, string(content))
+	}
+	if _, err := os.Stat(result.FilePath); !os.IsNotExist(err) {
+		t.Errorf("Expected no file beside the source, got %v", err)
+	}
+	if !slices.Equal(writer.Rewritten(), []string{mirrored}) {
+		t.Errorf("Expected rewritten files [%s], got %v", mirrored, writer.Rewritten())
+	}
+}